@@ -124,14 +124,15 @@ type {{ .EntityNameLow }}Controller struct {
 	{{ .EntityNameLow }}Service service.{{ .EntityName }}Service
 }
 
+// Create{{ .EntityName }}Request is the body of Create
+type Create{{ .EntityName }}Request struct {
+	Description string ` + "`" + `json:"description" validate:"required"` + "`" + `
+}
+
 // Create implements {{ .EntityName }}Controller.
 func (tc *{{ .EntityNameLow }}Controller) Create() echo.HandlerFunc {
-	type {{ .EntityNameLow }} struct {
-		Description string ` + "`" + `json:"description" validate:"required"` + "`" + `
-	}
-
 	return func(c echo.Context) error {
-		t := {{ .EntityNameLow }}{}
+		t := Create{{ .EntityName }}Request{}
 
 		// Use middleware's validator helper function for validation
 		if err := middleware.ValidateRequest(c, &t); err != nil {
@@ -174,23 +175,21 @@ func (t *{{ .EntityNameLow }}Controller) Find() echo.HandlerFunc {
 	}
 }
 
+// {{ .EntityName }}Path is the path of the {{ .EntityNameLow }} routes
+type {{ .EntityName }}Path struct {
+	ID string ` + "`" + `param:"id" json:"-" validate:"required,uuid"` + "`" + `
+}
+
 // Get{{ .EntityName }} implements {{ .EntityName }}Controller.
 func (tc *{{ .EntityNameLow }}Controller) GetById() echo.HandlerFunc {
 	return func(c echo.Context) error {
-		id := c.Param("id")
+		path := {{ .EntityName }}Path{}
 
-		// Get validator from middleware
-		validate := middleware.GetValidator(c)
-		if validate == nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, "Validator not configured")
-		}
-
-		err := validate.Var(id, "required,uuid")
-		if err != nil {
+		if err := middleware.ValidateRequest(c, &path); err != nil {
 			return err
 		}
 
-		entity, err := tc.{{ .EntityNameLow }}Service.Get(c.Request().Context(), id)
+		entity, err := tc.{{ .EntityNameLow }}Service.Get(c.Request().Context(), path.ID)
 		if err != nil {
 			return err
 		}
@@ -204,34 +203,23 @@ func (tc *{{ .EntityNameLow }}Controller) GetById() echo.HandlerFunc {
 	}
 }
 
+// Update{{ .EntityName }}Request is the path and the body of Update
+type Update{{ .EntityName }}Request struct {
+	ID          string ` + "`" + `param:"id" json:"-" validate:"required,uuid"` + "`" + `
+	Description string ` + "`" + `json:"description" validate:"required"` + "`" + `
+}
+
 // Update implements {{ .EntityName }}Controller.
 func (tc *{{ .EntityNameLow }}Controller) Update() echo.HandlerFunc {
-	type {{ .EntityNameLow }} struct {
-		Description string ` + "`" + `json:"description" validate:"required"` + "`" + `
-	}
-
 	return func(c echo.Context) error {
-		id := c.Param("id")
-
-		// Get validator from middleware
-		validate := middleware.GetValidator(c)
-		if validate == nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, "Validator not configured")
-		}
-
-		err := validate.Var(id, "required,uuid")
-		if err != nil {
-			return err
-		}
-
-		t := {{ .EntityNameLow }}{}
+		t := Update{{ .EntityName }}Request{}
 
 		// Use middleware's validator helper function for validation
 		if err := middleware.ValidateRequest(c, &t); err != nil {
 			return err
 		}
 
-		updated, err := tc.{{ .EntityNameLow }}Service.Update(c.Request().Context(), id, map[string]any{
+		updated, err := tc.{{ .EntityNameLow }}Service.Update(c.Request().Context(), t.ID, map[string]any{
 			"description": t.Description,
 		})
 		if err != nil {
@@ -250,20 +238,13 @@ func (tc *{{ .EntityNameLow }}Controller) Update() echo.HandlerFunc {
 // Delete implements {{ .EntityName }}Controller.
 func (t *{{ .EntityNameLow }}Controller) Delete() echo.HandlerFunc {
 	return func(c echo.Context) error {
-		id := c.Param("id")
-
-		// Get validator from middleware
-		validate := middleware.GetValidator(c)
-		if validate == nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, "Validator not configured")
-		}
+		path := {{ .EntityName }}Path{}
 
-		err := validate.Var(id, "required,uuid")
-		if err != nil {
+		if err := middleware.ValidateRequest(c, &path); err != nil {
 			return err
 		}
 
-		err = t.{{ .EntityNameLow }}Service.Delete(c.Request().Context(), id)
+		err := t.{{ .EntityNameLow }}Service.Delete(c.Request().Context(), path.ID)
 		if err != nil {
 			return err
 		}
@@ -292,11 +273,12 @@ import (
 	"{{ .ModuleName }}/internal/dao/model"
 	"{{ .ModuleName }}/internal/dao/query"
 	"{{ .ModuleName }}/internal/errz"
-	"net/http"
+	"{{ .ModuleName }}/internal/telemetry"
 
 	"github.com/cockroachdb/errors"
 	"github.com/redis/go-redis/v9"
 	"github.com/samber/do"
+	"go.opentelemetry.io/otel/attribute"
 	"gorm.io/gorm"
 
 	"github.com/google/uuid"
@@ -311,76 +293,89 @@ type {{ .EntityName }}Service interface {
 }
 
 type {{ .EntityNameLow }}Service struct {
-	db    *gorm.DB
-	q     *query.Query
-	redis *redis.Client
+	db        *gorm.DB
+	q         *query.Query
+	redis     *redis.Client
+	telemetry *telemetry.Telemetry
 }
 
 func New{{ .EntityName }}Service(i *do.Injector) ({{ .EntityName }}Service, error) {
 	db := do.MustInvoke[*gorm.DB](i)
 	return &{{ .EntityNameLow }}Service{
-		db:    db,
-		q:     query.Use(db),
-		redis: do.MustInvoke[*redis.Client](i),
+		db:        db,
+		q:         query.Use(db),
+		redis:     do.MustInvoke[*redis.Client](i),
+		telemetry: do.MustInvoke[*telemetry.Telemetry](i),
 	}, nil
 }
 
 // Create implements {{ .EntityName }}Service.
 func (s *{{ .EntityNameLow }}Service) Create(ctx context.Context, entity model.{{ .EntityName }}) (*model.{{ .EntityName }}, error) {
-	newID, err := uuid.NewV7()
+	return telemetry.Observe(ctx, s.telemetry, "{{ .EntityNameLow }}_create", func(ctx context.Context) (*model.{{ .EntityName }}, error) {
+		newID, err := uuid.NewV7()
 
-	if err != nil {
-		return nil, errz.NewPrettyError(http.StatusInternalServerError, "internal_server_error", "failed to generate new id", err)
-	}
+		if err != nil {
+			return nil, logFailure(ctx, errz.New(errz.CodeInternal, "failed to generate new id", err))
+		}
 
-	entityp := &entity
-	entityp.ID = newID.String()
-	if err := query.Use(s.db).WithContext(ctx).{{ .EntityName }}.Create(entityp); err != nil {
-		return nil, errz.NewPrettyError(http.StatusInternalServerError, "internal_server_error", "failed to create {{ .EntityNameLow }}", err)
-	}
-	return entityp, nil
+		entityp := &entity
+		entityp.ID = newID.String()
+		if err := query.Use(s.db).WithContext(ctx).{{ .EntityName }}.Create(entityp); err != nil {
+			return nil, logFailure(ctx, errz.New(errz.CodeInternal, "failed to create {{ .EntityNameLow }}", err))
+		}
+		return entityp, nil
+	}, attribute.String("operation", "create"))
 }
 
 // Get implements {{ .EntityName }}Service.
 func (s *{{ .EntityNameLow }}Service) Get(ctx context.Context, id string) (*model.{{ .EntityName }}, error) {
-	entity, err := s.q.WithContext(ctx).{{ .EntityName }}.Where(s.q.{{ .EntityName }}.ID.Eq(id)).First()
+	return telemetry.Observe(ctx, s.telemetry, "{{ .EntityNameLow }}_get", func(ctx context.Context) (*model.{{ .EntityName }}, error) {
+		entity, err := s.q.WithContext(ctx).{{ .EntityName }}.Where(s.q.{{ .EntityName }}.ID.Eq(id)).First()
 
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, errz.NewPrettyError(http.StatusNotFound, "not_found", "entity not found", err)
-	}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errz.New(errz.CodeNotFound, "entity not found", err)
+		}
 
-	if err != nil {
-		return nil, errz.NewPrettyError(http.StatusInternalServerError, "internal_server_error", "failed to get entity", err)
-	}
+		if err != nil {
+			return nil, logFailure(ctx, errz.New(errz.CodeInternal, "failed to get entity", err))
+		}
 
-	return entity, nil
+		return entity, nil
+	}, attribute.String("operation", "get"), attribute.String("{{ .EntityNameLow }}.id", id))
 }
 
 // GetAll implements {{ .EntityName }}Service.
 func (s *{{ .EntityNameLow }}Service) Find(ctx context.Context) ([]*model.{{ .EntityName }}, error) {
-	// var entities []model.{{ .EntityName }}
-
-	entities, err := s.q.WithContext(ctx).{{ .EntityName }}.Find()
+	return telemetry.Observe(ctx, s.telemetry, "{{ .EntityNameLow }}_find_all", func(ctx context.Context) ([]*model.{{ .EntityName }}, error) {
+		entities, err := s.q.WithContext(ctx).{{ .EntityName }}.Find()
 
-	if err != nil {
-		return nil, errz.NewPrettyError(http.StatusInternalServerError, "internal_server_error", "failed to get entities", err)
-	}
+		if err != nil {
+			return nil, logFailure(ctx, errz.New(errz.CodeInternal, "failed to get entities", err))
+		}
 
-	return entities, nil
+		return entities, nil
+	}, attribute.String("operation", "find_all"))
 }
 
 // Update implements {{ .EntityName }}Service.
 // using map here to avoid headache of handling Go's zero value
 // we pass whatever passed validation in handler
 func (s *{{ .EntityNameLow }}Service) Update(ctx context.Context, id string, entity map[string]any) (*model.{{ .EntityName }}, error) {
-	_, err := s.q.WithContext(ctx).{{ .EntityName }}.Where(s.q.{{ .EntityName }}.ID.Eq(id)).Updates(entity)
+	err := telemetry.ObserveErr(ctx, s.telemetry, "{{ .EntityNameLow }}_update", func(ctx context.Context) error {
+		_, err := s.q.WithContext(ctx).{{ .EntityName }}.Where(s.q.{{ .EntityName }}.ID.Eq(id)).Updates(entity)
 
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, errz.NewPrettyError(http.StatusNotFound, "not_found", "entity not found", err)
-	}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errz.New(errz.CodeNotFound, "entity not found", err)
+		}
+
+		if err != nil {
+			return logFailure(ctx, errz.New(errz.CodeInternal, "failed to update entities", err))
+		}
 
+		return nil
+	}, attribute.String("operation", "update"), attribute.String("{{ .EntityNameLow }}.id", id))
 	if err != nil {
-		return nil, errz.NewPrettyError(http.StatusInternalServerError, "internal_server_error", "failed to update entities", err)
+		return nil, err
 	}
 
 	return s.Get(ctx, id)
@@ -388,25 +383,61 @@ func (s *{{ .EntityNameLow }}Service) Update(ctx context.Context, id string, ent
 
 // Delete implements {{ .EntityName }}Service.
 func (s *{{ .EntityNameLow }}Service) Delete(ctx context.Context, id string) error {
-	_, err := s.q.WithContext(ctx).{{ .EntityName }}.Where(s.q.{{ .EntityName }}.ID.Eq(id)).Delete()
-	if err != nil {
-		return errors.Wrap(err, "failed to delete {{ .EntityNameLow }}")
-	}
-	return nil
+	return telemetry.ObserveErr(ctx, s.telemetry, "{{ .EntityNameLow }}_delete", func(ctx context.Context) error {
+		_, err := s.q.WithContext(ctx).{{ .EntityName }}.Where(s.q.{{ .EntityName }}.ID.Eq(id)).Delete()
+		if err != nil {
+			return logFailure(ctx, errz.New(errz.CodeInternal, "failed to delete {{ .EntityNameLow }}", err))
+		}
+		return nil
+	}, attribute.String("operation", "delete"), attribute.String("{{ .EntityNameLow }}.id", id))
 }
 
 `))
 
+// the routes are documented in the OpenAPI document as they are registered
 var routeTemplate = template.Must(template.New("").Parse(`
 add{{ .EntityName }}Routes(injector, e) // FIXME: move me
 func add{{ .EntityName }}Routes(injector *do.Injector, e *echo.Echo) {
+	spec := do.MustInvoke[*openapi.Spec](injector)
 	group := e.Group("/{{ .EntityNameLowPlural }}")
 
-	group.POST("", do.MustInvoke[handler.{{ .EntityName }}Controller](injector).Create())
-	group.GET("", do.MustInvoke[handler.{{ .EntityName }}Controller](injector).Find())
-	group.GET("/:id", do.MustInvoke[handler.{{ .EntityName }}Controller](injector).GetById())
-	group.PATCH("/:id", do.MustInvoke[handler.{{ .EntityName }}Controller](injector).Update())
-	group.DELETE("/:id", do.MustInvoke[handler.{{ .EntityName }}Controller](injector).Delete())
+	spec.Add(group.POST("", do.MustInvoke[handler.{{ .EntityName }}Controller](injector).Create()), openapi.Operation{
+		ID:       "create{{ .EntityName }}",
+		Summary:  "Create a {{ .EntityNameLow }}",
+		Tags:     []string{"{{ .EntityNameLowPlural }}"},
+		Request:  handler.Create{{ .EntityName }}Request{},
+		Response: model.{{ .EntityName }}{},
+		Status:   http.StatusCreated,
+	})
+	spec.Add(group.GET("", do.MustInvoke[handler.{{ .EntityName }}Controller](injector).Find()), openapi.Operation{
+		ID:       "find{{ .EntityNamePlural }}",
+		Summary:  "List the {{ .EntityNameLowPlural }}",
+		Tags:     []string{"{{ .EntityNameLowPlural }}"},
+		Response: []*model.{{ .EntityName }}{},
+	})
+	spec.Add(group.GET("/:id", do.MustInvoke[handler.{{ .EntityName }}Controller](injector).GetById()), openapi.Operation{
+		ID:       "get{{ .EntityName }}",
+		Summary:  "Get a {{ .EntityNameLow }}",
+		Tags:     []string{"{{ .EntityNameLowPlural }}"},
+		Request:  handler.{{ .EntityName }}Path{},
+		Response: model.{{ .EntityName }}{},
+		Errors:   []string{errz.CodeNotFound},
+	})
+	spec.Add(group.PATCH("/:id", do.MustInvoke[handler.{{ .EntityName }}Controller](injector).Update()), openapi.Operation{
+		ID:       "update{{ .EntityName }}",
+		Summary:  "Update a {{ .EntityNameLow }}",
+		Tags:     []string{"{{ .EntityNameLowPlural }}"},
+		Request:  handler.Update{{ .EntityName }}Request{},
+		Response: model.{{ .EntityName }}{},
+		Status:   http.StatusCreated,
+		Errors:   []string{errz.CodeNotFound},
+	})
+	spec.Add(group.DELETE("/:id", do.MustInvoke[handler.{{ .EntityName }}Controller](injector).Delete()), openapi.Operation{
+		ID:      "delete{{ .EntityName }}",
+		Summary: "Delete a {{ .EntityNameLow }}",
+		Tags:    []string{"{{ .EntityNameLowPlural }}"},
+		Request: handler.{{ .EntityName }}Path{},
+	})
 }
 
 `))
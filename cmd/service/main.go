@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"golang-service-template/internal/cli"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func run(
+	ctx context.Context,
+	args []string,
+	getenv func(string) string,
+	stdout, stderr io.Writer,
+) error {
+	// kubernetes and docker stop containers with SIGTERM
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cmd := cli.NewRootCommand(getenv, stdout, stderr)
+	cmd.SetArgs(args[1:])
+
+	return cmd.ExecuteContext(ctx)
+}
+
+func main() {
+	ctx := context.Background()
+
+	err := run(
+		ctx,
+		os.Args,
+		os.Getenv,
+		os.Stdout,
+		os.Stderr,
+	)
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
+	}
+}
@@ -3,7 +3,10 @@ package app
 import (
 	"context"
 	"golang-service-template/internal/common"
+	"golang-service-template/internal/telemetry"
+	"time"
 
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 	"github.com/samber/do"
@@ -19,10 +22,41 @@ func ConnectRedis(i *do.Injector) (*redis.Client, error) {
 		DB:       0,   // use default DB
 	})
 
+	// Add OpenTelemetry instrumentation for redis commands
+	instrumentRedis(i, config, logger, rdb)
+
 	status := rdb.Ping(context.Background())
 	if status.Err() != nil {
 		logger.Fatal().Err(status.Err()).Msg("failed to connect to redis")
 	}
 
+	OnShutdown(i, "redis", 5*time.Second, func(ctx context.Context) error {
+		return rdb.Close()
+	})
+
 	return rdb, nil
 }
+
+// instrumentRedis installs redisotel tracing and metrics hooks on the client.
+// telemetry is invoked first so the global tracer and meter providers are set
+// before the hooks grab them.
+func instrumentRedis(i *do.Injector, config common.Config, logger zerolog.Logger, rdb *redis.Client) {
+	telemetryInstance, err := do.Invoke[*telemetry.Telemetry](i)
+	if err != nil || telemetryInstance == nil || !config.TelemetryConfig.Enabled {
+		return
+	}
+
+	if config.TelemetryConfig.TracingEnabled {
+		if err := redisotel.InstrumentTracing(rdb); err != nil {
+			logger.Warn().Err(err).Msg("failed to add redis OpenTelemetry tracing, continuing without it")
+		}
+	}
+
+	if config.TelemetryConfig.MetricsEnabled {
+		if err := redisotel.InstrumentMetrics(rdb); err != nil {
+			logger.Warn().Err(err).Msg("failed to add redis OpenTelemetry metrics, continuing without it")
+		}
+	}
+
+	logger.Info().Msg("Redis OpenTelemetry instrumentation enabled")
+}
@@ -1,113 +1,300 @@
 package app
 
 import (
+	"fmt"
 	"golang-service-template/internal/common"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/BurntSushi/toml"
+	"github.com/cockroachdb/errors"
 	"github.com/go-playground/locales/en"
 	ut "github.com/go-playground/universal-translator"
 	en_translations "github.com/go-playground/validator/v10/translations/en"
+	"gopkg.in/yaml.v3"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/rs/zerolog/log"
 )
 
-func NewConfig(getenv func(string) string) common.Config {
+// ConfigSource is where NewConfig reads the config from, see common.Config for the precedence
+type ConfigSource struct {
+	Getenv func(string) string
+	// File is an optional .yaml, .yml or .toml config file
+	File string
+	// Overrides are the `--set key=value` flags, e.g. db.host=localhost
+	Overrides map[string]string
+}
+
+// ConfigError lists everything wrong with the config at once
+type ConfigError struct {
+	Problems []string
+}
+
+func (e ConfigError) Error() string {
+	return "invalid config:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// NewConfig loads the config, and where each value came from
+func NewConfig(source ConfigSource) (common.Config, common.ConfigOrigins, error) {
+	config := common.Config{}
+	origins := common.ConfigOrigins{}
+	problems := []string{}
+
+	file := map[string]any{}
+	if source.File != "" {
+		parsed, err := readConfigFile(source.File)
+		if err != nil {
+			return config, origins, err
+		}
+		file = parsed
+	}
+
+	known := map[string]bool{}
+	for _, field := range common.ConfigFields(&config) {
+		known[field.Key] = true
+
+		origin, err := loadConfigField(field, file, source)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+
+		if origin.Source != "" {
+			origins[field.Key] = origin
+		}
+	}
+
+	for key := range source.Overrides {
+		if !known[key] {
+			problems = append(problems, fmt.Sprintf("%s: unknown config key", key))
+		}
+	}
+
+	config.TelemetryConfig.ServiceName = config.ServiceName
+
+	problems = append(problems, validateConfig(config)...)
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return config, origins, ConfigError{Problems: problems}
+	}
+
+	return config, origins, nil
+}
+
+// loadConfigField sets the field from the last layer that has a value:
+// default, then file, then env (or env _FILE), then flag
+func loadConfigField(field common.ConfigField, file map[string]any, source ConfigSource) (common.ConfigOrigin, error) {
+	var (
+		raw    any
+		origin common.ConfigOrigin
+	)
+
+	if field.HasDefault {
+		raw, origin = field.Default, common.ConfigOrigin{Source: common.ConfigSourceDefault}
+	}
+
+	if value, ok := lookupConfigFile(file, field.Key); ok {
+		raw, origin = value, common.ConfigOrigin{Source: common.ConfigSourceFile, From: source.File}
+	}
+
+	if field.Env != "" && source.Getenv != nil {
+		if value := source.Getenv(field.Env); value != "" {
+			raw, origin = value, common.ConfigOrigin{Source: common.ConfigSourceEnv, From: field.Env}
+		} else if path := source.Getenv(field.Env + "_FILE"); path != "" {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return origin, errors.Wrapf(err, "%s_FILE", field.Env)
+			}
+			raw, origin = strings.TrimRight(string(content), "\r\n"), common.ConfigOrigin{Source: common.ConfigSourceEnv, From: field.Env + "_FILE"}
+		}
+	}
+
+	if value, ok := source.Overrides[field.Key]; ok {
+		raw, origin = value, common.ConfigOrigin{Source: common.ConfigSourceFlag, From: "--set " + field.Key}
+	}
+
+	if raw == nil {
+		return origin, nil
+	}
+
+	if err := setConfigValue(field.Value, raw); err != nil {
+		from := origin.Source
+		if origin.From != "" {
+			from = origin.From
+		}
+		return origin, errors.Newf("%s (from %s): %s", field.Key, from, err)
+	}
+
+	return origin, nil
+}
+
+// setConfigValue parses the raw value into the field type.
+// raw is a string from defaults, env and flags, and whatever the yaml or toml decoder gives for files.
+func setConfigValue(value reflect.Value, raw any) error {
+	switch {
+	case value.Type() == durationType:
+		d, err := time.ParseDuration(configString(raw))
+		if err != nil {
+			return errors.Newf("invalid duration %q, e.g. 10s or 1m30s", configString(raw))
+		}
+		value.SetInt(int64(d))
+
+	case value.Kind() == reflect.String:
+		value.SetString(configString(raw))
+
+	case value.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(configString(raw))
+		if err != nil {
+			return errors.Newf("invalid bool %q, e.g. true or false", configString(raw))
+		}
+		value.SetBool(b)
+
+	case value.Kind() == reflect.Int:
+		i, err := strconv.Atoi(configString(raw))
+		if err != nil {
+			return errors.Newf("invalid integer %q", configString(raw))
+		}
+		value.SetInt(int64(i))
+
+	case value.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(configString(raw), 64)
+		if err != nil {
+			return errors.Newf("invalid number %q", configString(raw))
+		}
+		value.SetFloat(f)
+
+	case value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.String:
+		list := []string{}
+		if items, ok := raw.([]any); ok {
+			for _, item := range items {
+				list = append(list, configString(item))
+			}
+		} else {
+			list = splitAndTrim(configString(raw), ",")
+		}
+		value.Set(reflect.ValueOf(list))
+
+	case value.Kind() == reflect.Map && value.Type().Key().Kind() == reflect.String && value.Type().Elem().Kind() == reflect.String:
+		m := map[string]string{}
+		if items, ok := raw.(map[string]any); ok {
+			for key, item := range items {
+				m[key] = configString(item)
+			}
+		} else {
+			m = parseKeyValues(configString(raw))
+		}
+		value.Set(reflect.ValueOf(m))
+
+	default:
+		return errors.Newf("unsupported config type %s", value.Type())
+	}
+
+	return nil
+}
+
+func configString(raw any) string {
+	if s, ok := raw.(string); ok {
+		return strings.TrimSpace(s)
+	}
+	return fmt.Sprint(raw)
+}
+
+func readConfigFile(path string) (map[string]any, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read config file")
+	}
+
+	file := map[string]any{}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &file)
+	case ".toml":
+		err = toml.Unmarshal(content, &file)
+	default:
+		return nil, errors.Newf("config file %s must be .yaml, .yml or .toml", path)
+	}
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse config file %s", path)
+	}
+
+	return file, nil
+}
+
+// lookupConfigFile finds a dotted key (e.g. db.host) in the nested maps of the config file
+func lookupConfigFile(file map[string]any, key string) (any, bool) {
+	var current any = file
+
+	for _, part := range strings.Split(key, ".") {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+
+		current, ok = m[part]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return current, current != nil
+}
+
+// validateConfig returns every validation problem, named after the env var
+func validateConfig(config common.Config) []string {
 	validate := validator.New()
 
 	english := en.New()
 	uni := ut.New(english, english)
-	trans, _ := uni.GetTranslator("en") // en should be get from header
+	trans, _ := uni.GetTranslator("en")
 	_ = en_translations.RegisterDefaultTranslations(validate, trans)
 
-	// Parse HealthcheckTimeoutSeconds with default value
-	healthcheckTimeout := 55 // default to 55 seconds
-	if timeoutStr := getenv("HEALTHCHECK_TIMEOUT_SECONDS"); timeoutStr != "" {
-		if parsed, err := strconv.Atoi(timeoutStr); err == nil {
-			healthcheckTimeout = parsed
-		} else {
-			log.Warn().Str("value", timeoutStr).Msg("invalid HEALTHCHECK_TIMEOUT_SECONDS, using default 55")
-		}
-	}
-
-	// Parse telemetry boolean values with defaults
-	telemetryEnabled := getenv("TELEMETRY_ENABLED") == "true"
-	metricsEnabled := getenv("TELEMETRY_METRICS_ENABLED") == "true"
-	tracingEnabled := getenv("TELEMETRY_TRACING_ENABLED") == "true"
-
-	// Parse database SSL mode with default
-	dbSslMode := getenv("DB_SSLMODE")
-	if dbSslMode == "" {
-		dbSslMode = "require" // Default to secure
-	}
-
-	// Get JWT configuration
-	jwtSecret := getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		log.Panic().Msg("JWT_SECRET environment variable is required")
-	}
-
-	jwtIssuer := getenv("JWT_ISSUER")
-	if jwtIssuer == "" {
-		log.Panic().Msg("JWT_ISSUER environment variable is required")
-	}
-
-	jwtAudience := getenv("JWT_AUDIENCE")
-	if jwtAudience == "" {
-		log.Panic().Msg("JWT_AUDIENCE environment variable is required")
-	}
-
-	_config := common.Config{
-		ServiceName:               getenv("SERVICE_NAME"),
-		Host:                      getenv("HOST"),
-		Port:                      getenv("PORT"),
-		HealthcheckTimeoutSeconds: healthcheckTimeout,
-
-		DbConfig: common.DbConfig{
-			Dialect:  getenv("DB_DIALECT"),
-			Host:     getenv("DB_HOST"),
-			Port:     getenv("DB_PORT"),
-			DBName:   getenv("DB_DBNAME"),
-			Username: getenv("DB_USERNAME"),
-			Password: getenv("DB_PASSWORD"),
-			SslMode:  dbSslMode,
-		},
-		RedisConfig: common.RedisConfig{
-			Address: getenv("REDIS_ADDRESS"),
-		},
-		TelemetryConfig: common.TelemetryConfig{
-			Enabled:        telemetryEnabled,
-			OtelEndpoint:   getenv("OTEL_EXPORTER_OTLP_ENDPOINT"),
-			ServiceName:    getenv("SERVICE_NAME"),
-			ServiceVersion: getenv("SERVICE_VERSION"),
-			Environment:    getenv("ENVIRONMENT"),
-			MetricsEnabled: metricsEnabled,
-			TracingEnabled: tracingEnabled,
-		},
-		JWTConfig: common.JWTConfig{
-			Secret:   jwtSecret,
-			Issuer:   jwtIssuer,
-			Audience: jwtAudience,
-		},
-		AllowedOrigins: getenv("ALLOWED_ORIGINS"), // Comma-separated list
-		TemporalConfig: common.TemporalConfig{
-			Address:   getenv("TEMPORAL_ADDRESS"),
-			Namespace: getenv("TEMPORAL_NAMESPACE"),
-			TaskQueue: getenv("TEMPORAL_TASK_QUEUE"),
-		},
-	}
-
-	err := validate.Struct(_config)
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		if env := field.Tag.Get("env"); env != "" {
+			return env
+		}
+		return field.Name
+	})
 
+	err := validate.Struct(config)
 	if err == nil {
-		log.Trace().Any("config", _config.DbConfig).Msg("config validated")
-		return _config
+		return nil
 	}
 
-	errs := err.(validator.ValidationErrors)
-	log.Panic().Err(err).Any("missing config", errs.Translate(trans)).Msg("failed to validate config")
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return []string{err.Error()}
+	}
 
-	// This line is never reached due to log.Panic() above, but Go requires a return statement
-	// for the function signature. In practice, the program will terminate at log.Panic().
-	return common.Config{}
+	problems := make([]string, 0, len(validationErrors))
+	for _, fieldError := range validationErrors {
+		problems = append(problems, fieldError.Translate(trans))
+	}
+
+	return problems
+}
+
+// parseKeyValues parses a comma-separated list of key=value pairs (e.g. "api-key=xxx,tenant=yyy")
+func parseKeyValues(s string) map[string]string {
+	result := map[string]string{}
+	for _, pair := range splitAndTrim(s, ",") {
+		key, value, found := strings.Cut(pair, "=")
+		if !found {
+			log.Warn().Str("value", pair).Msg("ignoring invalid key=value pair")
+			continue
+		}
+		result[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return result
 }
@@ -1,9 +1,13 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"golang-service-template/internal/common"
+	"golang-service-template/internal/telemetry"
+	"time"
 
+	"github.com/cockroachdb/errors"
 	mysql_drv "github.com/go-sql-driver/mysql"
 	"github.com/samber/do"
 	"github.com/uptrace/opentelemetry-go-extra/otelgorm"
@@ -20,8 +24,69 @@ func ConnectDB(i *do.Injector) (*gorm.DB, error) {
 	logger := do.MustInvoke[zerolog.Logger](i)
 	config := do.MustInvoke[common.Config](i)
 
-	var dialector gorm.Dialector
+	dialector, err := newDialector(config, false)
+	if err != nil {
+		logger.Fatal().Str("dialect", config.Dialect).Msg("unsupported database dialect")
+		return nil, err
+	}
+
+	gormDB, err := gorm.Open(dialector, &gorm.Config{
+		Logger: gormzerolog.NewGormLogger(),
+	})
 
+	if err != nil {
+		logger.Fatal().Err(err).Msg("failed to connect to DB")
+	}
+
+	// Add OpenTelemetry instrumentation for database tracing
+	if err := gormDB.Use(otelgorm.NewPlugin()); err != nil {
+		logger.Fatal().Err(err).Msg("failed to add GORM OpenTelemetry plugin")
+	}
+
+	logger.Info().Msg("Database OpenTelemetry instrumentation enabled")
+
+	// Report connection pool stats (open, idle, in use, waits) as metrics
+	if tel, err := do.Invoke[*telemetry.Telemetry](i); err == nil && tel != nil {
+		if sqlDB, err := gormDB.DB(); err == nil {
+			if err := tel.RegisterDBStats(config.Dialect, sqlDB.Stats); err != nil {
+				logger.Warn().Err(err).Msg("failed to register database pool metrics, continuing without them")
+			}
+		}
+	}
+
+	OnShutdown(i, "database", 5*time.Second, func(ctx context.Context) error {
+		sqlDB, err := gormDB.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.Close()
+	})
+
+	return gormDB, nil
+}
+
+// OpenMigrationDB opens a connection for the migrations, apart from the pool of ConnectDB.
+// The migration files hold several statements, which MySQL only runs with multiStatements,
+// an option the connections serving the requests must not have. The caller closes it.
+func OpenMigrationDB(config common.Config) (*gorm.DB, error) {
+	dialector, err := newDialector(config, true)
+	if err != nil {
+		return nil, err
+	}
+
+	gormDB, err := gorm.Open(dialector, &gorm.Config{
+		Logger: gormzerolog.NewGormLogger(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to DB")
+	}
+
+	return gormDB, nil
+}
+
+// newDialector is the dialector of the database of the config,
+// multiStatements lets a single Exec run several statements (MySQL only, postgres always does)
+func newDialector(config common.Config, multiStatements bool) (gorm.Dialector, error) {
 	switch config.Dialect {
 	case "mysql":
 		dbConfig := mysql_drv.NewConfig()
@@ -32,8 +97,9 @@ func ConnectDB(i *do.Injector) (*gorm.DB, error) {
 		dbConfig.Net = "tcp"
 		// https://stackoverflow.com/questions/29341590/how-to-parse-time-from-database/29343013#29343013
 		dbConfig.ParseTime = true
+		dbConfig.MultiStatements = multiStatements
 
-		dialector = mysql.Open(dbConfig.FormatDSN())
+		return mysql.Open(dbConfig.FormatDSN()), nil
 	case "postgres":
 		sslmode := config.SslMode
 		if sslmode == "" {
@@ -44,26 +110,8 @@ func ConnectDB(i *do.Injector) (*gorm.DB, error) {
 			config.DbConfig.Host, config.DbConfig.Port, config.Username, config.Password, config.DBName, sslmode,
 		)
 
-		dialector = postgres.Open(dsn)
+		return postgres.Open(dsn), nil
 	default:
-		logger.Fatal().Str("dialect", config.Dialect).Msg("unsupported database dialect")
 		return nil, fmt.Errorf("unsupported database dialect: %s", config.Dialect)
 	}
-
-	gormDB, err := gorm.Open(dialector, &gorm.Config{
-		Logger: gormzerolog.NewGormLogger(),
-	})
-
-	if err != nil {
-		logger.Fatal().Err(err).Msg("failed to connect to DB")
-	}
-
-	// Add OpenTelemetry instrumentation for database tracing
-	if err := gormDB.Use(otelgorm.NewPlugin()); err != nil {
-		logger.Fatal().Err(err).Msg("failed to add GORM OpenTelemetry plugin")
-	}
-
-	logger.Info().Msg("Database OpenTelemetry instrumentation enabled")
-
-	return gormDB, nil
 }
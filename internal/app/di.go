@@ -31,17 +31,34 @@ type Container struct {
 
 func NewInjector(
 	ctx context.Context,
-	getenv func(string) string,
+	config common.Config,
+	configOrigins common.ConfigOrigins,
 	stdout, stderr io.Writer,
 ) *do.Injector {
 
 	injector := do.New()
 
 	// logger
-	do.ProvideValue(injector, NewLogger(stdout))
+	do.ProvideValue(injector, NewLogger(stdout, config))
+	do.Provide(injector, NewRedactor)
+	do.Provide(injector, NewErrorReporter)
+
+	// request validation, shared by every request
+	do.Provide(injector, NewValidator)
+
+	// the OpenAPI document, filled by the route registrations
+	do.Provide(injector, NewOpenAPISpec)
+	do.Provide(injector, NewOpenAPIValidator)
 
 	// configs
-	do.ProvideValue(injector, NewConfig(getenv))
+	do.ProvideValue(injector, config)
+	do.ProvideValue(injector, configOrigins)
+
+	// settings that can change without a restart
+	do.Provide(injector, NewRuntimeSettings)
+
+	// feature flags
+	do.Provide(injector, NewFlags)
 
 	// telemetry
 	do.Provide(injector, NewTelemetry)
@@ -49,16 +66,38 @@ func NewInjector(
 	do.Provide(injector, ConnectDB)
 	do.Provide(injector, ConnectRedis)
 
+	// outgoing http client
+	do.Provide(injector, NewHTTPClient)
+
 	// temporal client
 	do.Provide(injector, NewTemporalClient)
 
+	// health checks, picked up by the health service
+	service.ProvideHealthCheck(injector, "database", service.NewDatabaseHealthCheck)
+	service.ProvideHealthCheck(injector, "redis", service.NewRedisHealthCheck)
+	service.ProvideHealthCheck(injector, "temporal", service.NewTemporalHealthCheck)
+
+	// the changes of the tasks, for the GraphQL subscriptions
+	do.Provide(injector, NewTaskEvents)
+
 	// services
 	do.Provide(injector, service.NewHealthService)
 	do.Provide(injector, service.NewTaskService)
+	do.Provide(injector, service.NewUserService)
 
 	// handler
 	do.Provide(injector, handler.NewHealthzController)
 	do.Provide(injector, handler.NewTaskController)
+	do.Provide(injector, handler.NewAdminController)
+	do.Provide(injector, handler.NewFlagController)
+	do.Provide(injector, handler.NewErrorsController)
+	do.Provide(injector, handler.NewOpenAPIController)
+
+	// the RPC services, served by the http server
+	do.Provide(injector, NewRPCServer)
+
+	// the GraphQL API, served by the http server
+	do.Provide(injector, NewGraphQLServer)
 
 	return injector
 }
@@ -0,0 +1,31 @@
+package app
+
+import (
+	"golang-service-template/internal/common"
+	"golang-service-template/internal/flags"
+	"golang-service-template/internal/settings"
+	"golang-service-template/internal/telemetry"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"github.com/samber/do"
+)
+
+// NewFlags evaluates the feature flags, with the overrides stored in redis
+func NewFlags(i *do.Injector) (*flags.Flags, error) {
+	config := do.MustInvoke[common.Config](i)
+
+	tel, _ := do.Invoke[*telemetry.Telemetry](i)
+
+	return flags.New(flags.Options{
+		Store: flags.RedisStore{
+			Client: do.MustInvoke[*redis.Client](i),
+			Key:    config.FlagsConfig.RedisKey,
+		},
+		Defaults:        config.FlagsConfig.Defaults,
+		RuntimeSettings: do.MustInvoke[*settings.RuntimeSettings](i),
+		Telemetry:       tel,
+		RefreshInterval: config.FlagsConfig.RefreshInterval,
+		Logger:          do.MustInvoke[zerolog.Logger](i),
+	})
+}
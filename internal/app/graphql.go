@@ -0,0 +1,103 @@
+package app
+
+import (
+	"context"
+	"golang-service-template/internal/common"
+	"golang-service-template/internal/dao/query"
+	"golang-service-template/internal/graph"
+	"golang-service-template/internal/middleware"
+	"golang-service-template/internal/openapi"
+	"golang-service-template/internal/redact"
+	"golang-service-template/internal/reporting"
+	"golang-service-template/internal/service"
+	"golang-service-template/internal/telemetry"
+	"golang-service-template/internal/validation"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"github.com/samber/do"
+	"gorm.io/gorm"
+)
+
+// NewTaskEvents publishes the changes of the tasks on redis, for the GraphQL subscriptions of every instance
+func NewTaskEvents(i *do.Injector) (service.TaskEvents, error) {
+	config := do.MustInvoke[common.Config](i)
+
+	events := service.NewRedisTaskEvents(
+		do.MustInvoke[*redis.Client](i),
+		config.RedisConfig.TaskEventsChannel,
+		do.MustInvoke[zerolog.Logger](i),
+	)
+
+	// before redis, registered after it
+	OnShutdown(i, "task_events", 5*time.Second, func(ctx context.Context) error {
+		return events.Close()
+	})
+
+	return events, nil
+}
+
+// NewGraphQLServer serves the GraphQL API, nil when GRAPHQL_ENABLED is false
+func NewGraphQLServer(i *do.Injector) (*graph.Server, error) {
+	config := do.MustInvoke[common.Config](i)
+	if !config.GraphQLConfig.Enabled {
+		return nil, nil
+	}
+
+	jwtValidator, err := middleware.NewJWTValidator(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create jwt validator")
+	}
+
+	persistedQueries, err := graph.NewPersistedQueries(
+		do.MustInvoke[*redis.Client](i),
+		config.GraphQLConfig.PersistedQueriesTTL,
+		config.GraphQLConfig.PersistedQueriesFile,
+		config.GraphQLConfig.PersistedQueriesOnly,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// optional, like in TelemetryMiddleware
+	tel, _ := do.Invoke[*telemetry.Telemetry](i)
+
+	server, err := graph.NewServer(graph.Options{
+		Resolver: graph.NewResolver(
+			do.MustInvoke[service.TaskService](i),
+			do.MustInvoke[service.UserService](i),
+			do.MustInvoke[service.TaskEvents](i),
+			do.MustInvoke[*validation.Validator](i),
+		),
+		Query:            query.Use(do.MustInvoke[*gorm.DB](i)),
+		JWTValidator:     jwtValidator,
+		PersistedQueries: persistedQueries,
+		Reporter:         do.MustInvoke[*reporting.Reporter](i),
+		Redactor:         do.MustInvoke[*redact.Redactor](i),
+		Telemetry:        tel,
+		Introspection:    config.GraphQLConfig.Introspection,
+		MaxDepth:         config.GraphQLConfig.MaxDepth,
+		MaxComplexity:    config.GraphQLConfig.MaxComplexity,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create graphql server")
+	}
+
+	return server, nil
+}
+
+// addGraphQLRoutes serves the GraphQL API at /graphql, the queries over GET and POST, the subscriptions over websockets.
+// The operations are described by the schema (see internal/graph/schema.graphql), not in the OpenAPI document.
+func addGraphQLRoutes(injector *do.Injector, e *echo.Echo) {
+	server := do.MustInvoke[*graph.Server](injector)
+	if server == nil {
+		return
+	}
+
+	do.MustInvoke[*openapi.Spec](injector).Exclude(
+		e.Match([]string{echo.GET, echo.POST}, "/graphql", server.Handle)...,
+	)
+}
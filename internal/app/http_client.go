@@ -0,0 +1,37 @@
+package app
+
+import (
+	"net/http"
+	"time"
+
+	"golang-service-template/internal/telemetry"
+
+	"github.com/samber/do"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+)
+
+// defaultHTTPClientTimeout bounds every outgoing call made with the shared client.
+// Use a context deadline for anything that needs a shorter timeout.
+const defaultHTTPClientTimeout = 30 * time.Second
+
+// NewHTTPClient returns the shared *http.Client for outgoing calls (webhooks, JWKS, 3rd party APIs).
+// The transport is wrapped with otelhttp so every request gets a client span,
+// http.client.* metrics and the trace context injected into the outgoing headers.
+func NewHTTPClient(i *do.Injector) (*http.Client, error) {
+	// make sure the global tracer/meter providers and propagator are set up first
+	_, _ = do.Invoke[*telemetry.Telemetry](i)
+
+	transport := otelhttp.NewTransport(
+		http.DefaultTransport.(*http.Transport).Clone(),
+		otelhttp.WithPropagators(otel.GetTextMapPropagator()),
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return "HTTP " + r.Method + " " + r.URL.Host
+		}),
+	)
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   defaultHTTPClientTimeout,
+	}, nil
+}
@@ -0,0 +1,100 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/rs/zerolog"
+	"github.com/samber/do"
+)
+
+const (
+	// shutdownHookPrefix is the injector name prefix of every registered ShutdownHook
+	shutdownHookPrefix = "shutdown:"
+
+	// defaultShutdownTimeout applies to hooks registered without a timeout
+	defaultShutdownTimeout = 5 * time.Second
+)
+
+// ShutdownHook closes a component when the injector shuts down.
+// It implements do.Shutdownable, so injector.Shutdown() runs it.
+type ShutdownHook struct {
+	Name    string
+	Timeout time.Duration
+	Fn      func(ctx context.Context) error
+
+	logger zerolog.Logger
+}
+
+// Shutdown runs the hook with its own timeout.
+// Failures are logged and not returned, the injector stops at the first error
+// and we still want the remaining components to be closed.
+func (hook ShutdownHook) Shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), hook.Timeout)
+	defer cancel()
+
+	start := time.Now()
+
+	// some clients don't take a context (e.g. temporal client.Close),
+	// run the hook aside so the timeout is honoured anyway
+	done := make(chan error, 1)
+	go func() {
+		done <- hook.Fn(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = errors.Wrapf(ctx.Err(), "timed out after %s", hook.Timeout)
+	}
+
+	if err != nil {
+		hook.logger.Error().Err(err).Str("component", hook.Name).Msg("failed to shutdown component")
+		return nil
+	}
+
+	hook.logger.Info().Str("component", hook.Name).Dur("duration", time.Since(start)).Msg("component shut down")
+	return nil
+}
+
+// OnShutdown registers a hook closing a component when the injector shuts down.
+//
+// The injector shuts services down in the reverse order of their first invocation,
+// so it must be called at the end of the component's provider, once its own dependencies are invoked:
+// the hook then runs after everything depending on the component, and before its dependencies.
+//
+//	app.OnShutdown(i, "redis", 5*time.Second, func(ctx context.Context) error { return rdb.Close() })
+func OnShutdown(i *do.Injector, name string, timeout time.Duration, fn func(ctx context.Context) error) {
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+
+	serviceName := shutdownHookPrefix + name
+
+	do.ProvideNamedValue(i, serviceName, ShutdownHook{
+		Name:    name,
+		Timeout: timeout,
+		Fn:      fn,
+		logger:  do.MustInvoke[zerolog.Logger](i),
+	})
+
+	// invoke it right away, the injector only shuts down invoked services
+	// and this is what puts the hook in the shutdown order
+	do.MustInvokeNamed[ShutdownHook](i, serviceName)
+}
+
+// Shutdown closes every component registered with OnShutdown, in reverse dependency order
+func Shutdown(i *do.Injector) error {
+	logger := do.MustInvoke[zerolog.Logger](i)
+
+	logger.Info().Msg("shutting down")
+
+	if err := i.Shutdown(); err != nil {
+		return errors.Wrap(err, "failed to shutdown")
+	}
+
+	logger.Info().Msg("shut down complete")
+	return nil
+}
@@ -1,18 +1,134 @@
 package app
 
 import (
+	"golang-service-template/internal/common"
+	"golang-service-template/internal/redact"
+	"golang-service-template/internal/telemetry"
 	"io"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/pkgerrors"
+	"github.com/samber/do"
 )
 
-func NewLogger(stdout io.Writer) zerolog.Logger {
+// NewLogger builds the logger from the log config (LOG_LEVEL, LOG_FORMAT, LOG_CALLER, LOG_SAMPLING).
+// Every line gets the service name, version and environment, extra hooks run after them.
+func NewLogger(stdout io.Writer, config common.Config, hooks ...zerolog.Hook) zerolog.Logger {
 	zerolog.TimeFieldFormat = time.RFC3339Nano
-	zerolog.ErrorStackMarshaler = pkgerrors.MarshalStack
 
-	logger := zerolog.New(stdout).With().Timestamp().Logger()
+	// the level is global, so the runtime settings can change it
+	level, err := zerolog.ParseLevel(config.LogConfig.Level)
+	if err != nil {
+		level = zerolog.InfoLevel
+	}
+	zerolog.SetGlobalLevel(level)
+
+	// stack traces are noisy and leak internals, keep them for development
+	zerolog.ErrorStackMarshaler = nil
+	if config.IsDevelopment() {
+		zerolog.ErrorStackMarshaler = pkgerrors.MarshalStack
+	}
+
+	var output io.Writer = stdout
+	if config.LogConfig.Format == "console" {
+		output = zerolog.ConsoleWriter{Out: stdout, TimeFormat: "15:04:05.000"}
+	}
+
+	// everything written to stdout is also forwarded to the OTel logs pipeline
+	// the bridge is a no-op until telemetry installs a logger provider (TELEMETRY_LOGS_ENABLED=true)
+	writer := zerolog.MultiLevelWriter(output, telemetry.NewLogBridge("golang-service-template"))
+
+	loggerContext := zerolog.New(writer).With().Timestamp()
+	if config.LogConfig.Caller {
+		loggerContext = loggerContext.Caller()
+	}
+
+	logger := loggerContext.Logger().
+		Hook(telemetry.TraceHook{}).
+		Hook(StaticFieldsHook{
+			"service":     config.ServiceName,
+			"version":     config.TelemetryConfig.ServiceVersion,
+			"environment": config.TelemetryConfig.Environment,
+		})
+
+	for _, hook := range hooks {
+		logger = logger.Hook(hook)
+	}
+
+	if sampler := newLogSampler(config.LogConfig); sampler != nil {
+		logger = logger.Sample(sampler)
+	}
+
+	// used by telemetry.Logger(ctx) when the context has no request scoped logger
+	zerolog.DefaultContextLogger = &logger
 
 	return logger
 }
+
+// StaticFieldsHook adds the same fields to every line, empty values are skipped
+type StaticFieldsHook map[string]string
+
+func (h StaticFieldsHook) Run(e *zerolog.Event, _ zerolog.Level, _ string) {
+	keys := make([]string, 0, len(h))
+	for key := range h {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if value := h[key]; value != "" {
+			e.Str(key, value)
+		}
+	}
+}
+
+// newLogSampler keeps every log up to the burst of each second, then 1 of every N, per level.
+// Returns nil when no level is sampled.
+func newLogSampler(config common.LogConfig) zerolog.Sampler {
+	levelSampler := zerolog.LevelSampler{}
+	sampled := false
+
+	for level, value := range config.Sampling {
+		n, err := strconv.ParseUint(value, 10, 32)
+		if err != nil || n <= 1 {
+			continue
+		}
+
+		sampler := &zerolog.BurstSampler{
+			Burst:       uint32(config.SamplingBurst),
+			Period:      time.Second,
+			NextSampler: &zerolog.BasicSampler{N: uint32(n)},
+		}
+
+		switch level {
+		case "trace":
+			levelSampler.TraceSampler = sampler
+		case "debug":
+			levelSampler.DebugSampler = sampler
+		case "info":
+			levelSampler.InfoSampler = sampler
+		default:
+			continue
+		}
+		sampled = true
+	}
+
+	if !sampled {
+		return nil
+	}
+
+	return levelSampler
+}
+
+// NewRedactor redacts the logged bodies and headers
+func NewRedactor(i *do.Injector) (*redact.Redactor, error) {
+	config := do.MustInvoke[common.Config](i)
+
+	return redact.New(redact.Options{
+		Keys:     config.LogConfig.RedactKeys,
+		Patterns: config.LogConfig.RedactPatterns,
+	})
+}
@@ -0,0 +1,36 @@
+package app
+
+import (
+	"context"
+	"golang-service-template/internal/common"
+	"golang-service-template/internal/openapi"
+
+	"github.com/samber/do"
+)
+
+// NewOpenAPISpec collects the documented routes, filled by addRoutes
+func NewOpenAPISpec(i *do.Injector) (*openapi.Spec, error) {
+	config := do.MustInvoke[common.Config](i)
+
+	version := config.TelemetryConfig.ServiceVersion
+	if version == "" {
+		version = "dev"
+	}
+
+	return openapi.New(openapi.Info{
+		Title:   config.ServiceName,
+		Version: version,
+	}), nil
+}
+
+// NewOpenAPIValidator validates the requests against the hand-written OpenAPI document of OPENAPI_VALIDATION_FILE,
+// it is nil (validating nothing) without one
+func NewOpenAPIValidator(i *do.Injector) (*openapi.Validator, error) {
+	config := do.MustInvoke[common.Config](i)
+
+	if config.OpenAPIConfig.ValidationFile == "" {
+		return nil, nil
+	}
+
+	return openapi.NewValidator(context.Background(), config.OpenAPIConfig.ValidationFile)
+}
@@ -0,0 +1,60 @@
+package app
+
+import (
+	"context"
+	"golang-service-template/internal/common"
+	"golang-service-template/internal/redact"
+	"golang-service-template/internal/reporting"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/rs/zerolog"
+	"github.com/samber/do"
+)
+
+// NewErrorReporter reports the server errors and the panics to ERROR_REPORTER,
+// it is nil (reporting nothing) with ERROR_REPORTER=none
+func NewErrorReporter(i *do.Injector) (*reporting.Reporter, error) {
+	config := do.MustInvoke[common.Config](i)
+
+	var sink reporting.ErrorReporter
+	switch config.ErrorReportingConfig.Reporter {
+	case "sentry":
+		sentryReporter, err := reporting.NewSentryReporter(config.ErrorReportingConfig.SentryDSN, do.MustInvoke[*http.Client](i))
+		if err != nil {
+			return nil, err
+		}
+		sink = sentryReporter
+	case "file":
+		fileReporter, err := reporting.NewFileReporter(config.ErrorReportingConfig.File)
+		if err != nil {
+			return nil, err
+		}
+		sink = fileReporter
+	default:
+		return nil, nil
+	}
+
+	reporter := reporting.New(sink, reporting.Options{
+		Service:     config.ServiceName,
+		Release:     config.TelemetryConfig.ServiceVersion,
+		Environment: config.TelemetryConfig.Environment,
+		RateLimit:   config.ErrorReportingConfig.RateLimit,
+		RateWindow:  config.ErrorReportingConfig.RateWindow,
+		Redactor:    do.MustInvoke[*redact.Redactor](i),
+		Logger:      do.MustInvoke[zerolog.Logger](i),
+	})
+
+	// the errors of the other components' shutdown are logged, not reported, so it can run first
+	OnShutdown(i, "error_reporter", 5*time.Second, func(ctx context.Context) error {
+		err := reporter.Flush(ctx)
+		if closer, ok := sink.(io.Closer); ok {
+			err = errors.CombineErrors(err, closer.Close())
+		}
+		return err
+	})
+
+	return reporter, nil
+}
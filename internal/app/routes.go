@@ -6,10 +6,17 @@ import (
 	"github.com/samber/do"
 
 	"golang-service-template/internal/common"
+	"golang-service-template/internal/dao/model"
 	"golang-service-template/internal/errz"
+	"golang-service-template/internal/flags"
 	"golang-service-template/internal/handler"
 	"golang-service-template/internal/middleware"
+	"golang-service-template/internal/openapi"
+	"golang-service-template/internal/redact"
+	"golang-service-template/internal/reporting"
+	"golang-service-template/internal/settings"
 	"golang-service-template/internal/telemetry"
+	"golang-service-template/internal/validation"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
@@ -52,46 +59,41 @@ func addRoutes(
 	injector *do.Injector,
 ) {
 	logger := do.MustInvoke[zerolog.Logger](injector)
-	config := do.MustInvoke[common.Config](injector)
-
-	// global middlewares
-	e.Use(echo_middleware.Recover())                // First - handle panics
-	
-	// Configure CORS securely
-	allowedOrigins := []string{"*"} // Default to allow all for development
-	if config.AllowedOrigins != "" {
-		// Parse comma-separated origins
-		origins := []string{}
-		for _, origin := range splitAndTrim(config.AllowedOrigins, ",") {
-			if origin != "" {
-				origins = append(origins, origin)
-			}
-		}
-		if len(origins) > 0 {
-			allowedOrigins = origins
-		}
-	}
-	
-	// CORS configuration: if using wildcard, don't allow credentials
-	allowCredentials := len(allowedOrigins) == 1 && allowedOrigins[0] != "*"
-	
-	e.Use(echo_middleware.CORSWithConfig(echo_middleware.CORSConfig{
-		AllowOrigins:     allowedOrigins,
-		AllowCredentials: allowCredentials,
-		AllowMethods:     []string{echo.GET, echo.POST, echo.PUT, echo.PATCH, echo.DELETE, echo.OPTIONS},
-		AllowHeaders:     []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
-		MaxAge:           86400, // 24 hours
-	}))
-	
+	runtimeSettings := do.MustInvoke[*settings.RuntimeSettings](injector)
+
+	reporter := do.MustInvoke[*reporting.Reporter](injector)
+	redactor := do.MustInvoke[*redact.Redactor](injector)
+
+	// global middlewares, in this order
+
+	// panics of the middlewares below, ReportErrorsMiddleware handles the ones of the handlers
+	e.Use(middleware.RecoverMiddleware(reporter, redactor))
+
+	// CORS follows the runtime settings
+	e.Use(middleware.CORSMiddleware(runtimeSettings))
+
 	// Body size limit to prevent DoS attacks
 	e.Use(echo_middleware.BodyLimit("1M"))
-	
-	e.Use(middleware.RequestIDMiddleware())         // Third - generate request ID for tracing
-	e.Use(middleware.TelemetryMiddleware(injector)) // Fourth - track all requests (including failed ones)
-	e.Use(middleware.LoggerMiddleware(logger))      // Fifth - logger should capture request ID and telemetry context
-	e.Use(errz.ErrorRendererMiddleware())           // Sixth - handle error rendering
-	e.Use(middleware.ValidatorMiddleware())         // Seventh - set up request validation
-	
+
+	// generate request ID for tracing
+	e.Use(middleware.RequestIDMiddleware())
+	// track all requests (including failed ones)
+	e.Use(middleware.TelemetryMiddleware(injector))
+	// logger should capture request ID and telemetry context
+	e.Use(middleware.LoggerMiddleware(logger, runtimeSettings, redactor))
+	// handle error rendering
+	e.Use(errz.ErrorRendererMiddleware())
+	// report 5xx and panics, rendered as 500 by the error renderer
+	e.Use(middleware.ReportErrorsMiddleware(reporter, redactor))
+	// per client IP, after the error renderer so 429s are rendered and logged
+	e.Use(middleware.RateLimitMiddleware(runtimeSettings))
+	// set up request validation
+	e.Use(middleware.ValidatorMiddleware(do.MustInvoke[*validation.Validator](injector)))
+	// requests described by OPENAPI_VALIDATION_FILE are validated against it, and their responses outside production
+	if openAPIValidator := do.MustInvoke[*openapi.Validator](injector); openAPIValidator != nil {
+		e.Use(middleware.OpenAPIValidationMiddleware(openAPIValidator, !do.MustInvoke[common.Config](injector).IsProduction()))
+	}
+
 	// Security headers
 	e.Use(securityHeadersMiddleware())
 
@@ -99,20 +101,61 @@ func addRoutes(
 	addHealthzRoutes(injector, e)
 	addTaskRoutes(injector, e)
 	addMetricsRoutes(injector, e)
+	addAdminRoutes(injector, e)
+	addErrorsRoutes(injector, e)
+	addOpenAPIRoutes(injector, e)
+	addGraphQLRoutes(injector, e)
 
 	// root route
-	e.Any("/", echo.WrapHandler(http.NotFoundHandler()))
+	do.MustInvoke[*openapi.Spec](injector).Exclude(
+		e.Any("/", echo.WrapHandler(http.NotFoundHandler()))...,
+	)
 }
 
+// every route is documented in the OpenAPI document where it is registered, or excluded from it:
+// TestRoutesAreDocumented and `service openapi check` fail on the routes that are neither
+
 func addHealthzRoutes(injector *do.Injector, e *echo.Echo) {
 	healthController := do.MustInvoke[handler.HealthzController](injector)
-	e.GET("/healthz", healthController.GetHealthz())
-	e.POST("/healthz", healthController.GetHealthz())
-	e.GET("/readyz", healthController.GetReadyz())
-	e.GET("/errorz", healthController.Errorz())
+	spec := do.MustInvoke[*openapi.Spec](injector)
+
+	liveness := openapi.Operation{
+		ID:          "getHealthz",
+		Summary:     "Liveness probe",
+		Description: "503 with the same body when the service is not alive.",
+		Tags:        []string{"health"},
+		Response:    handler.ProbeResponse{},
+		Raw:         true,
+	}
+	spec.Add(e.GET("/healthz", healthController.GetHealthz()), liveness)
+	liveness.ID = "postHealthz"
+	spec.Add(e.POST("/healthz", healthController.GetHealthz()), liveness)
+
+	spec.Add(e.GET("/readyz", healthController.GetReadyz()), openapi.Operation{
+		ID:          "getReadyz",
+		Summary:     "Readiness probe",
+		Description: "503 with the same body when the service is not ready. ?verbose=1 adds the result of every check.",
+		Tags:        []string{"health"},
+		Response:    handler.ProbeResponse{},
+		Raw:         true,
+	})
+	spec.Add(e.GET("/startupz", healthController.GetStartupz()), openapi.Operation{
+		ID:          "getStartupz",
+		Summary:     "Startup probe",
+		Description: "503 with the same body until the service started. ?verbose=1 adds the result of every check.",
+		Tags:        []string{"health"},
+		Response:    handler.ProbeResponse{},
+		Raw:         true,
+	})
+	spec.Add(e.GET("/errorz", healthController.Errorz()), openapi.Operation{
+		ID:      "getErrorz",
+		Summary: "Always fails, to check the error rendering and reporting",
+		Tags:    []string{"health"},
+	})
 }
 
 func addTaskRoutes(injector *do.Injector, e *echo.Echo) {
+	spec := do.MustInvoke[*openapi.Spec](injector)
 	taskGroup := e.Group("/tasks")
 
 	// taskGroup.Use(echo_middleware.BasicAuth(func(username, password string, c echo.Context) (bool, error) {
@@ -122,11 +165,42 @@ func addTaskRoutes(injector *do.Injector, e *echo.Echo) {
 	// 	return false, nil
 	// }))
 
-	taskGroup.POST("", do.MustInvoke[handler.TaskController](injector).Create())
-	taskGroup.GET("", do.MustInvoke[handler.TaskController](injector).Find())
-	taskGroup.GET("/:id", do.MustInvoke[handler.TaskController](injector).GetById())
-	taskGroup.PATCH("/:id", do.MustInvoke[handler.TaskController](injector).Update())
-	taskGroup.DELETE("/:id", do.MustInvoke[handler.TaskController](injector).Delete())
+	spec.Add(taskGroup.POST("", do.MustInvoke[handler.TaskController](injector).Create()), openapi.Operation{
+		ID:       "createTask",
+		Summary:  "Create a task",
+		Tags:     []string{"tasks"},
+		Request:  handler.CreateTaskRequest{},
+		Response: model.Task{},
+		Status:   http.StatusCreated,
+	})
+	spec.Add(taskGroup.GET("", do.MustInvoke[handler.TaskController](injector).Find()), openapi.Operation{
+		ID:       "findTasks",
+		Summary:  "List the tasks",
+		Tags:     []string{"tasks"},
+		Response: []*model.Task{},
+	})
+	spec.Add(taskGroup.GET("/:id", do.MustInvoke[handler.TaskController](injector).GetById()), openapi.Operation{
+		ID:       "getTask",
+		Summary:  "Get a task",
+		Tags:     []string{"tasks"},
+		Request:  handler.TaskPath{},
+		Response: model.Task{},
+		Errors:   []string{errz.CodeNotFound},
+	})
+	spec.Add(taskGroup.PATCH("/:id", do.MustInvoke[handler.TaskController](injector).Update()), openapi.Operation{
+		ID:       "updateTask",
+		Summary:  "Update a task",
+		Tags:     []string{"tasks"},
+		Request:  handler.UpdateTaskRequest{},
+		Response: model.Task{},
+		Errors:   []string{errz.CodeNotFound},
+	})
+	spec.Add(taskGroup.DELETE("/:id", do.MustInvoke[handler.TaskController](injector).Delete()), openapi.Operation{
+		ID:      "deleteTask",
+		Summary: "Delete a task",
+		Tags:    []string{"tasks"},
+		Request: handler.TaskPath{},
+	})
 
 	securedTaskGroup := e.Group("/secured/tasks")
 	securedTaskGroup.Use(middleware.ValidateJWTMiddleware(
@@ -134,13 +208,109 @@ func addTaskRoutes(injector *do.Injector, e *echo.Echo) {
 		do.MustInvoke[zerolog.Logger](injector),
 	))
 
-	securedTaskGroup.GET("", do.MustInvoke[handler.TaskController](injector).FindByUserId())
+	spec.Add(securedTaskGroup.GET("", do.MustInvoke[handler.TaskController](injector).FindByUserId()), openapi.Operation{
+		ID:       "findMyTasks",
+		Summary:  "List the tasks created by the user of the token",
+		Tags:     []string{"tasks"},
+		Response: []*model.Task{},
+		Secured:  true,
+	})
 
 }
 
 func addMetricsRoutes(injector *do.Injector, e *echo.Echo) {
 	// Get telemetry from dependency injection (optional, may not be available)
 	if tel, err := do.Invoke[*telemetry.Telemetry](injector); err == nil && tel != nil {
-		e.GET("/metrics", echo.WrapHandler(tel.GetMetricsHandler()))
+		// prometheus text format, not documented
+		do.MustInvoke[*openapi.Spec](injector).Exclude(
+			e.GET("/metrics", echo.WrapHandler(tel.GetMetricsHandler())),
+		)
+	}
+}
+
+func addAdminRoutes(injector *do.Injector, e *echo.Echo) {
+	spec := do.MustInvoke[*openapi.Spec](injector)
+	adminGroup := e.Group("/admin")
+	adminGroup.Use(middleware.ValidateJWTMiddleware(
+		do.MustInvoke[common.Config](injector),
+		do.MustInvoke[zerolog.Logger](injector),
+	))
+	adminGroup.Use(middleware.RequireRole(middleware.RoleAdmin))
+
+	admin := func(operation openapi.Operation) openapi.Operation {
+		operation.Tags = []string{"admin"}
+		operation.Secured = true
+		operation.Roles = []string{middleware.RoleAdmin}
+		return operation
+	}
+
+	spec.Add(adminGroup.GET("/config", do.MustInvoke[handler.AdminController](injector).GetConfig()), admin(openapi.Operation{
+		ID:       "getConfig",
+		Summary:  "Get the effective config, secrets redacted, with the origin of every value",
+		Response: []common.ConfigEntry{},
+	}))
+
+	flagController := do.MustInvoke[handler.FlagController](injector)
+	spec.Add(adminGroup.GET("/flags", flagController.List()), admin(openapi.Operation{
+		ID:       "listFlags",
+		Summary:  "List the feature flags, with their config default and override",
+		Response: []flags.State{},
+	}))
+	spec.Add(adminGroup.GET("/flags/:key", flagController.Get()), admin(openapi.Operation{
+		ID:       "getFlag",
+		Summary:  "Get a feature flag, with its config default and override",
+		Response: flags.State{},
+		Errors:   []string{errz.CodeNotFound},
+	}))
+	spec.Add(adminGroup.PUT("/flags/:key", flagController.Put()), admin(openapi.Operation{
+		ID:       "putFlag",
+		Summary:  "Replace the override of a feature flag",
+		Request:  flags.Override{},
+		Response: flags.State{},
+		Errors:   []string{errz.CodeNotFound, errz.CodeInvalidVariant, errz.CodeInvalidPercentage},
+	}))
+	spec.Add(adminGroup.DELETE("/flags/:key", flagController.Delete()), admin(openapi.Operation{
+		ID:      "deleteFlag",
+		Summary: "Remove the override of a feature flag",
+		Errors:  []string{errz.CodeNotFound},
+	}))
+	spec.Add(adminGroup.POST("/flags/:key/evaluate", flagController.Evaluate()), admin(openapi.Operation{
+		ID:       "evaluateFlag",
+		Summary:  "Get the variant a target gets, and why",
+		Request:  flags.Target{},
+		Response: flags.Evaluation{},
+		Errors:   []string{errz.CodeNotFound},
+	}))
+}
+
+// the `type` of the problem responses resolves here
+func addErrorsRoutes(injector *do.Injector, e *echo.Echo) {
+	errorsController := do.MustInvoke[handler.ErrorsController](injector)
+	spec := do.MustInvoke[*openapi.Spec](injector)
+
+	spec.Add(e.GET("/errors", errorsController.List()), openapi.Operation{
+		ID:       "listErrors",
+		Summary:  "List the error codes",
+		Tags:     []string{"errors"},
+		Response: []errz.CatalogEntry{},
+	})
+	spec.Add(e.GET("/errors/:code", errorsController.Get()), openapi.Operation{
+		ID:       "getError",
+		Summary:  "Get an error code",
+		Tags:     []string{"errors"},
+		Response: errz.CatalogEntry{},
+		Errors:   []string{errz.CodeNotFound},
+	})
+}
+
+// the document describes the API, it is not part of it
+func addOpenAPIRoutes(injector *do.Injector, e *echo.Echo) {
+	openAPIController := do.MustInvoke[handler.OpenAPIController](injector)
+	spec := do.MustInvoke[*openapi.Spec](injector)
+
+	spec.Exclude(e.GET("/openapi.json", openAPIController.Document()))
+
+	if do.MustInvoke[common.Config](injector).OpenAPIConfig.DocsEnabled {
+		spec.Exclude(e.GET("/docs", openAPIController.Docs()))
 	}
 }
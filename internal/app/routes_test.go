@@ -0,0 +1,116 @@
+package app
+
+import (
+	"net/http"
+	"testing"
+
+	"golang-service-template/internal/common"
+	"golang-service-template/internal/graph"
+	"golang-service-template/internal/handler"
+	"golang-service-template/internal/openapi"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+	"github.com/samber/do"
+)
+
+// stubController stands in for the controllers that need the database or redis,
+// the routes only need their handlers to register
+type stubController struct{}
+
+func (stubController) handler() echo.HandlerFunc {
+	return func(c echo.Context) error { return c.NoContent(http.StatusNoContent) }
+}
+
+func (s stubController) GetHealthz() echo.HandlerFunc   { return s.handler() }
+func (s stubController) GetReadyz() echo.HandlerFunc    { return s.handler() }
+func (s stubController) GetStartupz() echo.HandlerFunc  { return s.handler() }
+func (s stubController) Errorz() echo.HandlerFunc       { return s.handler() }
+func (s stubController) Create() echo.HandlerFunc       { return s.handler() }
+func (s stubController) Find() echo.HandlerFunc         { return s.handler() }
+func (s stubController) FindByUserId() echo.HandlerFunc { return s.handler() }
+func (s stubController) GetById() echo.HandlerFunc      { return s.handler() }
+func (s stubController) Update() echo.HandlerFunc       { return s.handler() }
+func (s stubController) Delete() echo.HandlerFunc       { return s.handler() }
+func (s stubController) List() echo.HandlerFunc         { return s.handler() }
+func (s stubController) Get() echo.HandlerFunc          { return s.handler() }
+func (s stubController) Put() echo.HandlerFunc          { return s.handler() }
+func (s stubController) Evaluate() echo.HandlerFunc     { return s.handler() }
+
+// newRoutesInjector provides what NewServer needs to register every route, without a database, redis or temporal
+func newRoutesInjector(t *testing.T) *do.Injector {
+	t.Helper()
+
+	config := common.Config{
+		ServiceName: "test",
+		JWTConfig: common.JWTConfig{
+			Secret:   "secret",
+			Issuer:   "https://example.com/",
+			Audience: "audience",
+		},
+		OpenAPIConfig: common.OpenAPIConfig{DocsEnabled: true},
+	}
+
+	injector := do.New()
+	t.Cleanup(func() { _ = Shutdown(injector) })
+
+	do.ProvideValue(injector, zerolog.Nop())
+	do.ProvideValue(injector, config)
+	do.ProvideValue(injector, common.ConfigOrigins{})
+	do.Provide(injector, NewRedactor)
+	do.Provide(injector, NewErrorReporter)
+	do.Provide(injector, NewValidator)
+	do.Provide(injector, NewOpenAPISpec)
+	do.Provide(injector, NewOpenAPIValidator)
+	do.Provide(injector, NewRuntimeSettings)
+	do.Provide(injector, NewTelemetry)
+
+	stub := stubController{}
+	do.ProvideValue[handler.HealthzController](injector, stub)
+	do.ProvideValue[handler.TaskController](injector, stub)
+	do.ProvideValue[handler.FlagController](injector, stub)
+	do.Provide(injector, handler.NewAdminController)
+	do.Provide(injector, handler.NewErrorsController)
+	do.Provide(injector, handler.NewOpenAPIController)
+
+	// the RPC services are served before the routing of echo, they have no routes
+	do.Provide(injector, NewRPCServer)
+
+	// the resolvers are only called by the operations
+	server, err := graph.NewServer(graph.Options{
+		Resolver:      graph.NewResolver(nil, nil, nil, nil),
+		MaxDepth:      1,
+		MaxComplexity: 1,
+	})
+	if err != nil {
+		t.Fatalf("graph.NewServer: %v", err)
+	}
+	do.ProvideValue(injector, server)
+
+	return injector
+}
+
+func TestRoutesAreDocumented(t *testing.T) {
+	injector := newRoutesInjector(t)
+
+	e := NewServer(injector)
+
+	// documented with spec.Add or left out with spec.Exclude where they are registered
+	for _, line := range do.MustInvoke[*openapi.Spec](injector).Drift(e.Routes()) {
+		t.Error(line)
+	}
+}
+
+func TestDriftFailsOnUndocumentedRoutes(t *testing.T) {
+	injector := newRoutesInjector(t)
+	spec := do.MustInvoke[*openapi.Spec](injector)
+
+	e := NewServer(injector)
+	// registered without spec.Add nor spec.Exclude
+	e.GET("/undocumented", stubController{}.handler())
+
+	drift := spec.Drift(e.Routes())
+	if len(drift) != 1 || drift[0] != "GET /undocumented is not documented" {
+		t.Errorf("drift = %q, want the undocumented route", drift)
+	}
+}
@@ -0,0 +1,82 @@
+package app
+
+import (
+	"golang-service-template/internal/common"
+	"golang-service-template/internal/middleware"
+	"golang-service-template/internal/redact"
+	"golang-service-template/internal/reporting"
+	"golang-service-template/internal/rpc"
+	"golang-service-template/internal/rpc/gen/task/v1/taskv1connect"
+	"golang-service-template/internal/service"
+	"golang-service-template/internal/settings"
+	"golang-service-template/internal/telemetry"
+	"golang-service-template/internal/validation"
+	"net/http"
+
+	"github.com/cockroachdb/errors"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+	"github.com/samber/do"
+)
+
+// NewRPCServer serves the RPC services, nil when RPC_ENABLED is false
+func NewRPCServer(i *do.Injector) (*rpc.Server, error) {
+	config := do.MustInvoke[common.Config](i)
+	if !config.RPCConfig.Enabled {
+		return nil, nil
+	}
+
+	jwtValidator, err := middleware.NewJWTValidator(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create jwt validator")
+	}
+
+	// optional, like in TelemetryMiddleware
+	tel, _ := do.Invoke[*telemetry.Telemetry](i)
+
+	return rpc.NewServer(
+		rpc.NewTaskServer(do.MustInvoke[service.TaskService](i), do.MustInvoke[*validation.Validator](i)),
+		rpc.NewHealthServer(do.MustInvoke[service.HealthService](i), config.RPCConfig.HealthInterval),
+		config.RPCConfig.Reflection,
+		// in the order of the http middlewares
+		rpc.RequestIDInterceptor(),
+		rpc.TelemetryInterceptor(tel),
+		rpc.LoggerInterceptor(do.MustInvoke[zerolog.Logger](i)),
+		rpc.ErrorInterceptor(do.MustInvoke[*reporting.Reporter](i), do.MustInvoke[*redact.Redactor](i), config.ServiceName),
+		rpc.RateLimitInterceptor(do.MustInvoke[*settings.RuntimeSettings](i)),
+		// the procedures of the /secured routes
+		rpc.AuthInterceptor(jwtValidator, taskv1connect.TaskServiceListMyTasksProcedure),
+	), nil
+}
+
+// addRPCServices serves the RPC services on the port of the http server.
+// They are served before the routing of echo, their interceptors replace the middlewares
+// (the rate limit included, the body limit is the message limit of rpc.NewServer),
+// and they are not in the OpenAPI document.
+func addRPCServices(
+	e *echo.Echo,
+	injector *do.Injector,
+) {
+	server := do.MustInvoke[*rpc.Server](injector)
+	if server == nil {
+		return
+	}
+
+	// gRPC needs HTTP/2, without TLS
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+	e.Server.Protocols = protocols
+
+	e.Pre(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !server.Handles(c.Request()) {
+				return next(c)
+			}
+
+			// the underlying writer, for the trailers and the streams
+			server.ServeHTTP(c.Response().Writer, c.Request())
+			return nil
+		}
+	})
+}
@@ -3,23 +3,52 @@ package app
 import (
 	"context"
 	"golang-service-template/internal/common"
+	"golang-service-template/internal/errz"
+	"golang-service-template/internal/graph"
+	"golang-service-template/internal/openapi"
+	"golang-service-template/internal/rpc"
+	"golang-service-template/internal/service"
 	"net/http"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/rs/zerolog"
 	"github.com/samber/do"
 )
 
-func RunNewServer(
+// NewServer creates the http server with all the routes, without starting it
+func NewServer(
 	injector *do.Injector,
-) func(ctx context.Context) error {
-	logger := do.MustInvoke[zerolog.Logger](injector)
-	config := do.MustInvoke[common.Config](injector)
-
+) *echo.Echo {
 	e := echo.New()
+	// errors of the middlewares before the error renderer (e.g. body limit) are problem+json too
+	e.HTTPErrorHandler = errz.HTTPErrorHandler
 
 	addRoutes(e, injector)
 	addSocketIoRoutes(e, injector)
+	addRPCServices(e, injector)
+
+	// TestRoutesAreDocumented fails on them in the CI, this is for the routes added since
+	if drift := do.MustInvoke[*openapi.Spec](injector).Drift(e.Routes()); len(drift) > 0 {
+		logger := do.MustInvoke[zerolog.Logger](injector)
+		logger.Warn().Strs("drift", drift).Msg("the OpenAPI document does not match the routes")
+	}
+
+	return e
+}
+
+// RunNewServer starts the http server in the background.
+// It is stopped by app.Shutdown, before any of the components it uses.
+func RunNewServer(
+	injector *do.Injector,
+) {
+	logger := do.MustInvoke[zerolog.Logger](injector)
+	config := do.MustInvoke[common.Config](injector)
+	healthService := do.MustInvoke[service.HealthService](injector)
+	rpcServer := do.MustInvoke[*rpc.Server](injector)
+	graphQLServer := do.MustInvoke[*graph.Server](injector)
+
+	e := NewServer(injector)
 
 	logger.Info().Str("port", config.Port).Msg("starting server")
 
@@ -29,7 +58,33 @@ func RunNewServer(
 		}
 	}()
 
-	return func(ctx context.Context) error {
+	drainDelay := config.ShutdownConfig.DrainDelay
+	timeout := config.ShutdownConfig.Timeout
+
+	// registered last, so it runs first
+	OnShutdown(injector, "http_server", drainDelay+timeout, func(ctx context.Context) error {
+		// fail the readiness probe first, and give the load balancer some time to notice
+		healthService.Drain()
+
+		if drainDelay > 0 {
+			logger.Info().Dur("delay", drainDelay).Msg("draining, waiting before stopping the server")
+			select {
+			case <-time.After(drainDelay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		// the health Watch streams would never end
+		if rpcServer != nil {
+			rpcServer.Shutdown()
+		}
+		// nor the websockets of the GraphQL subscriptions, which are hijacked
+		if graphQLServer != nil {
+			graphQLServer.Shutdown()
+		}
+
+		// stops accepting connections and waits for the in-flight requests
 		return e.Shutdown(ctx)
-	}
+	})
 }
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"context"
+	"golang-service-template/internal/common"
+	"golang-service-template/internal/settings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"github.com/samber/do"
+)
+
+const defaultSettingsPollInterval = 10 * time.Second
+
+// NewRuntimeSettings starts from the config values, and watches the settings file and redis key when configured.
+// The watcher is stopped by app.Shutdown.
+func NewRuntimeSettings(i *do.Injector) (*settings.RuntimeSettings, error) {
+	logger := do.MustInvoke[zerolog.Logger](i)
+	config := do.MustInvoke[common.Config](i)
+
+	allowedOrigins := splitAndTrim(config.AllowedOrigins, ",")
+	if len(allowedOrigins) == 0 {
+		allowedOrigins = []string{"*"} // Default to allow all for development
+	}
+
+	runtimeSettings, err := settings.NewRuntimeSettings(settings.Settings{
+		BodyLogging:    config.BodyLogging,
+		AllowedOrigins: allowedOrigins,
+		RateLimit: settings.RateLimit{
+			RequestsPerSecond: config.RateLimitConfig.RequestsPerSecond,
+			Burst:             config.RateLimitConfig.Burst,
+		},
+	}, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	// an empty log level keeps the startup one
+	startupLevel := zerolog.GlobalLevel()
+	runtimeSettings.Subscribe(func(s settings.Settings) {
+		level := startupLevel
+		if s.LogLevel != "" {
+			// validated by RuntimeSettings
+			level, _ = zerolog.ParseLevel(s.LogLevel)
+		}
+		if level != zerolog.GlobalLevel() {
+			logger.Info().Str("level", level.String()).Msg("log level changed")
+			zerolog.SetGlobalLevel(level)
+		}
+	})
+
+	sources := []settings.Source{}
+	if config.RuntimeConfig.SettingsFile != "" {
+		sources = append(sources, settings.FileSource{Path: config.RuntimeConfig.SettingsFile})
+	}
+	if config.RuntimeConfig.SettingsRedisKey != "" {
+		sources = append(sources, settings.RedisSource{
+			Client: do.MustInvoke[*redis.Client](i),
+			Key:    config.RuntimeConfig.SettingsRedisKey,
+		})
+	}
+
+	if len(sources) == 0 {
+		return runtimeSettings, nil
+	}
+
+	interval := config.RuntimeConfig.PollInterval
+	if interval <= 0 {
+		interval = defaultSettingsPollInterval
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := runtimeSettings.Watch(ctx, interval, sources...)
+
+	logger.Info().Dur("poll_interval", interval).Int("sources", len(sources)).Msg("watching runtime settings")
+
+	// registered after redis, so the watcher stops before the redis client is closed
+	OnShutdown(i, "runtime_settings", 5*time.Second, func(ctx context.Context) error {
+		cancel()
+		<-done
+		return nil
+	})
+
+	return runtimeSettings, nil
+}
@@ -1,6 +1,8 @@
 package app
 
 import (
+	"context"
+	"golang-service-template/internal/openapi"
 	"time"
 
 	"github.com/labstack/echo/v4"
@@ -95,5 +97,17 @@ func addSocketIoRoutes(
 
 	// Add the Socket.IO server as a handler for the Echo framework.
 	// This allows the Echo server to handle WebSocket and HTTP requests for Socket.IO.
-	e.Any("/socket.io/*", echo.WrapHandler(socketio.ServeHandler(c)))
+	// the socket.io protocol, not documented in the OpenAPI document
+	do.MustInvoke[*openapi.Spec](injector).Exclude(
+		e.Any("/socket.io/*", echo.WrapHandler(socketio.ServeHandler(c)))...,
+	)
+
+	// websocket connections are hijacked, the http server does not wait for them
+	OnShutdown(injector, "socketio", 5*time.Second, func(ctx context.Context) error {
+		done := make(chan error, 1)
+		socketio.Close(func(err error) {
+			done <- err
+		})
+		return <-done
+	})
 }
@@ -3,6 +3,7 @@ package app
 import (
 	"golang-service-template/internal/common"
 	"golang-service-template/internal/telemetry"
+	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/samber/do"
@@ -12,5 +13,13 @@ func NewTelemetry(i *do.Injector) (*telemetry.Telemetry, error) {
 	config := do.MustInvoke[common.Config](i)
 	logger := do.MustInvoke[zerolog.Logger](i)
 
-	return telemetry.NewTelemetry(config.TelemetryConfig, logger)
+	t, err := telemetry.NewTelemetry(config.TelemetryConfig, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	// closed last, so the spans and metrics of the other components shutting down are flushed too
+	OnShutdown(i, "telemetry", 10*time.Second, t.Shutdown)
+
+	return t, nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"golang-service-template/internal/common"
 	"golang-service-template/internal/telemetry"
+	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/samber/do"
@@ -38,6 +39,11 @@ func NewTemporalClient(i *do.Injector) (client.Client, error) {
 	// Add OpenTelemetry tracing interceptor if telemetry is enabled
 	clientOptions.Interceptors = createTracingInterceptors(i, config, logger)
 
+	// Report Temporal SDK metrics (requests, latencies, poller/worker stats) through OpenTelemetry
+	if metricsHandler := createMetricsHandler(i, config, logger); metricsHandler != nil {
+		clientOptions.MetricsHandler = metricsHandler
+	}
+
 	c, err := client.Dial(clientOptions)
 
 	if err != nil {
@@ -50,6 +56,10 @@ func NewTemporalClient(i *do.Injector) (client.Client, error) {
 		Str("namespace", namespace).
 		Msg("Temporal client connected")
 
+	OnShutdown(i, "temporal", 5*time.Second, func(ctx context.Context) error {
+		return ShutdownTemporalClient(ctx, c)
+	})
+
 	return c, nil
 }
 
@@ -95,6 +105,29 @@ func createTracingInterceptors(i *do.Injector, config common.Config, logger zero
 	return []interceptor.ClientInterceptor{tracingInterceptor}
 }
 
+// createMetricsHandler creates an OpenTelemetry metrics handler for the Temporal SDK,
+// so its metrics end up in the same registry as the rest of the service.
+// Returns nil if telemetry or metrics are disabled.
+func createMetricsHandler(i *do.Injector, config common.Config, logger zerolog.Logger) client.MetricsHandler {
+	telemetryInstance, err := do.Invoke[*telemetry.Telemetry](i)
+	if err != nil || telemetryInstance == nil {
+		return nil
+	}
+
+	if !config.TelemetryConfig.Enabled || !config.TelemetryConfig.MetricsEnabled {
+		return nil
+	}
+
+	logger.Info().Msg("Temporal OpenTelemetry metrics enabled")
+	return opentelemetry.NewMetricsHandler(opentelemetry.MetricsHandlerOptions{
+		Meter: otel.GetMeterProvider().Meter("temporal-sdk-go"),
+		// the default panics
+		OnError: func(err error) {
+			logger.Warn().Err(err).Msg("failed to record Temporal metric")
+		},
+	})
+}
+
 func ShutdownTemporalClient(ctx context.Context, c client.Client) error {
 	if c == nil {
 		return nil
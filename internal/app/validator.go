@@ -0,0 +1,12 @@
+package app
+
+import (
+	"golang-service-template/internal/validation"
+
+	"github.com/samber/do"
+)
+
+// NewValidator validates the requests, built once with the rules registered in the validation package
+func NewValidator(i *do.Injector) (*validation.Validator, error) {
+	return validation.New()
+}
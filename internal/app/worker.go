@@ -1,49 +1,34 @@
-package main
+package app
 
 import (
 	"context"
-	"io"
-	"log"
-	"os"
-	"os/signal"
-
-	"golang-service-template/internal/app"
 	"golang-service-template/internal/common"
 	"golang-service-template/internal/temporal/activity"
 	"golang-service-template/internal/temporal/workflow"
+	"time"
 
-	"github.com/joho/godotenv"
+	"github.com/cockroachdb/errors"
 	"github.com/rs/zerolog"
 	"github.com/samber/do"
 	"go.temporal.io/sdk/client"
 	"go.temporal.io/sdk/worker"
 )
 
-func run(
-	ctx context.Context,
-	getenv func(string) string,
-	stdout, stderr io.Writer,
-) error {
-	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
-	defer stop()
-
-	injector := app.NewInjector(
-		ctx,
-		getenv,
-		stdout,
-		stderr,
-	)
+// RunNewWorker starts the Temporal worker in the background.
+// It is stopped by app.Shutdown, before the Temporal client is closed.
+// Returns false when Temporal is not configured.
+func RunNewWorker(
+	injector *do.Injector,
+) (bool, error) {
 	logger := do.MustInvoke[zerolog.Logger](injector)
 	config := do.MustInvoke[common.Config](injector)
 
-	// Get Temporal client
 	temporalClient, err := do.Invoke[client.Client](injector)
 	if err != nil || temporalClient == nil {
 		logger.Warn().Msg("Temporal client not available, worker will not start")
-		return nil
+		return false, nil
 	}
 
-	// Create worker
 	taskQueue := config.TemporalConfig.TaskQueue
 	if taskQueue == "" {
 		taskQueue = "task-notifications"
@@ -59,41 +44,21 @@ func run(
 	w.RegisterActivity(activity.SendSMSNotification)
 	w.RegisterActivity(activity.SendPushNotification)
 
-	// Start worker
 	logger.Info().
 		Str("task_queue", taskQueue).
 		Str("namespace", config.TemporalConfig.Namespace).
 		Msg("Starting Temporal worker")
 
 	if err := w.Start(); err != nil {
-		logger.Fatal().Err(err).Msg("Unable to start worker")
-		return err
+		return false, errors.Wrap(err, "unable to start worker")
 	}
 
-	// Wait for interrupt signal
-	<-ctx.Done()
-	logger.Info().Msg("Shutting down Temporal worker")
-
-	w.Stop()
-	return nil
-}
-
-func main() {
-	err := godotenv.Load()
-	if err != nil {
-		log.Println("Error loading .env file")
-	}
-
-	ctx := context.Background()
-
-	err = run(
-		ctx,
-		os.Getenv,
-		os.Stdout,
-		os.Stderr,
-	)
+	// registered after the temporal client, so the worker stops before the client is closed
+	OnShutdown(injector, "temporal_worker", 30*time.Second, func(ctx context.Context) error {
+		// waits for the running activities, up to worker.Options.WorkerStopTimeout
+		w.Stop()
+		return nil
+	})
 
-	if err != nil {
-		log.Fatalf("Worker failed: %v", err)
-	}
+	return true, nil
 }
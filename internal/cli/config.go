@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+func newConfigCommand(opts *rootOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the config",
+	}
+
+	var asJSON bool
+
+	print := &cobra.Command{
+		Use:   "print",
+		Short: "Print the effective config, secrets redacted, with where each value came from",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// an invalid config is printed too, it's usually why we look at it
+			config, origins, loadErr := opts.loadConfig()
+			entries := config.Effective(origins)
+
+			if asJSON {
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				if err := encoder.Encode(entries); err != nil {
+					return err
+				}
+				return loadErr
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "KEY\tVALUE\tSOURCE\tFROM")
+			for _, entry := range entries {
+				value, ok := entry.Value.(string)
+				if !ok {
+					encoded, _ := json.Marshal(entry.Value)
+					value = string(encoded)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", entry.Key, value, entry.Source, entry.From)
+			}
+
+			if err := w.Flush(); err != nil {
+				return err
+			}
+
+			return loadErr
+		},
+	}
+
+	print.Flags().BoolVar(&asJSON, "json", false, "print as json")
+
+	cmd.AddCommand(print)
+
+	return cmd
+}
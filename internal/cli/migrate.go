@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"fmt"
+	"golang-service-template/internal/app"
+	"golang-service-template/internal/common"
+	"golang-service-template/migration"
+	"strconv"
+
+	"github.com/cockroachdb/errors"
+	"github.com/samber/do"
+	"github.com/spf13/cobra"
+)
+
+func newMigrateCommand(opts *rootOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Apply or roll back the database migrations in migration/migrations",
+	}
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "up",
+			Short: "Apply all the pending migrations",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return opts.withMigrator(cmd, func(migrator *migration.Migrator) error {
+					applied, err := migrator.Up(cmd.Context())
+					for _, m := range applied {
+						fmt.Fprintf(cmd.OutOrStdout(), "applied %s\n", m)
+					}
+					if err == nil && len(applied) == 0 {
+						fmt.Fprintln(cmd.OutOrStdout(), "no change")
+					}
+					return err
+				})
+			},
+		},
+		&cobra.Command{
+			Use:   "down [steps]",
+			Short: "Roll back the last applied migrations, 1 by default",
+			Args:  cobra.MaximumNArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				steps := 1
+				if len(args) == 1 {
+					parsed, err := strconv.Atoi(args[0])
+					if err != nil || parsed < 1 {
+						return errors.Newf("steps must be a positive number, got %q", args[0])
+					}
+					steps = parsed
+				}
+
+				return opts.withMigrator(cmd, func(migrator *migration.Migrator) error {
+					rolledBack, err := migrator.Down(cmd.Context(), steps)
+					for _, m := range rolledBack {
+						fmt.Fprintf(cmd.OutOrStdout(), "rolled back %s\n", m)
+					}
+					if err == nil && len(rolledBack) == 0 {
+						fmt.Fprintln(cmd.OutOrStdout(), "no change")
+					}
+					return err
+				})
+			},
+		},
+		&cobra.Command{
+			Use:   "version",
+			Short: "Print the currently applied migration version",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return opts.withMigrator(cmd, func(migrator *migration.Migrator) error {
+					version, dirty, err := migrator.Version(cmd.Context())
+					if err != nil {
+						return err
+					}
+
+					if dirty {
+						fmt.Fprintf(cmd.OutOrStdout(), "%d (dirty)\n", version)
+						return nil
+					}
+
+					fmt.Fprintln(cmd.OutOrStdout(), version)
+					return nil
+				})
+			},
+		},
+	)
+
+	return cmd
+}
+
+func (opts *rootOptions) withMigrator(cmd *cobra.Command, fn func(migrator *migration.Migrator) error) error {
+	injector, err := opts.bootstrap(cmd.Context())
+	if err != nil {
+		return err
+	}
+	defer app.Shutdown(injector) //nolint:errcheck
+
+	// a connection of its own, the pool of the app does not run several statements at once
+	db, err := app.OpenMigrationDB(do.MustInvoke[common.Config](injector))
+	if err != nil {
+		return err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	migrator, err := migration.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	return fn(migrator)
+}
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"golang-service-template/internal/app"
+	"golang-service-template/internal/openapi"
+
+	"github.com/cockroachdb/errors"
+	"github.com/samber/do"
+	"github.com/spf13/cobra"
+)
+
+func newOpenAPICommand(opts *rootOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "openapi",
+		Short: "Print or check the OpenAPI document of the http routes",
+		Long:  "Print or check the OpenAPI document of the http routes. The controllers are created to register them, so it needs the same config as serve.",
+	}
+
+	print := &cobra.Command{
+		Use:   "print",
+		Short: "Print the OpenAPI document, as served at /openapi.json",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			injector, err := opts.bootstrap(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer app.Shutdown(injector) //nolint:errcheck
+
+			app.NewServer(injector)
+
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(do.MustInvoke[*openapi.Spec](injector).Document())
+		},
+	}
+
+	check := &cobra.Command{
+		Use:   "check",
+		Short: "Fail when a route is not documented, for the CI",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			injector, err := opts.bootstrap(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer app.Shutdown(injector) //nolint:errcheck
+
+			routes := app.NewServer(injector).Routes()
+
+			drift := do.MustInvoke[*openapi.Spec](injector).Drift(routes)
+			for _, line := range drift {
+				fmt.Fprintln(cmd.OutOrStdout(), line)
+			}
+			if len(drift) > 0 {
+				return errors.Newf("the OpenAPI document does not match the routes: %d differences", len(drift))
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), "the OpenAPI document matches the routes")
+			return nil
+		},
+	}
+
+	cmd.AddCommand(print, check)
+
+	return cmd
+}
@@ -0,0 +1,110 @@
+// Package cli holds the commands of the service binary.
+// They all share the same bootstrap: env file, config and dependency injection.
+package cli
+
+import (
+	"context"
+	"golang-service-template/internal/app"
+	"golang-service-template/internal/common"
+	"io"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/joho/godotenv"
+	"github.com/samber/do"
+	"github.com/spf13/cobra"
+)
+
+type rootOptions struct {
+	envFile    string
+	configFile string
+	overrides  []string
+
+	getenv         func(string) string
+	stdout, stderr io.Writer
+}
+
+// NewRootCommand creates the service command with all its subcommands
+func NewRootCommand(
+	getenv func(string) string,
+	stdout, stderr io.Writer,
+) *cobra.Command {
+	opts := &rootOptions{
+		getenv: getenv,
+		stdout: stdout,
+		stderr: stderr,
+	}
+
+	cmd := &cobra.Command{
+		Use:           "service",
+		Short:         "golang-service-template: http server, temporal worker and admin tasks",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+
+	cmd.PersistentFlags().StringVar(&opts.envFile, "env-file", ".env", "env file to load, ignored if it does not exist")
+	cmd.PersistentFlags().StringVar(&opts.configFile, "config", "", "yaml or toml config file, defaults to $CONFIG_FILE")
+	cmd.PersistentFlags().StringArrayVar(&opts.overrides, "set", nil, "override a config key, e.g. --set db.host=localhost (repeatable)")
+
+	cmd.AddCommand(
+		newServeCommand(opts),
+		newWorkerCommand(opts),
+		newMigrateCommand(opts),
+		newSeedCommand(opts),
+		newUserCommand(opts),
+		newTokenCommand(opts),
+		newRoutesCommand(opts),
+		newOpenAPICommand(opts),
+		newConfigCommand(opts),
+	)
+
+	return cmd
+}
+
+// bootstrap loads the config and creates the injector, shared by every command.
+// Components are created lazily, so a command only connects to what it uses.
+func (opts *rootOptions) bootstrap(ctx context.Context) (*do.Injector, error) {
+	config, origins, err := opts.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return app.NewInjector(
+		ctx,
+		config,
+		origins,
+		opts.stdout,
+		opts.stderr,
+	), nil
+}
+
+// loadConfig reads the env file, the config file and the --set flags
+func (opts *rootOptions) loadConfig() (common.Config, common.ConfigOrigins, error) {
+	if opts.envFile != "" {
+		// already set env vars win over the file
+		_ = godotenv.Load(opts.envFile)
+	}
+
+	source := app.ConfigSource{
+		Getenv:    opts.getenv,
+		File:      opts.configFile,
+		Overrides: map[string]string{},
+	}
+
+	if source.File == "" {
+		source.File = opts.getenv("CONFIG_FILE")
+	}
+
+	for _, override := range opts.overrides {
+		key, value, found := strings.Cut(override, "=")
+		if !found {
+			return common.Config{}, nil, errors.Newf("--set %s: expected key=value", override)
+		}
+		source.Overrides[strings.TrimSpace(key)] = value
+	}
+
+	return app.NewConfig(source)
+}
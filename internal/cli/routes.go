@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"fmt"
+	"golang-service-template/internal/app"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+func newRoutesCommand(opts *rootOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "routes",
+		Short: "List the http routes",
+		Long:  "List the http routes. The controllers are created to register them, so it needs the same config as serve.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			injector, err := opts.bootstrap(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer app.Shutdown(injector) //nolint:errcheck
+
+			routes := app.NewServer(injector).Routes()
+
+			sort.Slice(routes, func(a, b int) bool {
+				if routes[a].Path != routes[b].Path {
+					return routes[a].Path < routes[b].Path
+				}
+				return routes[a].Method < routes[b].Method
+			})
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "METHOD\tPATH\tHANDLER")
+			for _, route := range routes {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", route.Method, route.Path, route.Name)
+			}
+
+			return w.Flush()
+		},
+	}
+}
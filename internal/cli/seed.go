@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"fmt"
+	"golang-service-template/internal/app"
+	"golang-service-template/internal/dao/model"
+	"golang-service-template/internal/dao/query"
+	"golang-service-template/internal/errz"
+	"golang-service-template/internal/service"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/samber/do"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+func newSeedCommand(opts *rootOptions) *cobra.Command {
+	var (
+		email    string
+		password string
+		tasks    int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "seed",
+		Short: "Create a demo user and some tasks owned by it, for local development, unless they exist",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			injector, err := opts.bootstrap(ctx)
+			if err != nil {
+				return err
+			}
+			defer app.Shutdown(injector) //nolint:errcheck
+
+			userService := do.MustInvoke[service.UserService](injector)
+
+			// seeding twice must not fail, reuse the demo user if it is there
+			user, err := userService.GetByEmail(ctx, email)
+			if err != nil && errz.StatusCode(err) != http.StatusNotFound {
+				return err
+			}
+			if err != nil {
+				user, err = userService.Create(ctx, email, password)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "created user %s (%s)\n", user.Email, user.ID)
+			}
+
+			q := query.Use(do.MustInvoke[*gorm.DB](injector))
+
+			// and its tasks, unless it has some
+			existing, err := q.WithContext(ctx).Task.Where(q.Task.CreatedBy.Eq(user.ID)).Count()
+			if err != nil {
+				return err
+			}
+			if existing > 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s has %d tasks already\n", user.Email, existing)
+				return nil
+			}
+
+			for index := range tasks {
+				id, err := uuid.NewV7()
+				if err != nil {
+					return err
+				}
+
+				task := &model.Task{
+					ID:          id.String(),
+					Description: fmt.Sprintf("demo task %d", index+1),
+					State:       "todo",
+					CreatedBy:   user.ID,
+				}
+
+				if err := q.WithContext(ctx).Task.Create(task); err != nil {
+					return err
+				}
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "created %d tasks for %s\n", tasks, user.Email)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&email, "email", "demo@example.com", "email of the demo user")
+	cmd.Flags().StringVar(&password, "password", "password", "password of the demo user")
+	cmd.Flags().IntVar(&tasks, "tasks", 5, "number of tasks to create")
+
+	return cmd
+}
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"golang-service-template/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+func newServeCommand(opts *rootOptions) *cobra.Command {
+	var withWorker bool
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run the http server",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			injector, err := opts.bootstrap(ctx)
+			if err != nil {
+				return err
+			}
+
+			app.RunNewServer(injector)
+
+			if withWorker {
+				if _, err := app.RunNewWorker(injector); err != nil {
+					_ = app.Shutdown(injector)
+					return err
+				}
+			}
+
+			<-ctx.Done()
+
+			// the http server stops first, then every component in reverse dependency order,
+			// each with its own timeout
+			return app.Shutdown(injector)
+		},
+	}
+
+	cmd.Flags().BoolVar(&withWorker, "with-worker", false, "also run the Temporal worker in the same process, for small deployments")
+
+	return cmd
+}
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"fmt"
+	"golang-service-template/internal/common"
+	"golang-service-template/internal/middleware"
+	"time"
+
+	"github.com/samber/do"
+	"github.com/spf13/cobra"
+)
+
+func newTokenCommand(opts *rootOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "token",
+		Short: "Manage JWT tokens",
+	}
+
+	var (
+		subject string
+		roles   []string
+		tenant  string
+		ttl     time.Duration
+	)
+
+	mint := &cobra.Command{
+		Use:   "mint",
+		Short: "Sign a JWT with the configured secret, issuer and audience",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			injector, err := opts.bootstrap(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			token, err := middleware.MintJWT(do.MustInvoke[common.Config](injector), subject, middleware.CustomClaims{
+				Roles:  roles,
+				Tenant: tenant,
+			}, ttl)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), token)
+			return nil
+		},
+	}
+
+	mint.Flags().StringVar(&subject, "sub", "", "subject of the token, the user id")
+	mint.Flags().StringArrayVar(&roles, "role", nil, "role of the token, e.g. --role admin for the /admin routes (repeatable)")
+	mint.Flags().StringVar(&tenant, "tenant", "", "tenant of the user, to target feature flags")
+	mint.Flags().DurationVar(&ttl, "ttl", time.Hour, "validity of the token")
+	_ = mint.MarkFlagRequired("sub")
+
+	cmd.AddCommand(mint)
+
+	return cmd
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"fmt"
+	"golang-service-template/internal/app"
+	"golang-service-template/internal/service"
+
+	"github.com/samber/do"
+	"github.com/spf13/cobra"
+)
+
+func newUserCommand(opts *rootOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "user",
+		Short: "Manage users",
+	}
+
+	var (
+		email    string
+		password string
+	)
+
+	create := &cobra.Command{
+		Use:   "create",
+		Short: "Create a user",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			injector, err := opts.bootstrap(ctx)
+			if err != nil {
+				return err
+			}
+			defer app.Shutdown(injector) //nolint:errcheck
+
+			user, err := do.MustInvoke[service.UserService](injector).Create(ctx, email, password)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "created user %s (%s)\n", user.Email, user.ID)
+			return nil
+		},
+	}
+
+	create.Flags().StringVar(&email, "email", "", "email of the user")
+	create.Flags().StringVar(&password, "password", "", "password of the user")
+	_ = create.MarkFlagRequired("email")
+	_ = create.MarkFlagRequired("password")
+
+	cmd.AddCommand(create)
+
+	return cmd
+}
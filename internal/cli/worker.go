@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"golang-service-template/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+func newWorkerCommand(opts *rootOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "worker",
+		Short: "Run the Temporal worker",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			injector, err := opts.bootstrap(ctx)
+			if err != nil {
+				return err
+			}
+
+			started, err := app.RunNewWorker(injector)
+			if err != nil || !started {
+				_ = app.Shutdown(injector)
+				return err
+			}
+
+			<-ctx.Done()
+
+			return app.Shutdown(injector)
+		},
+	}
+}
@@ -1,51 +1,209 @@
 package common
 
+import "time"
+
+// Config is loaded by app.NewConfig, every layer overriding the previous one:
+// the `default` tag, then the config file, then the `env` var, then the `--set key=value` flags.
+//
+// The file and flag key of a field is the `key` tag of the field, prefixed by the keys of its parents,
+// e.g. `db.host` or `telemetry.otel_endpoint`.
+// Every env var can also be read from a file with the `_FILE` suffix (e.g. `DB_PASSWORD_FILE=/run/secrets/db_password`),
+// for Docker and Kubernetes secrets.
+//
+// Fields tagged `secret:"true"` are redacted by Config.Effective (`config print`, GET /admin/config).
 type Config struct {
-	ServiceName               string `validate:"required"`
-	Host                      string
-	Port                      string `validate:"required"`
-	HealthcheckTimeoutSeconds int    `validate:"min=1"`
-	DbConfig                  `validate:"required"`
-	RedisConfig               `validate:"required"`
-	TelemetryConfig           `validate:"required"`
-	JWTConfig                 `validate:"required"`
-	AllowedOrigins            string `validate:""` // Comma-separated list of allowed CORS origins
-	TemporalConfig            `validate:""`
+	// Name of the service, also used as the telemetry service name
+	ServiceName string `key:"service_name" env:"SERVICE_NAME" validate:"required"`
+	// Host the http server listens on, all interfaces if empty
+	Host string `key:"host" env:"HOST"`
+	// Port the http server listens on
+	Port string `key:"port" env:"PORT" default:"8080" validate:"required"`
+	// TTL of the readiness flag cached in redis
+	HealthcheckTimeoutSeconds int `key:"healthcheck_timeout_seconds" env:"HEALTHCHECK_TIMEOUT_SECONDS" default:"55" validate:"min=1"`
+	// Comma-separated list of allowed CORS origins, all origins if empty or "*"
+	AllowedOrigins string `key:"allowed_origins" env:"ALLOWED_ORIGINS"`
+	// Log request and response bodies
+	BodyLogging bool `key:"body_logging" env:"ENABLE_BODY_LOGGING"`
+
+	LogConfig            `key:"log"`
+	DbConfig             `key:"db" validate:"required"`
+	RedisConfig          `key:"redis" validate:"required"`
+	TelemetryConfig      `key:"telemetry" validate:"required"`
+	JWTConfig            `key:"jwt" validate:"required"`
+	TemporalConfig       `key:"temporal"`
+	ShutdownConfig       `key:"shutdown"`
+	RateLimitConfig      `key:"rate_limit"`
+	RuntimeConfig        `key:"runtime"`
+	FlagsConfig          `key:"flags"`
+	ErrorReportingConfig `key:"error_reporting"`
+	OpenAPIConfig        `key:"openapi"`
+	RPCConfig            `key:"rpc"`
+	GraphQLConfig        `key:"graphql"`
+}
+
+// IsDevelopment is true when ENVIRONMENT is development
+func (c Config) IsDevelopment() bool {
+	return c.TelemetryConfig.Environment == "development"
+}
+
+// IsProduction is true when ENVIRONMENT is production
+func (c Config) IsProduction() bool {
+	return c.TelemetryConfig.Environment == "production"
+}
+
+type LogConfig struct {
+	// Startup log level, the runtime settings log_level overrides it
+	Level string `key:"level" env:"LOG_LEVEL" default:"info" validate:"oneof=trace debug info warn error fatal panic disabled"`
+	// json, or console for humans in local development
+	Format string `key:"format" env:"LOG_FORMAT" default:"json" validate:"oneof=json console"`
+	// Add the file:line of the log call
+	Caller bool `key:"caller" env:"LOG_CALLER"`
+	// Keep 1 of every N logs by level, after the first SamplingBurst of each second, e.g. info=10,debug=100.
+	// warn and above are never sampled.
+	Sampling map[string]string `key:"sampling" env:"LOG_SAMPLING" validate:"dive,keys,oneof=trace debug info,endkeys,number"`
+	// Logs per level and per second kept before sampling
+	SamplingBurst int `key:"sampling_burst" env:"LOG_SAMPLING_BURST" default:"100" validate:"min=0"`
+	// Keys redacted from the logged bodies and headers, on top of redact.DefaultKeys
+	RedactKeys []string `key:"redact_keys" env:"LOG_REDACT_KEYS"`
+	// Regular expressions redacted from the logged bodies and headers, on top of redact.DefaultPatterns
+	// (they can't contain commas in LOG_REDACT_PATTERNS, use a list in the config file)
+	RedactPatterns []string `key:"redact_patterns" env:"LOG_REDACT_PATTERNS"`
 }
 
 type TelemetryConfig struct {
-	Enabled        bool   `validate:""`
-	OtelEndpoint   string `validate:""`
-	ServiceName    string `validate:""`
-	ServiceVersion string `validate:""`
-	Environment    string `validate:""`
-	MetricsEnabled bool   `validate:""`
-	TracingEnabled bool   `validate:""`
+	Enabled        bool   `key:"enabled" env:"TELEMETRY_ENABLED"`
+	OtelEndpoint   string `key:"otel_endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	ServiceName    string `key:"-"` // Always Config.ServiceName
+	ServiceVersion string `key:"service_version" env:"SERVICE_VERSION"`
+	Environment    string `key:"environment" env:"ENVIRONMENT"`
+	MetricsEnabled bool   `key:"metrics_enabled" env:"TELEMETRY_METRICS_ENABLED"`
+	TracingEnabled bool   `key:"tracing_enabled" env:"TELEMETRY_TRACING_ENABLED"`
+	LogsEnabled    bool   `key:"logs_enabled" env:"TELEMETRY_LOGS_ENABLED"`
+
+	// OTLP exporter settings, shared by traces, metrics and logs
+	OtelProtocol   string            `key:"otel_protocol" env:"OTEL_EXPORTER_OTLP_PROTOCOL" default:"http/protobuf" validate:"omitempty,oneof=grpc http/protobuf"`
+	OtelInsecure   bool              `key:"otel_insecure" env:"OTEL_EXPORTER_OTLP_INSECURE"`
+	OtelHeaders    map[string]string `key:"otel_headers" env:"OTEL_EXPORTER_OTLP_HEADERS" secret:"true"` // e.g. api-key=xxx,tenant=yyy
+	OtelCACertFile string            `key:"otel_ca_cert_file" env:"OTEL_EXPORTER_OTLP_CERTIFICATE"`      // Optional, system roots are used if not set
+
+	MetricsExporters             []string `key:"metrics_exporters" env:"TELEMETRY_METRICS_EXPORTER" default:"prometheus" validate:"dive,oneof=prometheus otlp"`
+	MetricsExportIntervalSeconds int      `key:"metrics_export_interval_seconds" env:"TELEMETRY_METRICS_EXPORT_INTERVAL_SECONDS" default:"60" validate:"min=0"` // OTLP push interval
+
+	TracesSampler      string  `key:"traces_sampler" env:"OTEL_TRACES_SAMPLER" default:"parentbased_always_on" validate:"omitempty,oneof=always_on always_off traceidratio parentbased_always_on parentbased_always_off parentbased_traceidratio"`
+	TracesSamplerRatio float64 `key:"traces_sampler_ratio" env:"OTEL_TRACES_SAMPLER_ARG" default:"1" validate:"min=0,max=1"`
 }
 
 type RedisConfig struct {
-	Address string `validate:"required"`
+	Address string `key:"address" env:"REDIS_ADDRESS" validate:"required"`
+	// Channel the changes of the tasks are published on, for the GraphQL subscriptions of every instance
+	TaskEventsChannel string `key:"task_events_channel" env:"REDIS_TASK_EVENTS_CHANNEL" default:"task_events" validate:"required"`
 }
 
 type DbConfig struct {
-	Dialect string `validate:"required"`
-	Host    string `validate:"required"`
-	Port    string `validate:"required"`
-	DBName  string `validate:"required"`
+	Dialect string `key:"dialect" env:"DB_DIALECT" validate:"required,oneof=postgres mysql"`
+	Host    string `key:"host" env:"DB_HOST" validate:"required"`
+	Port    string `key:"port" env:"DB_PORT" validate:"required"`
+	DBName  string `key:"name" env:"DB_DBNAME" validate:"required"`
 
-	Username string `validate:"required"`
-	Password string `validate:"required"`
-	SslMode  string `validate:""` // Optional, defaults to "require" if not set
+	Username string `key:"username" env:"DB_USERNAME" validate:"required"`
+	Password string `key:"password" env:"DB_PASSWORD" secret:"true" validate:"required"`
+	// postgres only: disable, require, verify-ca or verify-full
+	SslMode string `key:"sslmode" env:"DB_SSLMODE" default:"require"`
 }
 
 type JWTConfig struct {
-	Secret   string `validate:"required"`
-	Issuer   string `validate:"required"`
-	Audience string `validate:"required"`
+	// HS256 secret, generate one with `openssl rand -base64 32`
+	Secret   string `key:"secret" env:"JWT_SECRET" secret:"true" validate:"required"`
+	Issuer   string `key:"issuer" env:"JWT_ISSUER" validate:"required"`
+	Audience string `key:"audience" env:"JWT_AUDIENCE" validate:"required"`
 }
 
 type TemporalConfig struct {
-	Address   string `validate:""`
-	Namespace string `validate:""`
-	TaskQueue string `validate:""`
+	// The Temporal client is not created if empty
+	Address   string `key:"address" env:"TEMPORAL_ADDRESS"`
+	Namespace string `key:"namespace" env:"TEMPORAL_NAMESPACE" default:"default"`
+	TaskQueue string `key:"task_queue" env:"TEMPORAL_TASK_QUEUE" default:"task-notifications"`
+}
+
+type ShutdownConfig struct {
+	// Time given to in-flight requests to finish
+	Timeout time.Duration `key:"timeout" env:"SHUTDOWN_TIMEOUT" default:"10s"`
+	// Time between readiness failing and the server refusing new requests,
+	// set it when running behind a load balancer
+	DrainDelay time.Duration `key:"drain_delay" env:"SHUTDOWN_DRAIN_DELAY" default:"0s"`
+}
+
+type RateLimitConfig struct {
+	// Requests per second per client IP, 0 disables the rate limit
+	RequestsPerSecond float64 `key:"requests_per_second" env:"RATE_LIMIT_RPS" default:"0" validate:"min=0"`
+	// Requests allowed at once above the rate, defaults to the rate
+	Burst int `key:"burst" env:"RATE_LIMIT_BURST" default:"0" validate:"min=0"`
+}
+
+// RuntimeConfig is where the runtime settings are watched.
+// The config values of the runtime settings (ALLOWED_ORIGINS, ENABLE_BODY_LOGGING, the rate limit)
+// are the base the watched documents are applied over.
+type RuntimeConfig struct {
+	// yaml or json file, e.g. a mounted ConfigMap
+	SettingsFile string `key:"settings_file" env:"RUNTIME_SETTINGS_FILE"`
+	// redis key holding a yaml or json document, applied over the file
+	SettingsRedisKey string        `key:"settings_redis_key" env:"RUNTIME_SETTINGS_REDIS_KEY"`
+	PollInterval     time.Duration `key:"poll_interval" env:"RUNTIME_SETTINGS_POLL_INTERVAL" default:"10s"`
+}
+
+type FlagsConfig struct {
+	// Default variant by flag key, e.g. task_notifications=false,task_list_order=newest_first
+	Defaults map[string]string `key:"defaults" env:"FEATURE_FLAGS"`
+	// redis hash holding the overrides managed by the /admin/flags API
+	RedisKey string `key:"redis_key" env:"FEATURE_FLAGS_REDIS_KEY" default:"feature_flags" validate:"required"`
+	// How long each instance caches the overrides
+	RefreshInterval time.Duration `key:"refresh_interval" env:"FEATURE_FLAGS_REFRESH_INTERVAL" default:"5s"`
+}
+
+// ErrorReportingConfig is where the server errors and the panics are reported
+type ErrorReportingConfig struct {
+	// none, sentry (SENTRY_DSN) or file (ERROR_REPORT_FILE, JSON lines)
+	Reporter  string `key:"reporter" env:"ERROR_REPORTER" default:"none" validate:"oneof=none sentry file"`
+	SentryDSN string `key:"sentry_dsn" env:"SENTRY_DSN" secret:"true" validate:"required_if=Reporter sentry"`
+	File      string `key:"file" env:"ERROR_REPORT_FILE" default:"errors.jsonl"`
+	// Events sent per fingerprint per window, 0 for no limit
+	RateLimit  int           `key:"rate_limit" env:"ERROR_REPORT_RATE_LIMIT" default:"10" validate:"min=0"`
+	RateWindow time.Duration `key:"rate_window" env:"ERROR_REPORT_RATE_WINDOW" default:"1m"`
+}
+
+// OpenAPIConfig is the API documentation, the document is always served at /openapi.json
+type OpenAPIConfig struct {
+	// Serve the Swagger UI at /docs, it loads its assets from a CDN
+	DocsEnabled bool `key:"docs_enabled" env:"OPENAPI_DOCS_ENABLED"`
+	// Hand-written OpenAPI document (yaml or json) the requests are validated against, none if empty.
+	// The responses are validated too, unless ENVIRONMENT is production.
+	ValidationFile string `key:"validation_file" env:"OPENAPI_VALIDATION_FILE"`
+}
+
+// RPCConfig is the Connect, gRPC and gRPC-Web API of the tasks, served on the port of the http server.
+// gRPC needs HTTP/2, it is served without TLS (h2c), terminate the TLS before the service.
+type RPCConfig struct {
+	Enabled bool `key:"enabled" env:"RPC_ENABLED" default:"true"`
+	// Serve the gRPC reflection, for grpcurl and the like. It lists the whole schema, enable it in development only
+	Reflection bool `key:"reflection" env:"RPC_REFLECTION" default:"false"`
+	// How often the Watch streams of the gRPC health service run the readiness check
+	HealthInterval time.Duration `key:"health_interval" env:"RPC_HEALTH_INTERVAL" default:"5s" validate:"gt=0"`
+}
+
+// GraphQLConfig is the GraphQL API of the tasks and the users, at /graphql.
+// The subscriptions are served over websockets, with the graphql-transport-ws protocol.
+type GraphQLConfig struct {
+	Enabled bool `key:"enabled" env:"GRAPHQL_ENABLED" default:"true"`
+	// Answer the introspection queries, for GraphiQL and the code generators. It lists the whole schema, enable it in development only
+	Introspection bool `key:"introspection" env:"GRAPHQL_INTROSPECTION" default:"false"`
+	// Deepest nesting of the selections of an operation
+	MaxDepth int `key:"max_depth" env:"GRAPHQL_MAX_DEPTH" default:"8" validate:"min=1"`
+	// Highest cost of an operation: every field costs 1, and the fields under a list count for 10 items
+	MaxComplexity int `key:"max_complexity" env:"GRAPHQL_MAX_COMPLEXITY" default:"1000" validate:"min=1"`
+	// How long the automatic persisted queries are kept in redis after their last use
+	PersistedQueriesTTL time.Duration `key:"persisted_queries_ttl" env:"GRAPHQL_PERSISTED_QUERIES_TTL" default:"24h" validate:"gt=0"`
+	// JSON file of the queries by their sha256 hash, e.g. generated by the build of the frontend, none if empty
+	PersistedQueriesFile string `key:"persisted_queries_file" env:"GRAPHQL_PERSISTED_QUERIES_FILE" validate:"required_if=PersistedQueriesOnly true"`
+	// Only run the queries of PersistedQueriesFile, the other queries are rejected
+	PersistedQueriesOnly bool `key:"persisted_queries_only" env:"GRAPHQL_PERSISTED_QUERIES_ONLY"`
 }
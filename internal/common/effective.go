@@ -0,0 +1,135 @@
+package common
+
+import (
+	"reflect"
+	"time"
+)
+
+const (
+	ConfigSourceDefault = "default"
+	ConfigSourceFile    = "file"
+	ConfigSourceEnv     = "env"
+	ConfigSourceFlag    = "flag"
+	ConfigSourceUnset   = "unset"
+
+	// RedactedValue replaces the value of the fields tagged `secret:"true"`
+	RedactedValue = "[REDACTED]"
+)
+
+// ConfigOrigin is where a config value came from
+type ConfigOrigin struct {
+	Source string `json:"source"`
+	// e.g. the env var name (DB_PASSWORD or DB_PASSWORD_FILE), or the config file path
+	From string `json:"from,omitempty"`
+}
+
+// ConfigOrigins maps each config key (e.g. db.host) to where its value came from
+type ConfigOrigins map[string]ConfigOrigin
+
+// ConfigField is a leaf of Config, found by walking the `key` tags
+type ConfigField struct {
+	Key        string // e.g. db.host
+	Env        string // e.g. DB_HOST
+	Default    string
+	HasDefault bool
+	Secret     bool
+	Value      reflect.Value
+}
+
+// ConfigEntry is one resolved config key, as shown by `config print` and GET /admin/config
+type ConfigEntry struct {
+	Key   string `json:"key"`
+	Env   string `json:"env,omitempty"`
+	Value any    `json:"value"`
+	ConfigOrigin
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// ConfigFields lists the leaves of the config, the values are settable
+func ConfigFields(config *Config) []ConfigField {
+	return configFields(reflect.ValueOf(config).Elem(), "")
+}
+
+func configFields(value reflect.Value, prefix string) []ConfigField {
+	fields := []ConfigField{}
+
+	for index := 0; index < value.NumField(); index++ {
+		structField := value.Type().Field(index)
+
+		key := structField.Tag.Get("key")
+		if key == "-" || key == "" {
+			continue
+		}
+
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		if structField.Type.Kind() == reflect.Struct && structField.Type != durationType {
+			fields = append(fields, configFields(value.Field(index), key)...)
+			continue
+		}
+
+		def, hasDefault := structField.Tag.Lookup("default")
+		fields = append(fields, ConfigField{
+			Key:        key,
+			Env:        structField.Tag.Get("env"),
+			Default:    def,
+			HasDefault: hasDefault,
+			Secret:     structField.Tag.Get("secret") == "true",
+			Value:      value.Field(index),
+		})
+	}
+
+	return fields
+}
+
+// Effective lists every config key with its value and origin, secrets redacted
+func (config Config) Effective(origins ConfigOrigins) []ConfigEntry {
+	entries := []ConfigEntry{}
+
+	for _, field := range ConfigFields(&config) {
+		origin, ok := origins[field.Key]
+		if !ok {
+			origin = ConfigOrigin{Source: ConfigSourceUnset}
+		}
+
+		entries = append(entries, ConfigEntry{
+			Key:          field.Key,
+			Env:          field.Env,
+			Value:        entryValue(field),
+			ConfigOrigin: origin,
+		})
+	}
+
+	return entries
+}
+
+func entryValue(field ConfigField) any {
+	value := field.Value
+
+	if value.Type() == durationType {
+		return value.Interface().(time.Duration).String()
+	}
+
+	if !field.Secret {
+		return value.Interface()
+	}
+
+	// keep the shape, so it's visible whether a secret is set
+	switch value.Kind() {
+	case reflect.Map:
+		redacted := map[string]string{}
+		for _, key := range value.MapKeys() {
+			redacted[key.String()] = RedactedValue
+		}
+		return redacted
+	case reflect.String:
+		if value.String() == "" {
+			return ""
+		}
+	}
+
+	return RedactedValue
+}
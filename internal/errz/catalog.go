@@ -0,0 +1,157 @@
+package errz
+
+import (
+	"net/http"
+	"sort"
+)
+
+// Codes of the catalog, the `code` of every error response.
+// Add new codes here, with their status and titles, never reuse a code for another meaning.
+const (
+	CodeBadRequest           = "bad_request"
+	CodeInvalidRequestFormat = "invalid_request_format"
+	CodeValidationFailed     = "validation_failed"
+	CodeUnauthorized         = "unauthorized"
+	CodeForbidden            = "forbidden"
+	CodeNotFound             = "not_found"
+	CodeMethodNotAllowed     = "method_not_allowed"
+	CodeConflict             = "conflict"
+	CodeEmailTaken           = "email_taken"
+	CodeRequestTooLarge      = "request_too_large"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodeTooManyRequests      = "too_many_requests"
+	CodeInvalidVariant       = "invalid_variant"
+	CodeInvalidPercentage    = "invalid_percentage"
+	CodeQueryTooComplex      = "query_too_complex"
+	CodeAlreadyExists        = "already_exists"
+	CodeReferenceViolation   = "reference_violation"
+	CodeConstraintViolation  = "constraint_violation"
+	CodeConcurrentUpdate     = "concurrent_update"
+	CodeDatabaseTimeout      = "database_timeout"
+	CodeInternal             = "internal_server_error"
+	CodeServiceUnavailable   = "service_unavailable"
+)
+
+// TypeBaseURI prefixes the code in the `type` of the problems, GET /errors/{code} describes it
+var TypeBaseURI = "/errors/"
+
+// CatalogEntry describes an error code
+type CatalogEntry struct {
+	Code   string `json:"code"`
+	Status int    `json:"status"`
+	Type   string `json:"type"`
+	// Short, human readable summary by language, that does not change between occurrences, from the message bundles
+	Title map[string]string `json:"title"`
+	// The same request may succeed when retried
+	Retryable bool `json:"retryable,omitempty"`
+}
+
+// LocalizedTitle returns the title in lang, english if there is none
+func (e CatalogEntry) LocalizedTitle(lang string) string {
+	if title, ok := e.Title[lang]; ok {
+		return title
+	}
+	return e.Title[DefaultLanguage]
+}
+
+// DefaultLanguage of the messages, used when the client accepts none of the others
+const DefaultLanguage = "en"
+
+var catalog = map[string]CatalogEntry{}
+
+// retryable marks the registered code as retryable
+func retryable(entry *CatalogEntry) {
+	entry.Retryable = true
+}
+
+// register adds a code to the catalog, its titles are in the message bundles (locales/*.yaml)
+func register(code string, status int, options ...func(*CatalogEntry)) {
+	entry := CatalogEntry{
+		Code:   code,
+		Status: status,
+		Type:   TypeBaseURI + code,
+		Title:  map[string]string{},
+	}
+	for _, option := range options {
+		option(&entry)
+	}
+	catalog[code] = entry
+}
+
+func init() {
+	register(CodeBadRequest, http.StatusBadRequest)
+	register(CodeInvalidRequestFormat, http.StatusBadRequest)
+	register(CodeValidationFailed, http.StatusBadRequest)
+	register(CodeUnauthorized, http.StatusUnauthorized)
+	register(CodeForbidden, http.StatusForbidden)
+	register(CodeNotFound, http.StatusNotFound)
+	register(CodeMethodNotAllowed, http.StatusMethodNotAllowed)
+	register(CodeConflict, http.StatusConflict)
+	register(CodeEmailTaken, http.StatusConflict)
+	register(CodeRequestTooLarge, http.StatusRequestEntityTooLarge)
+	register(CodeUnsupportedMediaType, http.StatusUnsupportedMediaType)
+	register(CodeTooManyRequests, http.StatusTooManyRequests)
+	register(CodeInvalidVariant, http.StatusBadRequest)
+	register(CodeInvalidPercentage, http.StatusBadRequest)
+	register(CodeQueryTooComplex, http.StatusBadRequest)
+	register(CodeAlreadyExists, http.StatusConflict)
+	register(CodeReferenceViolation, http.StatusUnprocessableEntity)
+	register(CodeConstraintViolation, http.StatusBadRequest)
+	register(CodeConcurrentUpdate, http.StatusServiceUnavailable, retryable)
+	register(CodeDatabaseTimeout, http.StatusServiceUnavailable, retryable)
+	register(CodeInternal, http.StatusInternalServerError)
+	register(CodeServiceUnavailable, http.StatusServiceUnavailable)
+
+	if err := loadBundles(); err != nil {
+		panic(err)
+	}
+}
+
+// Lookup returns the catalog entry of a code
+func Lookup(code string) (CatalogEntry, bool) {
+	entry, ok := catalog[code]
+	return entry, ok
+}
+
+// Catalog lists every code, by code
+func Catalog() []CatalogEntry {
+	entries := make([]CatalogEntry, 0, len(catalog))
+	for _, entry := range catalog {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(a, b int) bool {
+		return entries[a].Code < entries[b].Code
+	})
+	return entries
+}
+
+// codeForStatus is the generic code of a status, for the errors that don't carry a code (e.g. echo.HTTPError)
+func codeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusRequestEntityTooLarge:
+		return CodeRequestTooLarge
+	case http.StatusUnsupportedMediaType:
+		return CodeUnsupportedMediaType
+	case http.StatusTooManyRequests:
+		return CodeTooManyRequests
+	case http.StatusServiceUnavailable:
+		return CodeServiceUnavailable
+	}
+
+	if status >= http.StatusInternalServerError {
+		return CodeInternal
+	}
+	return ""
+}
@@ -2,6 +2,7 @@ package errz
 
 import (
 	"fmt"
+	"net/http"
 
 	"github.com/cockroachdb/errors"
 	"github.com/cockroachdb/errors/withstack"
@@ -12,9 +13,14 @@ type PrettyError struct {
 	Code           string
 	Message        string
 	Details        map[string]string // Optional additional details
+	InvalidParams  []InvalidParam    // Optional, the request parameters that failed validation
 	cause          error
+}
 
-	// TODO: add error details
+// InvalidParam is a request parameter that failed validation, rendered in the invalid-params of the problem
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
 }
 
 func (e PrettyError) Error() string {
@@ -37,6 +43,43 @@ func (e PrettyError) Format(s fmt.State, verb rune) {
 	errors.FormatError(e, s, verb)
 }
 
+// New creates an error with a code of the catalog, and its status
+//
+//	return errz.New(errz.CodeNotFound, "task not found", err)
+//
+// Internal errors caused by a constraint, a concurrent update or a timeout of the database
+// get the code of the database error instead (see FromDatabase).
+func New(code, message string, cause error) PrettyError {
+	if prettyError, ok := fromInternal(code, message, cause); ok {
+		return prettyError
+	}
+
+	status := http.StatusInternalServerError
+	if entry, ok := Lookup(code); ok {
+		status = entry.Status
+	}
+
+	return PrettyError{
+		HttpStatusCode: status,
+		Code:           code,
+		Message:        message,
+		cause:          withstack.WithStackDepth(cause, 1),
+	}
+}
+
+// WithInvalidParams returns a copy of the error with the parameters that failed validation
+func (e PrettyError) WithInvalidParams(params []InvalidParam) PrettyError {
+	e.InvalidParams = params
+	return e
+}
+
+// WithDetails returns a copy of the error with additional details
+func (e PrettyError) WithDetails(details map[string]string) PrettyError {
+	e.Details = details
+	return e
+}
+
+// NewPrettyError creates an error with an explicit status, prefer New with a code of the catalog
 func NewPrettyError(httpStatusCode int, code, message string, cause error) PrettyError {
 
 	return PrettyError{
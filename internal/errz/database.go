@@ -0,0 +1,159 @@
+package errz
+
+import (
+	"regexp"
+	"sync"
+
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/errors/withstack"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// database error classes, by postgres SQLSTATE and mysql error number
+const (
+	dbUniqueViolation      = "unique_violation"
+	dbForeignKeyViolation  = "foreign_key_violation"
+	dbCheckViolation       = "check_violation"
+	dbNotNullViolation     = "not_null_violation"
+	dbSerializationFailure = "serialization_failure"
+	dbDeadlock             = "deadlock"
+	dbTimeout              = "timeout"
+)
+
+var postgresClasses = map[string]string{
+	"23505": dbUniqueViolation,
+	"23503": dbForeignKeyViolation,
+	"23514": dbCheckViolation,
+	"23502": dbNotNullViolation,
+	"40001": dbSerializationFailure,
+	"40P01": dbDeadlock,
+	"57014": dbTimeout, // query_canceled, statement_timeout
+	"55P03": dbTimeout, // lock_not_available, lock_timeout
+}
+
+var mysqlClasses = map[uint16]string{
+	1062: dbUniqueViolation, // ER_DUP_ENTRY
+	1586: dbUniqueViolation, // ER_DUP_ENTRY_WITH_KEY_NAME
+	1216: dbForeignKeyViolation,
+	1217: dbForeignKeyViolation,
+	1451: dbForeignKeyViolation, // ER_ROW_IS_REFERENCED_2
+	1452: dbForeignKeyViolation, // ER_NO_REFERENCED_ROW_2
+	3819: dbCheckViolation,
+	1048: dbNotNullViolation, // ER_BAD_NULL_ERROR
+	1213: dbDeadlock,
+	1205: dbTimeout, // ER_LOCK_WAIT_TIMEOUT
+	3024: dbTimeout, // ER_QUERY_TIMEOUT, max_execution_time
+}
+
+// the code and message of each class, the message does not leak the query nor the values
+var databaseErrors = map[string]struct{ code, message string }{
+	dbUniqueViolation:      {CodeAlreadyExists, "a record with the same value already exists"},
+	dbForeignKeyViolation:  {CodeReferenceViolation, "the record references a missing record, or is referenced by another one"},
+	dbCheckViolation:       {CodeConstraintViolation, "a value is not allowed"},
+	dbNotNullViolation:     {CodeConstraintViolation, "a required value is missing"},
+	dbSerializationFailure: {CodeConcurrentUpdate, "the record was changed concurrently, retry the request"},
+	dbDeadlock:             {CodeConcurrentUpdate, "the record was changed concurrently, retry the request"},
+	dbTimeout:              {CodeDatabaseTimeout, "the database did not answer in time, retry the request"},
+}
+
+var (
+	constraintsMu sync.RWMutex
+	constraints   = map[string]struct{ code, message string }{}
+)
+
+// RegisterConstraint renders the violations of a constraint (unique index, foreign key, check)
+// with a more specific code of the catalog, e.g.
+//
+//	errz.RegisterConstraint("idx_users_email", errz.CodeEmailTaken, "email is already registered")
+func RegisterConstraint(name, code, message string) {
+	constraintsMu.Lock()
+	defer constraintsMu.Unlock()
+
+	constraints[name] = struct{ code, message string }{code, message}
+}
+
+// FromDatabase translates the postgres and mysql errors of constraints, concurrency and timeouts
+// (e.g. a unique violation becomes a 409 already_exists), false for any other error.
+// New applies it to the internal errors and the error renderer to the bare ones, so services rarely call it.
+func FromDatabase(err error) (PrettyError, bool) {
+	if err == nil {
+		return PrettyError{}, false
+	}
+
+	class, constraint := databaseClass(err)
+	translation, ok := databaseErrors[class]
+	if !ok {
+		return PrettyError{}, false
+	}
+
+	var details map[string]string
+	if constraint != "" {
+		constraintsMu.RLock()
+		registered, found := constraints[constraint]
+		constraintsMu.RUnlock()
+
+		if found {
+			translation = registered
+		} else {
+			details = map[string]string{"constraint": constraint}
+		}
+	}
+
+	prettyError := New(translation.code, translation.message, nil)
+	prettyError.Details = details
+	prettyError.cause = err
+	return prettyError, true
+}
+
+// mysql only has the constraint in the message
+var (
+	mysqlKeyName        = regexp.MustCompile("for key '(?:[^'.]+\\.)?([^']+)'")
+	mysqlConstraintName = regexp.MustCompile("CONSTRAINT `([^`]+)`")
+)
+
+func databaseClass(err error) (class, constraint string) {
+	var pgError *pgconn.PgError
+	if errors.As(err, &pgError) {
+		return postgresClasses[pgError.Code], pgError.ConstraintName
+	}
+
+	var mysqlError *mysql.MySQLError
+	if errors.As(err, &mysqlError) {
+		if match := mysqlKeyName.FindStringSubmatch(mysqlError.Message); match != nil {
+			constraint = match[1]
+		} else if match := mysqlConstraintName.FindStringSubmatch(mysqlError.Message); match != nil {
+			constraint = match[1]
+		}
+		return mysqlClasses[mysqlError.Number], constraint
+	}
+
+	// the dialects translate them when gorm.Config.TranslateError is set
+	switch {
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return dbUniqueViolation, ""
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return dbForeignKeyViolation, ""
+	case errors.Is(err, gorm.ErrCheckConstraintViolated):
+		return dbCheckViolation, ""
+	}
+
+	return "", ""
+}
+
+// fromInternal translates the database errors wrapped as internal errors by the services,
+// keeping the message of the service in the cause
+func fromInternal(code, message string, cause error) (PrettyError, bool) {
+	if code != CodeInternal {
+		return PrettyError{}, false
+	}
+
+	prettyError, ok := FromDatabase(cause)
+	if !ok {
+		return PrettyError{}, false
+	}
+
+	prettyError.cause = withstack.WithStackDepth(errors.WithMessage(cause, message), 2)
+	return prettyError, true
+}
@@ -0,0 +1,188 @@
+package errz
+
+import (
+	"embed"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// the message bundles, one <language>.yaml per language, see locales/en.yaml
+//
+//go:embed locales/*.yaml
+var localeFiles embed.FS
+
+// Message is the title and detail of an error code in a language
+type Message struct {
+	Title string `yaml:"title"`
+	// Optional, replaces the english message of the error; {name} is replaced by the detail of the error with that name
+	Detail string `yaml:"detail"`
+}
+
+// bundles by language, then by code
+var bundles = map[string]map[string]Message{}
+
+// languageAliases are the deprecated tags still sent by some clients
+var languageAliases = map[string]string{
+	"in": "id",
+}
+
+// loadBundles reads the embedded bundles and sets the titles of the catalog
+func loadBundles() error {
+	files, err := fs.Glob(localeFiles, "locales/*.yaml")
+	if err != nil {
+		return errors.Wrap(err, "failed to list the message bundles")
+	}
+
+	var errs []error
+	for _, file := range files {
+		data, err := localeFiles.ReadFile(file)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %s", file)
+		}
+
+		bundle := map[string]Message{}
+		if err := yaml.Unmarshal(data, &bundle); err != nil {
+			errs = append(errs, errors.Wrapf(err, "invalid message bundle %s", file))
+			continue
+		}
+
+		language := strings.TrimSuffix(path.Base(file), ".yaml")
+		for code, message := range bundle {
+			if _, ok := catalog[code]; !ok {
+				errs = append(errs, errors.Newf("%s: %s is not in the catalog", file, code))
+				continue
+			}
+			if message.Title != "" {
+				catalog[code].Title[language] = message.Title
+			}
+		}
+		bundles[language] = bundle
+	}
+
+	// english is the fallback, it must be complete
+	for code, entry := range catalog {
+		if entry.Title[DefaultLanguage] == "" {
+			errs = append(errs, errors.Newf("locales/%s.yaml: %s has no title", DefaultLanguage, code))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Languages lists the languages with a message bundle
+func Languages() []string {
+	languages := make([]string, 0, len(bundles))
+	for language := range bundles {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Translate returns the message of a code in a language, false when the language has none
+func Translate(code, language string) (Message, bool) {
+	message, ok := bundles[language][code]
+	return message, ok
+}
+
+var detailParam = regexp.MustCompile(`\{(\w+)\}`)
+
+// localizedDetail is the detail of the code in a language, with the details of the error in its {params}.
+// False when there is no detail, or a param is missing, the english message is used instead.
+func localizedDetail(code, language string, details map[string]string) (string, bool) {
+	message, ok := Translate(code, language)
+	if !ok || message.Detail == "" {
+		return "", false
+	}
+
+	complete := true
+	detail := detailParam.ReplaceAllStringFunc(message.Detail, func(param string) string {
+		value, ok := details[param[1:len(param)-1]]
+		if !ok {
+			complete = false
+		}
+		return value
+	})
+
+	return detail, complete
+}
+
+// NegotiateLanguage picks the language of the response from an Accept-Language header,
+// e.g. "fr-CH, id;q=0.9, en;q=0.8" gives id when there is no french bundle.
+// Languages are tried by quality then by order, q=0 excludes a language, DefaultLanguage when nothing matches.
+func NegotiateLanguage(acceptLanguage string) string {
+	type candidate struct {
+		language string
+		quality  float64
+	}
+
+	var candidates []candidate
+	excluded := map[string]bool{}
+
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range strings.Split(params, ";") {
+			name, value, found := strings.Cut(strings.TrimSpace(param), "=")
+			if !found || strings.ToLower(name) != "q" {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil || parsed < 0 || parsed > 1 {
+				quality = -1
+				break
+			}
+			quality = parsed
+		}
+		if quality < 0 {
+			continue
+		}
+
+		// en-US -> en, only the primary language is matched
+		language, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if alias, ok := languageAliases[language]; ok {
+			language = alias
+		}
+
+		if quality == 0 {
+			excluded[language] = true
+			continue
+		}
+		candidates = append(candidates, candidate{language, quality})
+	}
+
+	sort.SliceStable(candidates, func(a, b int) bool {
+		return candidates[a].quality > candidates[b].quality
+	})
+
+	for _, candidate := range candidates {
+		if excluded[candidate.language] {
+			continue
+		}
+		if candidate.language == "*" {
+			// any language, the default one unless it is excluded
+			for _, language := range append([]string{DefaultLanguage}, Languages()...) {
+				if !excluded[language] {
+					return language
+				}
+			}
+			continue
+		}
+		if _, ok := bundles[candidate.language]; ok {
+			return candidate.language
+		}
+	}
+
+	return DefaultLanguage
+}
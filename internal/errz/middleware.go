@@ -1,13 +1,36 @@
 package errz
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/cockroachdb/errors"
+	"github.com/go-playground/validator/v10"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/labstack/echo/v4"
 )
 
+// ProblemContentType is the media type of the error responses
+const ProblemContentType = "application/problem+json"
+
+// Problem is the body of the error responses, RFC 9457 problem details.
+// code, retryable, trace_id, invalid-params and details are extension members.
+type Problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	// The request id, to find the logs of the request
+	Instance string `json:"instance,omitempty"`
+
+	Code          string            `json:"code"`
+	Retryable     bool              `json:"retryable,omitempty"`
+	TraceID       string            `json:"trace_id,omitempty"`
+	InvalidParams []InvalidParam    `json:"invalid-params,omitempty"`
+	Details       map[string]string `json:"details,omitempty"`
+}
+
 func ErrorRendererMiddleware() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
@@ -17,51 +40,137 @@ func ErrorRendererMiddleware() echo.MiddlewareFunc {
 				return nil
 			}
 
-			// handle custom PrettyError
-			var prettyError PrettyError
-			if errors.As(err, &prettyError) {
-				errorResponse := map[string]any{
-					"code":    prettyError.Code,
-					"message": prettyError.Message,
-				}
-
-				// Include details if they exist
-				if len(prettyError.Details) > 0 {
-					errorResponse["details"] = prettyError.Details
-				}
-
-				return c.JSON(prettyError.HttpStatusCode, map[string]any{
-					"meta": map[string]any{
-						"http_status_code": prettyError.HttpStatusCode,
-					},
-					"error": errorResponse,
-				})
-			}
+			return Render(c, err)
+		}
+	}
+}
 
-			// handle Echo's HTTPError (like 404, 405, etc.)
-			var httpError *echo.HTTPError
-			if errors.As(err, &httpError) {
-				return c.JSON(httpError.Code, map[string]any{
-					"meta": map[string]any{
-						"http_status_code": httpError.Code,
-					},
-					"error": map[string]any{
-						"code":    http.StatusText(httpError.Code),
-						"message": httpError.Message,
-					},
-				})
-			}
+// HTTPErrorHandler renders the errors that did not go through ErrorRendererMiddleware
+// (e.g. the ones of the middlewares running before it), set it as echo.HTTPErrorHandler
+func HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	if renderErr := Render(c, err); renderErr != nil {
+		c.Logger().Error(renderErr)
+	}
+}
+
+// Render writes err as a problem+json response
+func Render(c echo.Context, err error) error {
+	recordServerError(c, err)
+
+	language := NegotiateLanguage(c.Request().Header.Get("Accept-Language"))
+	problem := NewProblem(err, language)
+
+	// request_id and trace_id let support jump from a customer report straight to the logs and the trace
+	problem.Instance = c.Response().Header().Get(echo.HeaderXRequestID)
+	if spanContext := trace.SpanContextFromContext(c.Request().Context()); spanContext.HasTraceID() {
+		problem.TraceID = spanContext.TraceID().String()
+	}
+
+	if problem.Retryable {
+		c.Response().Header().Set(echo.HeaderRetryAfter, "1")
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, ProblemContentType)
+	c.Response().Header().Set("Content-Language", language)
+	return c.JSON(problem.Status, problem)
+}
+
+// NewProblem describes err, with the title and detail in lang (english when the bundle of lang has none).
+// Only PrettyError messages and echo.HTTPError messages are shown, other errors are internal errors without detail.
+func NewProblem(err error, lang string) Problem {
+	var (
+		code, detail  string
+		status        = http.StatusInternalServerError
+		details       map[string]string
+		invalidParams []InvalidParam
+	)
 
-			// fallback for any other errors
-			return c.JSON(http.StatusInternalServerError, map[string]any{
-				"meta": map[string]any{
-					"http_status_code": http.StatusInternalServerError,
-				},
-				"error": map[string]any{
-					"code":    "ttts",
-					"message": "Tetap Tenang, Tetap Semangat",
-				},
+	var prettyError PrettyError
+	var httpError *echo.HTTPError
+	var validationErrors validator.ValidationErrors
+
+	switch {
+	case errors.As(err, &prettyError):
+		code, status, detail = prettyError.Code, prettyError.HttpStatusCode, prettyError.Message
+		details, invalidParams = prettyError.Details, prettyError.InvalidParams
+
+	case errors.As(err, &httpError):
+		status = httpError.Code
+		code = codeForStatus(status)
+		if message := fmt.Sprint(httpError.Message); message != http.StatusText(status) {
+			detail = message
+		}
+
+	case errors.As(err, &validationErrors):
+		status, code = http.StatusBadRequest, CodeValidationFailed
+		for _, fieldError := range validationErrors {
+			invalidParams = append(invalidParams, InvalidParam{
+				Name:   fieldError.Field(),
+				Reason: "failed on the '" + fieldError.Tag() + "' rule",
 			})
 		}
+
+	default:
+		// bare database errors, e.g. returned without errz.New
+		if prettyError, ok := FromDatabase(err); ok {
+			code, status, detail, details = prettyError.Code, prettyError.HttpStatusCode, prettyError.Message, prettyError.Details
+			break
+		}
+		code = CodeInternal
+	}
+
+	problem := Problem{
+		Type:          "about:blank",
+		Title:         http.StatusText(status),
+		Status:        status,
+		Detail:        detail,
+		Code:          code,
+		InvalidParams: invalidParams,
+		Details:       details,
+	}
+
+	// codes outside of the catalog are plain http errors
+	if entry, ok := Lookup(code); ok {
+		problem.Type = entry.Type
+		problem.Title = entry.LocalizedTitle(lang)
+		problem.Retryable = entry.Retryable
+	}
+
+	// the messages of the errors are in english
+	if lang != DefaultLanguage {
+		if localized, ok := localizedDetail(code, lang, details); ok {
+			problem.Detail = localized
+		}
+	}
+
+	return problem
+}
+
+// StatusCode is the status err is rendered with
+func StatusCode(err error) int {
+	var prettyError PrettyError
+	var httpError *echo.HTTPError
+	if errors.As(err, &prettyError) {
+		return prettyError.HttpStatusCode
+	} else if errors.As(err, &httpError) {
+		return httpError.Code
+	} else if prettyError, ok := FromDatabase(err); ok {
+		return prettyError.HttpStatusCode
 	}
+
+	return http.StatusInternalServerError
+}
+
+// recordServerError records 5xx errors on the request span,
+// they are swallowed here so the telemetry middleware never sees them
+func recordServerError(c echo.Context, err error) {
+	if StatusCode(err) < http.StatusInternalServerError {
+		return
+	}
+
+	trace.SpanFromContext(c.Request().Context()).RecordError(err)
 }
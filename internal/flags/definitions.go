@@ -0,0 +1,18 @@
+package flags
+
+// The flags of the service, add new ones here.
+// Remove a flag once its feature is fully rolled out, along with its override (DELETE /admin/flags/:key).
+var (
+	TaskNotifications = NewBool(
+		"task_notifications",
+		"Start the Temporal notification workflow when a task is created or updated",
+		true,
+	)
+
+	TaskListOrder = NewVariant(
+		"task_list_order",
+		"Order of the tasks listed by GET /secured/tasks",
+		[]string{"default", "newest_first"},
+		"default",
+	)
+)
@@ -0,0 +1,280 @@
+package flags
+
+import (
+	"context"
+	"golang-service-template/internal/errz"
+	"golang-service-template/internal/settings"
+	"golang-service-template/internal/telemetry"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/rs/zerolog"
+)
+
+const defaultRefreshInterval = 5 * time.Second
+
+// Options of New, only Store is required
+type Options struct {
+	Store Store
+	// Config defaults by flag key, checked against the definitions
+	Defaults map[string]string
+	// Optional, its boolean flags override the config defaults
+	RuntimeSettings *settings.RuntimeSettings
+	// Optional, records the evaluations
+	Telemetry *telemetry.Telemetry
+	// How long the overrides are cached, 5s if not set
+	RefreshInterval time.Duration
+	Logger          zerolog.Logger
+}
+
+// Flags evaluates the flags, see the package doc for the layers
+type Flags struct {
+	store           Store
+	defaults        map[string]string
+	runtimeSettings *settings.RuntimeSettings
+	telemetry       *telemetry.Telemetry
+	refreshInterval time.Duration
+	logger          zerolog.Logger
+
+	cache     atomic.Pointer[cachedOverrides]
+	refreshMu sync.Mutex
+}
+
+type cachedOverrides struct {
+	overrides map[string]Override
+	loadedAt  time.Time
+}
+
+// State is a flag as shown by the admin API
+type State struct {
+	Definition
+	ConfigDefault string    `json:"config_default,omitempty"`
+	Override      *Override `json:"override,omitempty"`
+}
+
+func New(options Options) (*Flags, error) {
+	for key, variant := range options.Defaults {
+		definition, ok := Lookup(key)
+		if !ok {
+			return nil, errors.Newf("unknown feature flag %s", key)
+		}
+		if !slices.Contains(definition.Variants, variant) {
+			return nil, errors.Newf("feature flag %s: %q is not one of %v", key, variant, definition.Variants)
+		}
+	}
+
+	refreshInterval := options.RefreshInterval
+	if refreshInterval <= 0 {
+		refreshInterval = defaultRefreshInterval
+	}
+
+	return &Flags{
+		store:           options.Store,
+		defaults:        options.Defaults,
+		runtimeSettings: options.RuntimeSettings,
+		telemetry:       options.Telemetry,
+		refreshInterval: refreshInterval,
+		logger:          options.Logger,
+	}, nil
+}
+
+// Enabled evaluates a boolean flag for the target stored in ctx
+//
+//	if s.flags.Enabled(ctx, flags.TaskNotifications) { ... }
+func (f *Flags) Enabled(ctx context.Context, flag BoolFlag) bool {
+	return f.evaluate(ctx, flag.Definition, TargetFromContext(ctx)).Enabled()
+}
+
+// Variant evaluates a multivariate flag for the target stored in ctx
+//
+//	switch s.flags.Variant(ctx, flags.TaskListOrder) { ... }
+func (f *Flags) Variant(ctx context.Context, flag VariantFlag) string {
+	return f.evaluate(ctx, flag.Definition, TargetFromContext(ctx)).Variant
+}
+
+// Evaluate evaluates a flag by key for any target, e.g. to check a rollout from the admin API
+func (f *Flags) Evaluate(ctx context.Context, key string, target Target) (Evaluation, error) {
+	definition, ok := Lookup(key)
+	if !ok {
+		return Evaluation{}, flagNotFound(key)
+	}
+
+	return f.evaluate(ctx, definition, target), nil
+}
+
+func (f *Flags) evaluate(ctx context.Context, definition Definition, target Target) Evaluation {
+	evaluation := Evaluation{Key: definition.Key, Variant: definition.Default, Reason: ReasonDefault}
+
+	if variant, ok := f.defaults[definition.Key]; ok {
+		evaluation.Variant, evaluation.Reason = variant, ReasonConfig
+	}
+
+	if definition.Boolean && f.runtimeSettings != nil {
+		if enabled, ok := f.runtimeSettings.Get().Flags[definition.Key]; ok {
+			evaluation.Variant, evaluation.Reason = strconv.FormatBool(enabled), ReasonSettings
+		}
+	}
+
+	if override, ok := f.overrides(ctx)[definition.Key]; ok {
+		evaluation = applyOverride(evaluation, definition, override, target)
+	}
+
+	f.telemetry.RecordFeatureFlag(ctx, evaluation.Key, evaluation.Variant, evaluation.Reason)
+
+	return evaluation
+}
+
+func applyOverride(evaluation Evaluation, definition Definition, override Override, target Target) Evaluation {
+	for index, rule := range override.Rules {
+		// variants are checked when the override is set, but the definition may have changed since
+		if !slices.Contains(definition.Variants, rule.Variant) || !rule.matches(definition.Key, target) {
+			continue
+		}
+
+		return Evaluation{Key: definition.Key, Variant: rule.Variant, Reason: ReasonRule, Rule: &index}
+	}
+
+	if override.Variant != "" && slices.Contains(definition.Variants, override.Variant) {
+		evaluation.Variant, evaluation.Reason = override.Variant, ReasonOverride
+	}
+
+	return evaluation
+}
+
+// overrides returns the cached overrides, refreshed every refreshInterval.
+// Only one caller refreshes at a time, the others keep using the previous overrides meanwhile.
+// When the store fails, the previous overrides are kept until the next refresh.
+func (f *Flags) overrides(ctx context.Context) map[string]Override {
+	cached := f.cache.Load()
+	if cached != nil && time.Since(cached.loadedAt) < f.refreshInterval {
+		return cached.overrides
+	}
+
+	if cached == nil {
+		f.refreshMu.Lock()
+	} else if !f.refreshMu.TryLock() {
+		return cached.overrides
+	}
+	defer f.refreshMu.Unlock()
+
+	// refreshed while waiting for the lock
+	if latest := f.cache.Load(); latest != nil && latest != cached {
+		return latest.overrides
+	}
+
+	overrides, err := f.store.List(ctx)
+	if err != nil {
+		f.logger.Warn().Err(err).Msg("failed to load feature flag overrides")
+	}
+	if overrides == nil {
+		overrides = map[string]Override{}
+		if cached != nil {
+			overrides = cached.overrides
+		}
+	}
+
+	f.cache.Store(&cachedOverrides{overrides: overrides, loadedAt: time.Now()})
+
+	return overrides
+}
+
+// States lists the flags with their config default and override, read from the store
+func (f *Flags) States(ctx context.Context) ([]State, error) {
+	overrides, err := f.store.List(ctx)
+	if overrides == nil {
+		return nil, errz.New(errz.CodeInternal, "failed to list feature flag overrides", err)
+	}
+	if err != nil {
+		f.logger.Warn().Err(err).Msg("failed to decode feature flag overrides")
+	}
+
+	states := []State{}
+	for _, definition := range Definitions() {
+		state := State{Definition: definition, ConfigDefault: f.defaults[definition.Key]}
+		if override, ok := overrides[definition.Key]; ok {
+			state.Override = &override
+		}
+		states = append(states, state)
+	}
+
+	return states, nil
+}
+
+// State returns a flag with its config default and override
+func (f *Flags) State(ctx context.Context, key string) (State, error) {
+	if _, ok := Lookup(key); !ok {
+		return State{}, flagNotFound(key)
+	}
+
+	states, err := f.States(ctx)
+	if err != nil {
+		return State{}, err
+	}
+
+	index := slices.IndexFunc(states, func(state State) bool {
+		return state.Key == key
+	})
+
+	return states[index], nil
+}
+
+// SetOverride replaces the override of a flag, applied by every instance within the refresh interval
+func (f *Flags) SetOverride(ctx context.Context, key string, override Override) (State, error) {
+	definition, ok := Lookup(key)
+	if !ok {
+		return State{}, flagNotFound(key)
+	}
+
+	variants := []string{}
+	if override.Variant != "" {
+		variants = append(variants, override.Variant)
+	}
+	for _, rule := range override.Rules {
+		if rule.Percentage != nil && (*rule.Percentage < 0 || *rule.Percentage > 100) {
+			return State{}, errz.New(errz.CodeInvalidPercentage, "percentage must be between 0 and 100", nil)
+		}
+		variants = append(variants, rule.Variant)
+	}
+	for _, variant := range variants {
+		if !slices.Contains(definition.Variants, variant) {
+			return State{}, errz.New(errz.CodeInvalidVariant, "unknown variant "+variant, nil).WithDetails(map[string]string{
+				"variants": strings.Join(definition.Variants, ","),
+			})
+		}
+	}
+
+	override.UpdatedAt = time.Now().UTC()
+
+	if err := f.store.Set(ctx, key, override); err != nil {
+		return State{}, errz.New(errz.CodeInternal, "failed to set feature flag override", err)
+	}
+
+	// this instance applies it right away
+	f.cache.Store(nil)
+
+	return f.State(ctx, key)
+}
+
+// DeleteOverride removes the override of a flag, the config default applies again
+func (f *Flags) DeleteOverride(ctx context.Context, key string) error {
+	if _, ok := Lookup(key); !ok {
+		return flagNotFound(key)
+	}
+
+	if err := f.store.Delete(ctx, key); err != nil {
+		return errz.New(errz.CodeInternal, "failed to delete feature flag override", err)
+	}
+
+	f.cache.Store(nil)
+
+	return nil
+}
+
+func flagNotFound(key string) error {
+	return errz.New(errz.CodeNotFound, "unknown feature flag "+key, nil)
+}
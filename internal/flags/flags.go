@@ -0,0 +1,205 @@
+// Package flags evaluates the feature flags, to roll out features gradually.
+//
+// Flags are defined in code (see definitions.go), with their variants and default.
+// The variant served is, each layer overriding the previous one:
+//
+//  1. the default of the definition
+//  2. the config default, FEATURE_FLAGS=task_notifications=false,task_list_order=newest_first
+//  3. the runtime settings flags, boolean flags only, e.g. a kill switch
+//  4. the override stored in redis, managed by the /admin/flags API:
+//     the variant of the first rule matching the target (user ids, tenants, percentage of the users),
+//     else the variant of the override
+//
+// Percentages use a consistent hash of the flag key and the user id (the JWT subject),
+// so a user keeps the same variant, and raising the percentage only adds users.
+package flags
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// Variants of the boolean flags
+	On  = "true"
+	Off = "false"
+
+	ReasonDefault  = "default"  // default of the definition
+	ReasonConfig   = "config"   // FEATURE_FLAGS
+	ReasonSettings = "settings" // runtime settings flags
+	ReasonOverride = "override" // variant of the override, no rule matched
+	ReasonRule     = "rule"     // variant of a matching rule of the override
+)
+
+// Definition is a flag defined in code
+type Definition struct {
+	Key         string   `json:"key"`
+	Description string   `json:"description"`
+	Variants    []string `json:"variants"`
+	Default     string   `json:"default"`
+	Boolean     bool     `json:"boolean"`
+}
+
+// BoolFlag is a flag with the On and Off variants, read with Flags.Enabled
+type BoolFlag struct {
+	Definition
+}
+
+// VariantFlag is a multivariate flag, read with Flags.Variant
+type VariantFlag struct {
+	Definition
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Definition{}
+)
+
+// NewBool defines a boolean flag, panics if the key is already defined
+func NewBool(key, description string, def bool) BoolFlag {
+	return BoolFlag{register(Definition{
+		Key:         key,
+		Description: description,
+		Variants:    []string{On, Off},
+		Default:     strconv.FormatBool(def),
+		Boolean:     true,
+	})}
+}
+
+// NewVariant defines a multivariate flag, panics if the key is already defined or def is not one of the variants
+func NewVariant(key, description string, variants []string, def string) VariantFlag {
+	if !slices.Contains(variants, def) {
+		panic(fmt.Sprintf("flags: default %q of %s is not one of its variants %v", def, key, variants))
+	}
+
+	return VariantFlag{register(Definition{
+		Key:         key,
+		Description: description,
+		Variants:    variants,
+		Default:     def,
+	})}
+}
+
+func register(definition Definition) Definition {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, ok := registry[definition.Key]; ok {
+		panic("flags: " + definition.Key + " is already defined")
+	}
+	registry[definition.Key] = definition
+
+	return definition
+}
+
+// Lookup returns the definition of a flag
+func Lookup(key string) (Definition, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	definition, ok := registry[key]
+	return definition, ok
+}
+
+// Definitions lists every flag defined in code, by key
+func Definitions() []Definition {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	definitions := make([]Definition, 0, len(registry))
+	for _, definition := range registry {
+		definitions = append(definitions, definition)
+	}
+	sort.Slice(definitions, func(a, b int) bool {
+		return definitions[a].Key < definitions[b].Key
+	})
+
+	return definitions
+}
+
+// Target is who a flag is evaluated for
+type Target struct {
+	UserID string `json:"user_id,omitempty"`
+	Tenant string `json:"tenant,omitempty"`
+}
+
+type targetContextKey struct{}
+
+// WithTarget stores the target in ctx, read by Flags.Enabled and Flags.Variant.
+// Set for the authenticated requests by middleware.ValidateJWTMiddleware.
+func WithTarget(ctx context.Context, target Target) context.Context {
+	return context.WithValue(ctx, targetContextKey{}, target)
+}
+
+// TargetFromContext returns the target stored in ctx, an anonymous target if none
+func TargetFromContext(ctx context.Context) Target {
+	target, _ := ctx.Value(targetContextKey{}).(Target)
+	return target
+}
+
+// Override is the targeting of a flag, stored in redis
+type Override struct {
+	// Served when no rule matches, the variant of the previous layers if empty
+	Variant string `json:"variant,omitempty"`
+	// The first matching rule wins
+	Rules     []Rule    `json:"rules,omitempty" validate:"dive"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UpdatedBy string    `json:"updated_by,omitempty"`
+}
+
+// Rule matches a target when every condition it sets matches
+type Rule struct {
+	Variant string   `json:"variant" validate:"required"`
+	UserIDs []string `json:"user_ids,omitempty"`
+	Tenants []string `json:"tenants,omitempty"`
+	// Share of the users, by a consistent hash of the flag key and the user id.
+	// Targets without a user id never match a percentage.
+	Percentage *int `json:"percentage,omitempty" validate:"omitempty,min=0,max=100"`
+}
+
+func (rule Rule) matches(key string, target Target) bool {
+	if len(rule.UserIDs) > 0 && !slices.Contains(rule.UserIDs, target.UserID) {
+		return false
+	}
+
+	if len(rule.Tenants) > 0 && !slices.Contains(rule.Tenants, target.Tenant) {
+		return false
+	}
+
+	if rule.Percentage != nil {
+		if target.UserID == "" {
+			return false
+		}
+		return Bucket(key, target.UserID) < *rule.Percentage
+	}
+
+	return true
+}
+
+// Bucket is the consistent bucket of a user for a flag, from 0 to 99.
+// The flag key is part of the hash, so the same users are not always the first ones in.
+func Bucket(key, userID string) int {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(key + "/" + userID))
+	return int(hash.Sum32() % 100)
+}
+
+// Evaluation is the variant served for a target, and why
+type Evaluation struct {
+	Key     string `json:"key"`
+	Variant string `json:"variant"`
+	Reason  string `json:"reason"`
+	// Index of the matching rule, when Reason is ReasonRule
+	Rule *int `json:"rule,omitempty"`
+}
+
+// Enabled is true when the variant is On
+func (e Evaluation) Enabled() bool {
+	return e.Variant == On
+}
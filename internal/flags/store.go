@@ -0,0 +1,67 @@
+package flags
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/cockroachdb/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// Store keeps the overrides
+type Store interface {
+	// List returns the overrides by flag key.
+	// Entries that can't be decoded are skipped and reported in the error, along with the others.
+	List(ctx context.Context) (map[string]Override, error)
+	Set(ctx context.Context, key string, override Override) error
+	Delete(ctx context.Context, key string) error
+}
+
+// RedisStore keeps the overrides as json in a redis hash, one field per flag,
+// shared by every instance of the service
+type RedisStore struct {
+	Client *redis.Client
+	Key    string
+}
+
+func (s RedisStore) List(ctx context.Context) (map[string]Override, error) {
+	fields, err := s.Client.HGetAll(ctx, s.Key).Result()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get redis hash %s", s.Key)
+	}
+
+	overrides := make(map[string]Override, len(fields))
+	var decodeErrors error
+
+	for key, value := range fields {
+		override := Override{}
+		if err := json.Unmarshal([]byte(value), &override); err != nil {
+			decodeErrors = errors.CombineErrors(decodeErrors, errors.Wrapf(err, "invalid override of %s", key))
+			continue
+		}
+		overrides[key] = override
+	}
+
+	return overrides, decodeErrors
+}
+
+func (s RedisStore) Set(ctx context.Context, key string, override Override) error {
+	value, err := json.Marshal(override)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode override")
+	}
+
+	if err := s.Client.HSet(ctx, s.Key, key, value).Err(); err != nil {
+		return errors.Wrapf(err, "failed to set redis hash field %s %s", s.Key, key)
+	}
+
+	return nil
+}
+
+func (s RedisStore) Delete(ctx context.Context, key string) error {
+	if err := s.Client.HDel(ctx, s.Key, key).Err(); err != nil {
+		return errors.Wrapf(err, "failed to delete redis hash field %s %s", s.Key, key)
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package graph
+
+import (
+	"context"
+	"net/http"
+
+	"golang-service-template/internal/errz"
+	"golang-service-template/internal/flags"
+	"golang-service-template/internal/reporting"
+	"golang-service-template/internal/telemetry"
+
+	"github.com/cockroachdb/errors"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// call is the request an operation comes from, over http or over a websocket
+type call struct {
+	// of the problems, from the Accept-Language header
+	language  string
+	requestID string
+	// for the error reports
+	request *reporting.Request
+}
+
+type callKey struct{}
+
+func withCall(ctx context.Context, c call) context.Context {
+	return context.WithValue(ctx, callKey{}, c)
+}
+
+func callFrom(ctx context.Context) call {
+	c, _ := ctx.Value(callKey{}).(call)
+	if c.language == "" {
+		c.language = errz.DefaultLanguage
+	}
+	return c
+}
+
+// queryError describes err like errz.NewProblem, in the language of the call.
+// The message is the detail of the problem, else its title, the extensions are the members of the problem:
+// code, status, retryable, invalid-params, details, trace_id and request_id.
+func queryError(ctx context.Context, err error) *gqlerrors.QueryError {
+	c := callFrom(ctx)
+	problem := errz.NewProblem(err, c.language)
+
+	message := problem.Detail
+	if message == "" {
+		message = problem.Title
+	}
+
+	extensions := map[string]any{
+		"code":   problem.Code,
+		"status": problem.Status,
+	}
+	if problem.Retryable {
+		extensions["retryable"] = true
+	}
+	if len(problem.InvalidParams) > 0 {
+		extensions["invalid-params"] = problem.InvalidParams
+	}
+	if len(problem.Details) > 0 {
+		extensions["details"] = problem.Details
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+		extensions["trace_id"] = spanContext.TraceID().String()
+	}
+	if c.requestID != "" {
+		extensions["request_id"] = c.requestID
+	}
+
+	return &gqlerrors.QueryError{Err: err, Message: message, Extensions: extensions}
+}
+
+// resolveErrors replaces the errors of the resolvers with their problems, and reports the server errors.
+// The other errors (syntax, validation) are left as graphql-go describes them.
+func (s *Server) resolveErrors(ctx context.Context, errs []*gqlerrors.QueryError) {
+	for index, err := range errs {
+		if err.ResolverError == nil {
+			continue
+		}
+
+		if errz.StatusCode(err.ResolverError) >= http.StatusInternalServerError {
+			s.report(ctx, err.ResolverError, reporting.LevelError)
+		}
+
+		problem := queryError(ctx, err.ResolverError)
+		problem.Locations, problem.Path, problem.ResolverError = err.Locations, err.Path, err.ResolverError
+		errs[index] = problem
+	}
+}
+
+func (s *Server) report(ctx context.Context, err error, level string) string {
+	c := callFrom(ctx)
+
+	var user *reporting.User
+	if claims, ok := claims(ctx); ok {
+		user = &reporting.User{ID: claims.RegisteredClaims.Subject, Tenant: flags.TargetFromContext(ctx).Tenant}
+	}
+
+	return s.reporter.Report(ctx, err, level, c.request, user)
+}
+
+// MakePanicError implements errors.PanicHandler, the panics of the resolvers are reported and described as internal errors
+func (s *Server) MakePanicError(ctx context.Context, value any) *gqlerrors.QueryError {
+	err := panicError(value)
+	eventID := s.report(ctx, err, reporting.LevelFatal)
+
+	telemetry.Logger(ctx).Error().
+		Err(err).
+		Str("event_id", eventID).
+		Msg("panic recovered")
+
+	return queryError(ctx, err)
+}
+
+// panicError keeps the stack of the panic, from the frame that panicked
+func panicError(recovered any) error {
+	// skips panicError and MakePanicError
+	const depth = 2
+
+	if err, ok := recovered.(error); ok {
+		return errors.WrapWithDepth(depth, err, "panic")
+	}
+	return errors.NewWithDepthf(depth, "panic: %v", recovered)
+}
@@ -0,0 +1,298 @@
+// Package graph serves the GraphQL API of the tasks and the users at /graphql, for the frontends
+// that fetch a user with their tasks and counts in one round trip.
+//
+// The API is schema first: schema.graphql is the contract, the resolvers of Resolver are bound to it
+// when the server starts, which fails when they do not match. There is no generated code to keep in sync,
+// the resolvers are plain methods over the services and their types; TestSchemaMatchesResolvers catches
+// the mismatches before the startup does. The resolvers reuse the services,
+// and the fields that would query the database once per parent are batched by dataloaders.
+// The operations are limited in depth and complexity, their query can be persisted, and they are
+// authenticated by the bearer token of the http routes. The subscriptions are served over websockets.
+package graph
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"golang-service-template/internal/dao/query"
+	"golang-service-template/internal/errz"
+	"golang-service-template/internal/middleware"
+	"golang-service-template/internal/redact"
+	"golang-service-template/internal/reporting"
+	"golang-service-template/internal/telemetry"
+
+	"github.com/auth0/go-jwt-middleware/v2/validator"
+	"github.com/gorilla/websocket"
+	graphql "github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/log"
+	"github.com/labstack/echo/v4"
+)
+
+//go:embed schema.graphql
+var Schema string
+
+// maxParallelism is how many resolvers of an operation run at once.
+// The loaders batch the keys of the resolvers running at the same time, so it is the size of the batches too.
+const maxParallelism = 100
+
+// Options of NewServer
+type Options struct {
+	Resolver *Resolver
+	// the loaders query the database directly
+	Query            *query.Query
+	JWTValidator     *validator.Validator
+	PersistedQueries *PersistedQueries
+	Reporter         *reporting.Reporter
+	Redactor         *redact.Redactor
+	// optional, no spans nor metrics without it
+	Telemetry *telemetry.Telemetry
+
+	Introspection bool
+	MaxDepth      int
+	MaxComplexity int
+}
+
+// Server serves the operations over http (GET and POST /graphql) and over websockets
+type Server struct {
+	schema           *graphql.Schema
+	limits           *limits
+	query            *query.Query
+	jwtValidator     *validator.Validator
+	persistedQueries *PersistedQueries
+	reporter         *reporting.Reporter
+	redactor         *redact.Redactor
+	telemetry        *telemetry.Telemetry
+	upgrader         websocket.Upgrader
+
+	// closed by Shutdown, to end the websockets
+	done     chan struct{}
+	shutdown sync.Once
+}
+
+// NewServer binds the resolvers to the schema, it fails when they do not match
+func NewServer(options Options) (*Server, error) {
+	s := &Server{
+		query:            options.Query,
+		jwtValidator:     options.JWTValidator,
+		persistedQueries: options.PersistedQueries,
+		reporter:         options.Reporter,
+		redactor:         options.Redactor,
+		telemetry:        options.Telemetry,
+		upgrader: websocket.Upgrader{
+			Subprotocols: []string{websocketProtocol},
+			// the token is sent in connection_init, not in a cookie, any origin can connect
+			CheckOrigin: func(*http.Request) bool { return true },
+		},
+		done: make(chan struct{}),
+	}
+
+	limits, err := newLimits(Schema, options.MaxDepth, options.MaxComplexity)
+	if err != nil {
+		return nil, err
+	}
+	s.limits = limits
+
+	schemaOptions := []graphql.SchemaOpt{
+		graphql.UseStringDescriptions(),
+		graphql.MaxParallelism(maxParallelism),
+		graphql.PanicHandler(s),
+		// MakePanicError logs them
+		graphql.Logger(log.LoggerFunc(func(context.Context, any) {})),
+	}
+	if options.Telemetry != nil {
+		schemaOptions = append(schemaOptions, graphql.Tracer(fieldTracer{telemetry: options.Telemetry}))
+	}
+	if !options.Introspection {
+		schemaOptions = append(schemaOptions, graphql.DisableIntrospection())
+	}
+
+	schema, err := graphql.ParseSchema(Schema, options.Resolver, schemaOptions...)
+	if err != nil {
+		return nil, err
+	}
+	s.schema = schema
+
+	return s, nil
+}
+
+// Shutdown ends the websockets, the http server does not wait for the hijacked connections
+func (s *Server) Shutdown() {
+	s.shutdown.Do(func() {
+		close(s.done)
+	})
+}
+
+// Request is a GraphQL request, the body of POST /graphql, the query parameters of GET /graphql
+// (variables and extensions as JSON) or the payload of a websocket subscribe message
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+	Extensions    struct {
+		PersistedQuery *PersistedQuery `json:"persistedQuery"`
+	} `json:"extensions"`
+}
+
+// Handle serves GET and POST /graphql, and the websockets of the subscriptions.
+// GET only runs queries, so they can be cached (e.g. the persisted queries).
+// The errors of the request itself (format, token) are problems, the ones of the operation are in its response.
+func (s *Server) Handle(c echo.Context) error {
+	req := c.Request()
+
+	if websocket.IsWebSocketUpgrade(req) {
+		return s.serveWebsocket(c)
+	}
+
+	ctx := withCall(req.Context(), s.callOf(c))
+
+	if authorization := req.Header.Get(echo.HeaderAuthorization); authorization != "" {
+		claims, err := middleware.ValidateBearerToken(ctx, s.jwtValidator, authorization)
+		if err != nil {
+			return err
+		}
+		ctx = middleware.WithClaims(ctx, claims)
+	}
+
+	request, err := readRequest(c)
+	if err != nil {
+		return err
+	}
+
+	allowed := []string{"query", "mutation"}
+	if req.Method == http.MethodGet {
+		allowed = []string{"query"}
+	}
+
+	return c.JSON(http.StatusOK, s.execute(ctx, request, allowed...))
+}
+
+// callOf is the call of an http request, or of the upgrade request of a websocket
+func (s *Server) callOf(c echo.Context) call {
+	req := c.Request()
+	return call{
+		language:  errz.NegotiateLanguage(req.Header.Get("Accept-Language")),
+		requestID: c.Response().Header().Get(echo.HeaderXRequestID),
+		request: &reporting.Request{
+			Method:    req.Method,
+			URL:       req.URL.Path,
+			Route:     c.Path(),
+			Headers:   s.redactor.Headers(req.Header),
+			RequestID: c.Response().Header().Get(echo.HeaderXRequestID),
+			ClientIP:  c.RealIP(),
+		},
+	}
+}
+
+func readRequest(c echo.Context) (Request, error) {
+	request := Request{}
+	req := c.Request()
+
+	if req.Method == http.MethodGet {
+		request.Query = c.QueryParam("query")
+		request.OperationName = c.QueryParam("operationName")
+		if variables := c.QueryParam("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+				return request, errz.New(errz.CodeInvalidRequestFormat, "the variables are not a JSON object", err)
+			}
+		}
+		if extensions := c.QueryParam("extensions"); extensions != "" {
+			if err := json.Unmarshal([]byte(extensions), &request.Extensions); err != nil {
+				return request, errz.New(errz.CodeInvalidRequestFormat, "the extensions are not a JSON object", err)
+			}
+		}
+		return request, nil
+	}
+
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		return request, errz.New(errz.CodeInvalidRequestFormat, "the body is not a GraphQL request", err)
+	}
+	return request, nil
+}
+
+// prepare resolves the query of the request and checks it against the limits, it is the type of the operation
+func (s *Server) prepare(ctx context.Context, request Request) (string, string, []*gqlerrors.QueryError) {
+	query, err := s.persistedQueries.Resolve(ctx, request.Query, request.Extensions.PersistedQuery)
+	if err != nil {
+		return "", "", []*gqlerrors.QueryError{err}
+	}
+	if query == "" {
+		return "", "", []*gqlerrors.QueryError{{Message: "the query is missing"}}
+	}
+
+	operationType, errs := s.limits.check(ctx, query, request.OperationName)
+	return query, operationType, errs
+}
+
+// execute runs a query or a mutation, the types of operation allowed by the transport
+func (s *Server) execute(ctx context.Context, request Request, allowed ...string) *graphql.Response {
+	start := time.Now()
+
+	query, operationType, errs := s.prepare(ctx, request)
+	if len(errs) == 0 && !slices.Contains(allowed, operationType) {
+		errs = []*gqlerrors.QueryError{{Message: operationType + " operations are not allowed here, " + allowedHint(operationType)}}
+	}
+	if len(errs) > 0 {
+		s.record(ctx, operationType, errs, start)
+		return &graphql.Response{Errors: errs}
+	}
+
+	return s.exec(ctx, query, operationType, request, start)
+}
+
+// exec runs a prepared query or mutation
+func (s *Server) exec(ctx context.Context, query, operationType string, request Request, start time.Time) *graphql.Response {
+	ctx, span := s.startSpan(ctx, operationType, request.OperationName)
+	defer span.End()
+
+	ctx = withLoaders(ctx, newLoaders(s.query, true))
+	response := s.schema.Exec(ctx, query, request.OperationName, request.Variables)
+	s.resolveErrors(ctx, response.Errors)
+
+	s.record(ctx, operationType, response.Errors, start)
+	return response
+}
+
+// subscribe runs a prepared subscription, every event is resolved into a response until ctx is done.
+// The metrics count the subscriptions, not their events.
+func (s *Server) subscribe(ctx context.Context, query string, request Request, start time.Time) (<-chan *graphql.Response, []*gqlerrors.QueryError) {
+	// the loaders are shared by the events, without cache
+	ctx = withLoaders(ctx, newLoaders(s.query, false))
+	results, err := s.schema.Subscribe(ctx, query, request.OperationName, request.Variables)
+	if err != nil {
+		errs := []*gqlerrors.QueryError{queryError(ctx, err)}
+		s.record(ctx, "subscription", errs, start)
+		return nil, errs
+	}
+	s.record(ctx, "subscription", nil, start)
+
+	responses := make(chan *graphql.Response)
+	go func() {
+		defer close(responses)
+
+		for result := range results {
+			response := result.(*graphql.Response)
+			s.resolveErrors(ctx, response.Errors)
+
+			select {
+			case responses <- response:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return responses, nil
+}
+
+func allowedHint(operationType string) string {
+	if operationType == "subscription" {
+		return "the subscriptions are served over websockets"
+	}
+	return "use POST"
+}
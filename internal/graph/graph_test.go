@@ -0,0 +1,29 @@
+package graph
+
+import (
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// the resolvers are bound to the schema at runtime, these tests fail on a mismatch before the server does on startup
+
+func TestSchemaMatchesResolvers(t *testing.T) {
+	// the resolvers are only called by the operations
+	_, err := NewServer(Options{
+		Resolver:      NewResolver(nil, nil, nil, nil),
+		MaxDepth:      1,
+		MaxComplexity: 1,
+	})
+	if err != nil {
+		t.Fatalf("the resolvers do not match schema.graphql: %v", err)
+	}
+}
+
+func TestSchemaMismatchFails(t *testing.T) {
+	schema := Schema + "\nextend type Query { unresolved: String }\n"
+
+	if _, err := graphql.ParseSchema(schema, NewResolver(nil, nil, nil, nil)); err == nil {
+		t.Error("a field without a resolver: no error")
+	}
+}
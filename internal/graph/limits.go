@@ -0,0 +1,140 @@
+package graph
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"golang-service-template/internal/errz"
+
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// listSize is the number of items a list is assumed to have, for the complexity
+const listSize = 10
+
+// limits reject the operations that would cost too much before they run, by their depth and their complexity.
+// Every field costs 1, and the fields under a list count for listSize items:
+// { tasks { id creator { email } } } costs 1 + 10 * (1 + 1 + 1) = 31, its depth is 3.
+// The introspection fields are free and their depth is not limited, GraphiQL and the code generators need them.
+type limits struct {
+	schema        *ast.Schema
+	maxDepth      int
+	maxComplexity int
+}
+
+func newLimits(schema string, maxDepth int, maxComplexity int) (*limits, error) {
+	parsed, err := gqlparser.LoadSchema(&ast.Source{Name: "schema.graphql", Input: schema})
+	if err != nil {
+		return nil, err
+	}
+	return &limits{schema: parsed, maxDepth: maxDepth, maxComplexity: maxComplexity}, nil
+}
+
+// check is the type of the operation (query, mutation or subscription), and the error when it goes over a limit.
+// The operations that are not valid are described by the errors of the validation.
+func (l *limits) check(ctx context.Context, query string, operationName string) (string, []*gqlerrors.QueryError) {
+	document, validationErrors := gqlparser.LoadQuery(l.schema, query)
+	if len(validationErrors) > 0 {
+		errs := make([]*gqlerrors.QueryError, 0, len(validationErrors))
+		for _, validationError := range validationErrors {
+			err := &gqlerrors.QueryError{Message: validationError.Message}
+			for _, location := range validationError.Locations {
+				err.Locations = append(err.Locations, gqlerrors.Location{Line: location.Line, Column: location.Column})
+			}
+			errs = append(errs, err)
+		}
+		return "", errs
+	}
+
+	operation := document.Operations.ForName(operationName)
+	if operation == nil {
+		if operationName == "" {
+			return "", []*gqlerrors.QueryError{{Message: "more than one operation in the query, operationName is required"}}
+		}
+		return "", []*gqlerrors.QueryError{{Message: "no operation " + strconv.Quote(operationName) + " in the query"}}
+	}
+	operationType := string(operation.Operation)
+
+	if depth := selectionDepth(operation.SelectionSet); depth > l.maxDepth {
+		err := errz.New(errz.CodeQueryTooComplex, "the depth of the operation is "+strconv.Itoa(depth)+", more than "+strconv.Itoa(l.maxDepth), nil).
+			WithDetails(map[string]string{
+				"depth":     strconv.Itoa(depth),
+				"max_depth": strconv.Itoa(l.maxDepth),
+			})
+		return operationType, []*gqlerrors.QueryError{queryError(ctx, err)}
+	}
+
+	if complexity := selectionComplexity(operation.SelectionSet); complexity > l.maxComplexity {
+		err := errz.New(errz.CodeQueryTooComplex, "the complexity of the operation is "+strconv.Itoa(complexity)+", more than "+strconv.Itoa(l.maxComplexity), nil).
+			WithDetails(map[string]string{
+				"complexity":     strconv.Itoa(complexity),
+				"max_complexity": strconv.Itoa(l.maxComplexity),
+			})
+		return operationType, []*gqlerrors.QueryError{queryError(ctx, err)}
+	}
+
+	return operationType, nil
+}
+
+// isIntrospection is true for the introspection fields, and __typename
+func isIntrospection(field *ast.Field) bool {
+	return field.Definition == nil || strings.HasPrefix(field.Name, "__")
+}
+
+func selectionDepth(selections ast.SelectionSet) int {
+	depth := 0
+
+	for _, selection := range selections {
+		var current int
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if !isIntrospection(selection) {
+				current = 1 + selectionDepth(selection.SelectionSet)
+			}
+		case *ast.InlineFragment:
+			current = selectionDepth(selection.SelectionSet)
+		case *ast.FragmentSpread:
+			if selection.Definition != nil {
+				current = selectionDepth(selection.Definition.SelectionSet)
+			}
+		}
+		depth = max(depth, current)
+	}
+
+	return depth
+}
+
+func selectionComplexity(selections ast.SelectionSet) int {
+	complexity := 0
+
+	for _, selection := range selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			complexity += fieldComplexity(selection)
+		case *ast.InlineFragment:
+			complexity += selectionComplexity(selection.SelectionSet)
+		case *ast.FragmentSpread:
+			if selection.Definition != nil {
+				complexity += selectionComplexity(selection.Definition.SelectionSet)
+			}
+		}
+	}
+
+	return complexity
+}
+
+func fieldComplexity(field *ast.Field) int {
+	if isIntrospection(field) {
+		return 0
+	}
+
+	children := selectionComplexity(field.SelectionSet)
+	if field.Definition.Type.Elem != nil {
+		children *= listSize
+	}
+
+	return 1 + children
+}
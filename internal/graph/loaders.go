@@ -0,0 +1,161 @@
+package graph
+
+import (
+	"context"
+	"time"
+
+	"golang-service-template/internal/dao/model"
+	"golang-service-template/internal/dao/query"
+	"golang-service-template/internal/errz"
+
+	"github.com/graph-gophers/dataloader"
+)
+
+// loaderWait is how long a loader waits for the other keys of its batch.
+// The resolvers of a list run concurrently, their keys come in within this delay.
+const loaderWait = 2 * time.Millisecond
+
+// loaders batch the fields that would query the database once per parent (the N+1 queries):
+// the tasks and the task counts of the users, and the creators of the tasks, are each loaded with one query per level of the operation.
+// They live for one operation, so a result is never served to another request.
+type loaders struct {
+	tasksByCreator *dataloader.Loader
+	taskCounts     *dataloader.Loader
+	users          *dataloader.Loader
+}
+
+// newLoaders creates the loaders of an operation.
+// The subscriptions run their selections once per event with the same loaders, they are created without cache.
+func newLoaders(q *query.Query, cache bool) *loaders {
+	options := []dataloader.Option{dataloader.WithWait(loaderWait)}
+	if !cache {
+		options = append(options, dataloader.WithCache(&dataloader.NoCache{}))
+	}
+
+	return &loaders{
+		tasksByCreator: dataloader.NewBatchedLoader(batchTasksByCreator(q), options...),
+		taskCounts:     dataloader.NewBatchedLoader(batchTaskCounts(q), options...),
+		users:          dataloader.NewBatchedLoader(batchUsers(q), options...),
+	}
+}
+
+type loadersKey struct{}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+// tasksOf is the tasks created by the user, oldest first
+func (l *loaders) tasksOf(ctx context.Context, userID string) ([]*model.Task, error) {
+	value, err := l.tasksByCreator.Load(ctx, dataloader.StringKey(userID))()
+	if err != nil {
+		return nil, err
+	}
+	return value.([]*model.Task), nil
+}
+
+// taskCountOf is the number of tasks created by the user
+func (l *loaders) taskCountOf(ctx context.Context, userID string) (int, error) {
+	value, err := l.taskCounts.Load(ctx, dataloader.StringKey(userID))()
+	if err != nil {
+		return 0, err
+	}
+	return value.(int), nil
+}
+
+// user is the user, nil when there is none
+func (l *loaders) user(ctx context.Context, id string) (*model.User, error) {
+	value, err := l.users.Load(ctx, dataloader.StringKey(id))()
+	if err != nil {
+		return nil, err
+	}
+	return value.(*model.User), nil
+}
+
+func batchTasksByCreator(q *query.Query) dataloader.BatchFunc {
+	return func(ctx context.Context, keys dataloader.Keys) []*dataloader.Result {
+		tasks, err := q.WithContext(ctx).Task.
+			Where(q.Task.CreatedBy.In(keys.Keys()...)).
+			Order(q.Task.CreatedAt, q.Task.ID).
+			Find()
+		if err != nil {
+			return failed(keys, errz.New(errz.CodeInternal, "failed to get tasks", err))
+		}
+
+		byCreator := make(map[string][]*model.Task, len(keys))
+		for _, task := range tasks {
+			byCreator[task.CreatedBy] = append(byCreator[task.CreatedBy], task)
+		}
+
+		results := make([]*dataloader.Result, len(keys))
+		for index, key := range keys {
+			userTasks := byCreator[key.String()]
+			if userTasks == nil {
+				userTasks = []*model.Task{}
+			}
+			results[index] = &dataloader.Result{Data: userTasks}
+		}
+		return results
+	}
+}
+
+func batchTaskCounts(q *query.Query) dataloader.BatchFunc {
+	return func(ctx context.Context, keys dataloader.Keys) []*dataloader.Result {
+		var counts []struct {
+			CreatedBy string
+			Count     int
+		}
+		err := q.WithContext(ctx).Task.
+			Select(q.Task.CreatedBy, q.Task.ID.Count().As("count")).
+			Where(q.Task.CreatedBy.In(keys.Keys()...)).
+			Group(q.Task.CreatedBy).
+			Scan(&counts)
+		if err != nil {
+			return failed(keys, errz.New(errz.CodeInternal, "failed to count tasks", err))
+		}
+
+		byCreator := make(map[string]int, len(counts))
+		for _, count := range counts {
+			byCreator[count.CreatedBy] = count.Count
+		}
+
+		results := make([]*dataloader.Result, len(keys))
+		for index, key := range keys {
+			results[index] = &dataloader.Result{Data: byCreator[key.String()]}
+		}
+		return results
+	}
+}
+
+func batchUsers(q *query.Query) dataloader.BatchFunc {
+	return func(ctx context.Context, keys dataloader.Keys) []*dataloader.Result {
+		users, err := q.WithContext(ctx).User.Where(q.User.ID.In(keys.Keys()...)).Find()
+		if err != nil {
+			return failed(keys, errz.New(errz.CodeInternal, "failed to get users", err))
+		}
+
+		byID := make(map[string]*model.User, len(users))
+		for _, user := range users {
+			byID[user.ID] = user
+		}
+
+		results := make([]*dataloader.Result, len(keys))
+		for index, key := range keys {
+			results[index] = &dataloader.Result{Data: byID[key.String()]}
+		}
+		return results
+	}
+}
+
+// failed is the results of a batch whose query failed, every key gets the error
+func failed(keys dataloader.Keys, err error) []*dataloader.Result {
+	results := make([]*dataloader.Result, len(keys))
+	for index := range keys {
+		results[index] = &dataloader.Result{Error: err}
+	}
+	return results
+}
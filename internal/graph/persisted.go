@@ -0,0 +1,131 @@
+package graph
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+
+	"golang-service-template/internal/errz"
+	"golang-service-template/internal/telemetry"
+
+	"github.com/cockroachdb/errors"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// The errors of the automatic persisted queries protocol, the clients resend the query on PersistedQueryNotFound
+const (
+	persistedQueryNotFound     = "PersistedQueryNotFound"
+	persistedQueryNotFoundCode = "PERSISTED_QUERY_NOT_FOUND"
+)
+
+// persistedQueriesKeyPrefix prefixes the sha256 hash in the redis keys of the automatic persisted queries
+const persistedQueriesKeyPrefix = "graphql:apq:"
+
+// PersistedQuery is the persistedQuery extension of the requests, the sha256 hash of the query
+type PersistedQuery struct {
+	Version    int    `json:"version"`
+	Sha256Hash string `json:"sha256Hash"`
+}
+
+// PersistedQueries lets the clients send the sha256 hash of a query instead of the query:
+//   - the queries of the manifest, a JSON object of the queries by hash, e.g. generated by the build of the frontend
+//   - the automatic persisted queries (APQ): a client sends the hash alone, then the query with its hash
+//     when it is not known yet, the query is kept in redis for the next requests (of every instance)
+//
+// With only, the queries of the manifest are the only ones that run, an allow list.
+type PersistedQueries struct {
+	client   *redis.Client
+	ttl      time.Duration
+	manifest map[string]string
+	only     bool
+}
+
+// NewPersistedQueries loads the manifest, if any, and checks the hash of every query
+func NewPersistedQueries(client *redis.Client, ttl time.Duration, manifestFile string, only bool) (*PersistedQueries, error) {
+	p := &PersistedQueries{
+		client:   client,
+		ttl:      ttl,
+		manifest: map[string]string{},
+		only:     only,
+	}
+
+	if manifestFile == "" {
+		return p, nil
+	}
+
+	content, err := os.ReadFile(manifestFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read persisted queries file %s", manifestFile)
+	}
+	if err := json.Unmarshal(content, &p.manifest); err != nil {
+		return nil, errors.Wrapf(err, "invalid persisted queries file %s", manifestFile)
+	}
+	for hash, query := range p.manifest {
+		if !strings.EqualFold(hash, sha256Hash(query)) {
+			return nil, errors.Newf("invalid persisted queries file %s: %s is not the sha256 hash of its query", manifestFile, hash)
+		}
+	}
+
+	return p, nil
+}
+
+// Resolve is the query of a request: its query when it has no persisted query, else the persisted query of its hash
+func (p *PersistedQueries) Resolve(ctx context.Context, query string, persisted *PersistedQuery) (string, *gqlerrors.QueryError) {
+	if persisted == nil {
+		if p.only {
+			return "", queryError(ctx, errz.New(errz.CodeForbidden, "only the persisted queries are allowed", nil))
+		}
+		return query, nil
+	}
+
+	if persisted.Version != 1 {
+		return "", queryError(ctx, errz.New(errz.CodeBadRequest, "unsupported persisted query version", nil))
+	}
+	hash := strings.ToLower(persisted.Sha256Hash)
+
+	if manifestQuery, ok := p.manifest[hash]; ok {
+		return manifestQuery, nil
+	}
+	if p.only {
+		return "", queryError(ctx, errz.New(errz.CodeForbidden, "only the persisted queries are allowed", nil))
+	}
+
+	key := persistedQueriesKeyPrefix + hash
+
+	if query == "" {
+		// read again for ttl, the queries in use stay
+		stored, err := p.client.GetEx(ctx, key, p.ttl).Result()
+		if err != nil {
+			if !errors.Is(err, redis.Nil) {
+				// the client sends the query again
+				telemetry.Logger(ctx).Warn().Err(err).Msg("failed to get persisted query")
+			}
+			return "", &gqlerrors.QueryError{
+				Message:    persistedQueryNotFound,
+				Extensions: map[string]any{"code": persistedQueryNotFoundCode},
+			}
+		}
+		return stored, nil
+	}
+
+	if sha256Hash(query) != hash {
+		return "", queryError(ctx, errz.New(errz.CodeBadRequest, "the sha256Hash of the persisted query is not the hash of the query", nil))
+	}
+
+	if err := p.client.Set(ctx, key, query, p.ttl).Err(); err != nil {
+		// the query runs anyway, the client will send it again
+		telemetry.Logger(ctx).Warn().Err(err).Msg("failed to persist query")
+	}
+
+	return query, nil
+}
+
+func sha256Hash(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,227 @@
+package graph
+
+import (
+	"context"
+	"net/http"
+
+	"golang-service-template/internal/dao/model"
+	"golang-service-template/internal/errz"
+	"golang-service-template/internal/handler"
+	"golang-service-template/internal/middleware"
+	"golang-service-template/internal/service"
+	"golang-service-template/internal/validation"
+
+	jwtmiddleware "github.com/auth0/go-jwt-middleware/v2"
+	"github.com/auth0/go-jwt-middleware/v2/validator"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// Resolver is the root resolver of schema.graphql, the fields of Query, Mutation and Subscription.
+// The inputs are validated with the rules of the http requests (e.g. handler.CreateTaskRequest).
+type Resolver struct {
+	taskService service.TaskService
+	userService service.UserService
+	taskEvents  service.TaskEvents
+	validator   *validation.Validator
+}
+
+func NewResolver(taskService service.TaskService, userService service.UserService, taskEvents service.TaskEvents, validator *validation.Validator) *Resolver {
+	return &Resolver{
+		taskService: taskService,
+		userService: userService,
+		taskEvents:  taskEvents,
+		validator:   validator,
+	}
+}
+
+// validate validates req like middleware.ValidateRequest, the reasons in the language of the operation
+func (r *Resolver) validate(ctx context.Context, req any) error {
+	return middleware.ValidateStruct(r.validator, r.validator.Translator(callFrom(ctx).language), req)
+}
+
+// claims are the claims of the bearer token of the operation, if any
+func claims(ctx context.Context) (*validator.ValidatedClaims, bool) {
+	claims, ok := ctx.Value(jwtmiddleware.ContextKey{}).(*validator.ValidatedClaims)
+	return claims, ok
+}
+
+// requireClaims is the claims of the bearer token of the operation, unauthorized without one
+func requireClaims(ctx context.Context) (*validator.ValidatedClaims, error) {
+	claims, ok := claims(ctx)
+	if !ok {
+		return nil, errz.New(errz.CodeUnauthorized, "a bearer token is required", nil)
+	}
+	return claims, nil
+}
+
+// isAdmin is true when the token of the operation has the admin role
+func isAdmin(ctx context.Context) bool {
+	claims, ok := claims(ctx)
+	if !ok {
+		return false
+	}
+	customClaims, ok := claims.CustomClaims.(*middleware.CustomClaims)
+	return ok && customClaims.HasRole(middleware.RoleAdmin)
+}
+
+// notFound is nil for the not_found errors, the nullable fields are null when there is nothing
+func notFound(err error) error {
+	if errz.StatusCode(err) == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+// Task resolves Query.task
+func (r *Resolver) Task(ctx context.Context, args struct{ ID graphql.ID }) (*TaskResolver, error) {
+	path := handler.TaskPath{ID: string(args.ID)}
+	if err := r.validate(ctx, path); err != nil {
+		return nil, err
+	}
+
+	task, err := r.taskService.Get(ctx, path.ID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	return &TaskResolver{task: task}, nil
+}
+
+// Tasks resolves Query.tasks
+func (r *Resolver) Tasks(ctx context.Context) ([]*TaskResolver, error) {
+	tasks, err := r.taskService.Find(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return taskResolvers(tasks), nil
+}
+
+// Me resolves Query.me, the user of the subject of the token
+func (r *Resolver) Me(ctx context.Context) (*UserResolver, error) {
+	claims, err := requireClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := r.userService.Get(ctx, claims.RegisteredClaims.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserResolver{user: user}, nil
+}
+
+// User resolves Query.user
+func (r *Resolver) User(ctx context.Context, args struct{ ID graphql.ID }) (*UserResolver, error) {
+	user, err := loadersFrom(ctx).user(ctx, string(args.ID))
+	if err != nil || user == nil {
+		return nil, err
+	}
+
+	return &UserResolver{user: user}, nil
+}
+
+// CreateTask resolves Mutation.createTask
+func (r *Resolver) CreateTask(ctx context.Context, args struct{ Input handler.CreateTaskRequest }) (*TaskResolver, error) {
+	if err := r.validate(ctx, args.Input); err != nil {
+		return nil, err
+	}
+
+	task, err := r.taskService.Create(ctx, model.Task{Description: args.Input.Description})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TaskResolver{task: task}, nil
+}
+
+// UpdateTaskInput is the input of Mutation.updateTask
+type UpdateTaskInput struct {
+	Description string
+}
+
+// UpdateTask resolves Mutation.updateTask
+func (r *Resolver) UpdateTask(ctx context.Context, args struct {
+	ID    graphql.ID
+	Input UpdateTaskInput
+}) (*TaskResolver, error) {
+	request := handler.UpdateTaskRequest{ID: string(args.ID), Description: args.Input.Description}
+	if err := r.validate(ctx, request); err != nil {
+		return nil, err
+	}
+
+	task, err := r.taskService.Update(ctx, request.ID, map[string]any{
+		"description": request.Description,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TaskResolver{task: task}, nil
+}
+
+// DeleteTask resolves Mutation.deleteTask
+func (r *Resolver) DeleteTask(ctx context.Context, args struct{ ID graphql.ID }) (graphql.ID, error) {
+	path := handler.TaskPath{ID: string(args.ID)}
+	if err := r.validate(ctx, path); err != nil {
+		return "", err
+	}
+
+	if err := r.taskService.Delete(ctx, path.ID); err != nil {
+		return "", err
+	}
+
+	return args.ID, nil
+}
+
+// CreateUserInput is the input of Mutation.createUser
+type CreateUserInput struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+// CreateUser resolves Mutation.createUser, for the admins
+func (r *Resolver) CreateUser(ctx context.Context, args struct{ Input CreateUserInput }) (*UserResolver, error) {
+	if _, err := requireClaims(ctx); err != nil {
+		return nil, err
+	}
+	if !isAdmin(ctx) {
+		return nil, errz.New(errz.CodeForbidden, "missing role "+middleware.RoleAdmin, nil)
+	}
+
+	if err := r.validate(ctx, args.Input); err != nil {
+		return nil, err
+	}
+
+	user, err := r.userService.Create(ctx, args.Input.Email, args.Input.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserResolver{user: user}, nil
+}
+
+// TaskChanged resolves Subscription.taskChanged with the TaskEvents, until the subscription ends
+func (r *Resolver) TaskChanged(ctx context.Context, args struct{ CreatedBy *graphql.ID }) <-chan *TaskEventResolver {
+	events := r.taskEvents.Subscribe(ctx)
+	resolvers := make(chan *TaskEventResolver)
+
+	go func() {
+		defer close(resolvers)
+
+		for event := range events {
+			if args.CreatedBy != nil && event.Task.CreatedBy != string(*args.CreatedBy) {
+				continue
+			}
+
+			select {
+			case resolvers <- &TaskEventResolver{event: event}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return resolvers
+}
@@ -0,0 +1,86 @@
+package graph
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"golang-service-template/internal/errz"
+	"golang-service-template/internal/telemetry"
+
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/introspection"
+	"github.com/graph-gophers/graphql-go/trace/tracer"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startSpan starts the span of an operation, the query and the variables are left out, they can hold personal data
+func (s *Server) startSpan(ctx context.Context, operationType, operationName string) (context.Context, trace.Span) {
+	if s.telemetry == nil {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+
+	name := "graphql." + operationType
+	if operationName != "" {
+		name += " " + operationName
+	}
+
+	return s.telemetry.StartSpan(ctx, name,
+		trace.WithAttributes(
+			attribute.String("graphql.operation.type", operationType),
+			attribute.String("graphql.operation.name", operationName),
+			attribute.String("request.id", callFrom(ctx).requestID),
+		))
+}
+
+// record records the metrics of an operation, its type is unknown when the query is invalid
+func (s *Server) record(ctx context.Context, operationType string, errs []*gqlerrors.QueryError, start time.Time) {
+	if s.telemetry == nil {
+		return
+	}
+	if operationType == "" {
+		operationType = "unknown"
+	}
+
+	outcome := "ok"
+	if len(errs) > 0 {
+		outcome = "error"
+	}
+
+	s.telemetry.RecordGraphQLOperation(ctx, operationType, outcome, start)
+}
+
+// fieldTracer starts a span per resolver that is not a plain getter, the operations have theirs already
+type fieldTracer struct {
+	telemetry *telemetry.Telemetry
+}
+
+var _ tracer.Tracer = fieldTracer{}
+
+func (t fieldTracer) TraceQuery(ctx context.Context, _ string, _ string, _ map[string]any, _ map[string]*introspection.Type) (context.Context, tracer.QueryFinishFunc) {
+	return ctx, func([]*gqlerrors.QueryError) {}
+}
+
+func (t fieldTracer) TraceField(ctx context.Context, _ string, typeName, fieldName string, trivial bool, _ map[string]any) (context.Context, tracer.FieldFinishFunc) {
+	if trivial {
+		return ctx, func(*gqlerrors.QueryError) {}
+	}
+
+	ctx, span := t.telemetry.StartSpan(ctx, "graphql.resolve "+typeName+"."+fieldName,
+		trace.WithAttributes(
+			attribute.String("graphql.field.parent", typeName),
+			attribute.String("graphql.field.name", fieldName),
+		))
+
+	return ctx, func(err *gqlerrors.QueryError) {
+		defer span.End()
+
+		// like the http routes, only the errors of the server mark the span as an error
+		if err != nil && err.ResolverError != nil && errz.StatusCode(err.ResolverError) >= http.StatusInternalServerError {
+			t.telemetry.RecordError(ctx, err.ResolverError)
+			span.SetStatus(codes.Error, err.Message)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package graph
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"golang-service-template/internal/dao/model"
+	"golang-service-template/internal/service"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// TaskResolver resolves the fields of Task
+type TaskResolver struct {
+	task *model.Task
+}
+
+func taskResolvers(tasks []*model.Task) []*TaskResolver {
+	resolvers := make([]*TaskResolver, 0, len(tasks))
+	for _, task := range tasks {
+		resolvers = append(resolvers, &TaskResolver{task: task})
+	}
+	return resolvers
+}
+
+func (r *TaskResolver) ID() graphql.ID {
+	return graphql.ID(r.task.ID)
+}
+
+func (r *TaskResolver) Description() string {
+	return r.task.Description
+}
+
+func (r *TaskResolver) State() string {
+	return r.task.State
+}
+
+func (r *TaskResolver) CreatedBy() graphql.ID {
+	return graphql.ID(r.task.CreatedBy)
+}
+
+// Creator is batched with the creators of the other tasks of the operation
+func (r *TaskResolver) Creator(ctx context.Context) (*UserResolver, error) {
+	user, err := loadersFrom(ctx).user(ctx, r.task.CreatedBy)
+	if err != nil || user == nil {
+		return nil, err
+	}
+	return &UserResolver{user: user}, nil
+}
+
+func (r *TaskResolver) CreatedAt() *graphql.Time {
+	return timeOf(r.task.CreatedAt)
+}
+
+func (r *TaskResolver) UpdatedAt() *graphql.Time {
+	return timeOf(r.task.UpdatedAt)
+}
+
+// UserResolver resolves the fields of User
+type UserResolver struct {
+	user *model.User
+}
+
+func (r *UserResolver) ID() graphql.ID {
+	return graphql.ID(r.user.ID)
+}
+
+// Email is only shown to the user themselves and to the admins
+func (r *UserResolver) Email(ctx context.Context) *string {
+	if claims, ok := claims(ctx); (ok && claims.RegisteredClaims.Subject == r.user.ID) || isAdmin(ctx) {
+		return &r.user.Email
+	}
+	return nil
+}
+
+func (r *UserResolver) CreatedAt() *graphql.Time {
+	return timeOf(r.user.CreatedAt)
+}
+
+// Tasks are batched with the tasks of the other users of the operation
+func (r *UserResolver) Tasks(ctx context.Context) ([]*TaskResolver, error) {
+	tasks, err := loadersFrom(ctx).tasksOf(ctx, r.user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return taskResolvers(tasks), nil
+}
+
+// TaskCount is batched with the counts of the other users of the operation
+func (r *UserResolver) TaskCount(ctx context.Context) (int32, error) {
+	count, err := loadersFrom(ctx).taskCountOf(ctx, r.user.ID)
+	return int32(count), err
+}
+
+// TaskEventResolver resolves the fields of TaskEvent
+type TaskEventResolver struct {
+	event service.TaskEvent
+}
+
+// Type is the TaskEventType of the event, e.g. CREATED for service.TaskCreated
+func (r *TaskEventResolver) Type() string {
+	return strings.ToUpper(r.event.Type)
+}
+
+func (r *TaskEventResolver) TaskID() graphql.ID {
+	return graphql.ID(r.event.TaskID)
+}
+
+func (r *TaskEventResolver) Task() *TaskResolver {
+	return &TaskResolver{task: r.event.Task}
+}
+
+func timeOf(t *time.Time) *graphql.Time {
+	if t == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *t}
+}
@@ -0,0 +1,342 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang-service-template/internal/middleware"
+	"golang-service-template/internal/telemetry"
+
+	"github.com/cockroachdb/errors"
+	"github.com/gorilla/websocket"
+	graphql "github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/labstack/echo/v4"
+)
+
+// websocketProtocol is the GraphQL over WebSocket protocol, of graphql-ws:
+// https://github.com/enisdenjo/graphql-ws/blob/master/PROTOCOL.md
+const websocketProtocol = "graphql-transport-ws"
+
+const (
+	// the client has this long to send connection_init
+	websocketInitTimeout = 10 * time.Second
+	// the server pings the idle clients, for the proxies that close the idle connections
+	websocketPingInterval = 30 * time.Second
+	websocketWriteTimeout = 10 * time.Second
+)
+
+// The messages of the protocol
+const (
+	messageConnectionInit = "connection_init"
+	messageConnectionAck  = "connection_ack"
+	messagePing           = "ping"
+	messagePong           = "pong"
+	messageSubscribe      = "subscribe"
+	messageNext           = "next"
+	messageError          = "error"
+	messageComplete       = "complete"
+)
+
+// The close codes of the protocol
+const (
+	closeInvalidMessage        = 4400
+	closeUnauthorized          = 4401
+	closeForbidden             = 4403
+	closeSubprotocolNotAllowed = 4406
+	closeInitTimeout           = 4408
+	closeSubscriberExists      = 4409
+	closeTooManyInits          = 4429
+)
+
+type message struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// session is a websocket, its operations share the token of its connection_init
+type session struct {
+	server *Server
+	conn   *websocket.Conn
+	// of the operations, ended with the websocket
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// a websocket has a single writer at a time
+	writeMu sync.Mutex
+
+	mu          sync.Mutex
+	initialized bool
+	// the operations running, by id
+	operations map[string]context.CancelFunc
+}
+
+// serveWebsocket upgrades the request, the token can be in its Authorization header,
+// or in the Authorization member of the connection_init payload for the browsers
+func (s *Server) serveWebsocket(c echo.Context) error {
+	req := c.Request()
+
+	// the context of the request ends when the handler returns, the websocket outlives it
+	ctx := withCall(context.WithoutCancel(req.Context()), s.callOf(c))
+
+	if authorization := req.Header.Get(echo.HeaderAuthorization); authorization != "" {
+		claims, err := middleware.ValidateBearerToken(ctx, s.jwtValidator, authorization)
+		if err != nil {
+			return err
+		}
+		ctx = middleware.WithClaims(ctx, claims)
+	}
+
+	conn, err := s.upgrader.Upgrade(c.Response(), req, nil)
+	if err != nil {
+		// the upgrader answered the request already
+		telemetry.Logger(ctx).Debug().Err(err).Msg("failed to upgrade to a websocket")
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	ss := &session{
+		server:     s,
+		conn:       conn,
+		ctx:        ctx,
+		cancel:     cancel,
+		operations: map[string]context.CancelFunc{},
+	}
+
+	if conn.Subprotocol() != websocketProtocol {
+		ss.close(closeSubprotocolNotAllowed, "Subprotocol not acceptable")
+		return nil
+	}
+
+	go ss.run()
+	return nil
+}
+
+func (ss *session) run() {
+	defer ss.cancel()
+	defer ss.conn.Close()
+
+	go ss.watch(ss.ctx.Done())
+
+	for {
+		var msg message
+		if err := ss.conn.ReadJSON(&msg); err != nil {
+			var syntaxError *json.SyntaxError
+			var typeError *json.UnmarshalTypeError
+			if errors.As(err, &syntaxError) || errors.As(err, &typeError) {
+				ss.close(closeInvalidMessage, "Invalid message received")
+			}
+			return
+		}
+
+		if !ss.handle(msg) {
+			return
+		}
+	}
+}
+
+// watch closes the websocket when the client does not init in time and when the server shuts down,
+// and pings the client
+func (ss *session) watch(done <-chan struct{}) {
+	initTimeout := time.NewTimer(websocketInitTimeout)
+	defer initTimeout.Stop()
+	ping := time.NewTicker(websocketPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ss.server.done:
+			ss.close(websocket.CloseGoingAway, "Server shutting down")
+			return
+		case <-initTimeout.C:
+			if !ss.isInitialized() {
+				ss.close(closeInitTimeout, "Connection initialisation timeout")
+				return
+			}
+		case <-ping.C:
+			if err := ss.write(message{Type: messagePing}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// handle handles a message of the client, false when the websocket is closed
+func (ss *session) handle(msg message) bool {
+	switch msg.Type {
+	case messageConnectionInit:
+		return ss.init(msg)
+	case messagePing:
+		return ss.write(message{Type: messagePong}) == nil
+	case messagePong:
+		return true
+	case messageSubscribe:
+		return ss.start(msg)
+	case messageComplete:
+		ss.stop(msg.ID)
+		return true
+	default:
+		ss.close(closeInvalidMessage, fmt.Sprintf("Invalid message type %q", msg.Type))
+		return false
+	}
+}
+
+func (ss *session) init(msg message) bool {
+	ss.mu.Lock()
+	initialized := ss.initialized
+	ss.initialized = true
+	ss.mu.Unlock()
+
+	if initialized {
+		ss.close(closeTooManyInits, "Too many initialisation requests")
+		return false
+	}
+
+	var payload struct {
+		Authorization string `json:"Authorization"`
+	}
+	if len(msg.Payload) > 0 && string(msg.Payload) != "null" {
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			ss.close(closeInvalidMessage, "Invalid connection_init payload")
+			return false
+		}
+	}
+
+	if payload.Authorization != "" {
+		claims, err := middleware.ValidateBearerToken(ss.ctx, ss.server.jwtValidator, payload.Authorization)
+		if err != nil {
+			ss.close(closeForbidden, "Forbidden")
+			return false
+		}
+		// only read by the operations, which start after the ack
+		ss.ctx = middleware.WithClaims(ss.ctx, claims)
+	}
+
+	return ss.write(message{Type: messageConnectionAck}) == nil
+}
+
+func (ss *session) start(msg message) bool {
+	if !ss.isInitialized() {
+		ss.close(closeUnauthorized, "Unauthorized")
+		return false
+	}
+
+	var request Request
+	if msg.ID == "" || json.Unmarshal(msg.Payload, &request) != nil {
+		ss.close(closeInvalidMessage, "Invalid subscribe message")
+		return false
+	}
+
+	ss.mu.Lock()
+	if _, ok := ss.operations[msg.ID]; ok {
+		ss.mu.Unlock()
+		ss.close(closeSubscriberExists, fmt.Sprintf("Subscriber for %s already exists", msg.ID))
+		return false
+	}
+	ctx, cancel := context.WithCancel(ss.ctx)
+	ss.operations[msg.ID] = cancel
+	ss.mu.Unlock()
+
+	go ss.operate(ctx, msg.ID, request)
+	return true
+}
+
+// operate runs an operation, queries and mutations are a single next message
+func (ss *session) operate(ctx context.Context, id string, request Request) {
+	defer ss.stop(id)
+
+	start := time.Now()
+	s := ss.server
+
+	query, operationType, errs := s.prepare(ctx, request)
+	if len(errs) > 0 {
+		s.record(ctx, operationType, errs, start)
+		ss.fail(ctx, id, errs)
+		return
+	}
+
+	if operationType != "subscription" {
+		ss.next(ctx, id, s.exec(ctx, query, operationType, request, start))
+		ss.complete(ctx, id)
+		return
+	}
+
+	responses, errs := s.subscribe(ctx, query, request, start)
+	if len(errs) > 0 {
+		ss.fail(ctx, id, errs)
+		return
+	}
+	for response := range responses {
+		if !ss.next(ctx, id, response) {
+			return
+		}
+	}
+	ss.complete(ctx, id)
+}
+
+// stop cancels an operation, when the client completes it or when it ends
+func (ss *session) stop(id string) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if cancel, ok := ss.operations[id]; ok {
+		cancel()
+		delete(ss.operations, id)
+	}
+}
+
+func (ss *session) next(ctx context.Context, id string, response *graphql.Response) bool {
+	payload, err := json.Marshal(response)
+	if err != nil {
+		telemetry.Logger(ctx).Error().Err(err).Msg("failed to encode GraphQL response")
+		return false
+	}
+	return ss.write(message{ID: id, Type: messageNext, Payload: payload}) == nil
+}
+
+// complete tells the client the operation is over, unless the client completed it
+func (ss *session) complete(ctx context.Context, id string) {
+	if ctx.Err() != nil {
+		return
+	}
+	_ = ss.write(message{ID: id, Type: messageComplete})
+}
+
+func (ss *session) fail(ctx context.Context, id string, errs []*gqlerrors.QueryError) {
+	payload, err := json.Marshal(errs)
+	if err != nil {
+		telemetry.Logger(ctx).Error().Err(err).Msg("failed to encode GraphQL errors")
+		return
+	}
+	_ = ss.write(message{ID: id, Type: messageError, Payload: payload})
+}
+
+func (ss *session) write(msg message) error {
+	ss.writeMu.Lock()
+	defer ss.writeMu.Unlock()
+
+	_ = ss.conn.SetWriteDeadline(time.Now().Add(websocketWriteTimeout))
+	return ss.conn.WriteJSON(msg)
+}
+
+// close closes the websocket with a close code of the protocol, the read loop ends on it
+func (ss *session) close(code int, reason string) {
+	ss.writeMu.Lock()
+	defer ss.writeMu.Unlock()
+
+	_ = ss.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(websocketWriteTimeout))
+	_ = ss.conn.Close()
+	ss.cancel()
+}
+
+func (ss *session) isInitialized() bool {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	return ss.initialized
+}
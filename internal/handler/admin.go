@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"golang-service-template/internal/common"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/samber/do"
+)
+
+type AdminController interface {
+	GetConfig() echo.HandlerFunc
+}
+
+type adminController struct {
+	config        common.Config
+	configOrigins common.ConfigOrigins
+}
+
+func NewAdminController(i *do.Injector) (AdminController, error) {
+	return &adminController{
+		config:        do.MustInvoke[common.Config](i),
+		configOrigins: do.MustInvoke[common.ConfigOrigins](i),
+	}, nil
+}
+
+// GetConfig returns the effective config, secrets redacted,
+// with where each value came from: default, file, env or flag
+func (controller *adminController) GetConfig() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]any{
+			"meta": map[string]any{
+				"status": http.StatusOK,
+			},
+			"data": controller.config.Effective(controller.configOrigins),
+		})
+	}
+}
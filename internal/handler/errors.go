@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"golang-service-template/internal/errz"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/samber/do"
+)
+
+// ErrorsController documents the error codes, the `type` of the problem responses points here
+type ErrorsController interface {
+	List() echo.HandlerFunc
+	Get() echo.HandlerFunc
+}
+
+type errorsController struct{}
+
+func NewErrorsController(i *do.Injector) (ErrorsController, error) {
+	return &errorsController{}, nil
+}
+
+// List returns every code of the catalog
+func (controller *errorsController) List() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		catalog := errz.Catalog()
+
+		return c.JSON(
+			http.StatusOK,
+			NewResponse().
+				AddMeta("total", len(catalog)).
+				AddMeta("status", http.StatusOK).
+				SetData(catalog),
+		)
+	}
+}
+
+// Get returns a code of the catalog
+func (controller *errorsController) Get() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		entry, ok := errz.Lookup(c.Param("code"))
+		if !ok {
+			return errz.New(errz.CodeNotFound, "unknown error code", nil)
+		}
+
+		return c.JSON(
+			http.StatusOK,
+			NewResponse().
+				AddMeta("status", http.StatusOK).
+				SetData(entry),
+		)
+	}
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"golang-service-template/internal/flags"
+	"golang-service-template/internal/middleware"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/samber/do"
+)
+
+// FlagController manages the feature flag overrides, under /admin
+type FlagController interface {
+	List() echo.HandlerFunc
+	Get() echo.HandlerFunc
+	Put() echo.HandlerFunc
+	Delete() echo.HandlerFunc
+	Evaluate() echo.HandlerFunc
+}
+
+type flagController struct {
+	flags *flags.Flags
+}
+
+func NewFlagController(i *do.Injector) (FlagController, error) {
+	return &flagController{
+		flags: do.MustInvoke[*flags.Flags](i),
+	}, nil
+}
+
+// List returns every flag defined in code, with its config default and override
+func (controller *flagController) List() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		states, err := controller.flags.States(c.Request().Context())
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(
+			http.StatusOK,
+			NewResponse().
+				AddMeta("total", len(states)).
+				AddMeta("status", http.StatusOK).
+				SetData(states),
+		)
+	}
+}
+
+// Get returns a flag with its config default and override
+func (controller *flagController) Get() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		state, err := controller.flags.State(c.Request().Context(), c.Param("key"))
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(
+			http.StatusOK,
+			NewResponse().
+				AddMeta("status", http.StatusOK).
+				SetData(state),
+		)
+	}
+}
+
+// Put replaces the override of a flag
+func (controller *flagController) Put() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		override := flags.Override{}
+
+		if err := middleware.ValidateRequest(c, &override); err != nil {
+			return err
+		}
+
+		// who changed it, from the admin token
+		if userId, ok := c.Get(middleware.ContextKeyUserId).(string); ok {
+			override.UpdatedBy = userId
+		}
+
+		state, err := controller.flags.SetOverride(c.Request().Context(), c.Param("key"), override)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(
+			http.StatusOK,
+			NewResponse().
+				AddMeta("status", http.StatusOK).
+				SetData(state),
+		)
+	}
+}
+
+// Delete removes the override of a flag
+func (controller *flagController) Delete() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if err := controller.flags.DeleteOverride(c.Request().Context(), c.Param("key")); err != nil {
+			return err
+		}
+
+		return c.JSON(
+			http.StatusOK,
+			NewResponse().
+				AddMeta("status", http.StatusOK).
+				SetMessage("deleted"),
+		)
+	}
+}
+
+// Evaluate returns the variant a target gets, and why, to check a rollout
+func (controller *flagController) Evaluate() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		target := flags.Target{}
+
+		if err := middleware.ValidateRequest(c, &target); err != nil {
+			return err
+		}
+
+		evaluation, err := controller.flags.Evaluate(c.Request().Context(), c.Param("key"), target)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(
+			http.StatusOK,
+			NewResponse().
+				AddMeta("status", http.StatusOK).
+				SetData(evaluation),
+		)
+	}
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"golang-service-template/internal/service"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/cockroachdb/errors"
@@ -15,6 +16,7 @@ import (
 type HealthzController interface {
 	GetHealthz() echo.HandlerFunc
 	GetReadyz() echo.HandlerFunc
+	GetStartupz() echo.HandlerFunc
 	Errorz() echo.HandlerFunc
 }
 
@@ -34,17 +36,11 @@ func NewHealthzController(i *do.Injector) (HealthzController, error) {
 // GetHealthz - Liveness probe endpoint
 // This should be fast and lightweight, only checking if the app is alive
 func (controller *healthzController) GetHealthz() echo.HandlerFunc {
-	type response struct {
-		Status    string `json:"status"`
-		Message   string `json:"message"`
-		Timestamp string `json:"timestamp"`
-	}
-
 	return func(c echo.Context) error {
 		err := controller.healthService.LivenessCheck(c.Request().Context())
 
 		if err != nil {
-			resp := response{
+			resp := ProbeResponse{
 				Status:    "unhealthy",
 				Message:   "Liveness check failed: " + err.Error(),
 				Timestamp: time.Now().Format(time.RFC3339),
@@ -52,7 +48,7 @@ func (controller *healthzController) GetHealthz() echo.HandlerFunc {
 			return c.JSON(http.StatusServiceUnavailable, resp)
 		}
 
-		resp := response{
+		resp := ProbeResponse{
 			Status:    "healthy",
 			Message:   "I am alive 🫡",
 			Timestamp: time.Now().Format(time.RFC3339),
@@ -64,33 +60,52 @@ func (controller *healthzController) GetHealthz() echo.HandlerFunc {
 
 // GetReadyz - Readiness probe endpoint
 // This can be more thorough, checking if the app is ready to serve traffic
+// Add ?verbose=1 to get the status and latency of every check
 func (controller *healthzController) GetReadyz() echo.HandlerFunc {
-	type response struct {
-		Status    string `json:"status"`
-		Message   string `json:"message"`
-		Timestamp string `json:"timestamp"`
+	return func(c echo.Context) error {
+		report, err := controller.healthService.ReadinessCheck(c.Request().Context())
+
+		return probeResponse(c, report, err, "ready", "not_ready", "I am ready to serve traffic 🚀", "Readiness check failed: ")
 	}
+}
 
+// GetStartupz - Startup probe endpoint
+// It succeeds once the critical checks passed for the first time, then it stays successful
+func (controller *healthzController) GetStartupz() echo.HandlerFunc {
 	return func(c echo.Context) error {
-		err := controller.healthService.ReadinessCheck(c.Request().Context())
+		report, err := controller.healthService.StartupCheck(c.Request().Context())
 
-		if err != nil {
-			resp := response{
-				Status:    "not_ready",
-				Message:   "Readiness check failed: " + err.Error(),
-				Timestamp: time.Now().Format(time.RFC3339),
-			}
-			return c.JSON(http.StatusServiceUnavailable, resp)
-		}
+		return probeResponse(c, report, err, "started", "starting", "I am up and running 🏁", "Startup check failed: ")
+	}
+}
 
-		resp := response{
-			Status:    "ready",
-			Message:   "I am ready to serve traffic 🚀",
-			Timestamp: time.Now().Format(time.RFC3339),
-		}
+// ProbeResponse is the body of the probes, not wrapped in the response envelope
+type ProbeResponse struct {
+	Status    string `json:"status"`
+	Message   string `json:"message"`
+	Timestamp string `json:"timestamp"`
+	// With ?verbose=1, on the readiness and startup probes
+	Checks []service.HealthCheckResult `json:"checks,omitempty"`
+}
 
-		return c.JSON(http.StatusOK, resp)
+func probeResponse(c echo.Context, report service.HealthReport, err error, okStatus, failedStatus, okMessage, failedMessage string) error {
+	resp := ProbeResponse{
+		Status:    okStatus,
+		Message:   okMessage,
+		Timestamp: time.Now().Format(time.RFC3339),
 	}
+
+	if verbose, _ := strconv.ParseBool(c.QueryParam("verbose")); verbose {
+		resp.Checks = report.Checks
+	}
+
+	if err != nil {
+		resp.Status = failedStatus
+		resp.Message = failedMessage + err.Error()
+		return c.JSON(http.StatusServiceUnavailable, resp)
+	}
+
+	return c.JSON(http.StatusOK, resp)
 }
 
 func (controller *healthzController) Errorz() echo.HandlerFunc {
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"html/template"
+	"net/http"
+
+	"golang-service-template/internal/common"
+	"golang-service-template/internal/openapi"
+
+	"github.com/labstack/echo/v4"
+	"github.com/samber/do"
+)
+
+// OpenAPIController serves the OpenAPI document of the routes, and the Swagger UI reading it
+type OpenAPIController interface {
+	Document() echo.HandlerFunc
+	Docs() echo.HandlerFunc
+}
+
+type openAPIController struct {
+	spec  *openapi.Spec
+	title string
+}
+
+func NewOpenAPIController(i *do.Injector) (OpenAPIController, error) {
+	return &openAPIController{
+		spec:  do.MustInvoke[*openapi.Spec](i),
+		title: do.MustInvoke[common.Config](i).ServiceName,
+	}, nil
+}
+
+// Document returns the OpenAPI document, as is, without the response envelope
+func (controller *openAPIController) Document() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.JSON(http.StatusOK, controller.spec.Document())
+	}
+}
+
+// the document is relative, so the UI keeps working behind a path prefix
+var docsTemplate = template.Must(template.New("docs").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+	<meta charset="utf-8">
+	<title>{{ . }} API</title>
+	<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+	<div id="swagger-ui"></div>
+	<script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+	<script>
+		window.onload = () => {
+			window.ui = SwaggerUIBundle({ url: "openapi.json", dom_id: "#swagger-ui" });
+		};
+	</script>
+</body>
+</html>
+`))
+
+// Docs returns the Swagger UI
+func (controller *openAPIController) Docs() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMETextHTMLCharsetUTF8)
+		c.Response().WriteHeader(http.StatusOK)
+		return docsTemplate.Execute(c.Response(), controller.title)
+	}
+}
@@ -25,14 +25,15 @@ type taskController struct {
 	taskService service.TaskService
 }
 
+// CreateTaskRequest is the body of Create
+type CreateTaskRequest struct {
+	Description string `json:"description" validate:"required,notblank"`
+}
+
 // Create implements TaskController.
 func (tc *taskController) Create() echo.HandlerFunc {
-	type task struct {
-		Description string `json:"description" validate:"required"`
-	}
-
 	return func(c echo.Context) error {
-		t := task{}
+		t := CreateTaskRequest{}
 
 		// Use middleware's validator helper function for validation
 		if err := middleware.ValidateRequest(c, &t); err != nil {
@@ -99,23 +100,21 @@ func (t *taskController) FindByUserId() echo.HandlerFunc {
 	}
 }
 
+// TaskPath is the path of the task routes
+type TaskPath struct {
+	ID string `param:"id" json:"-" validate:"required,uuid"`
+}
+
 // GetTask implements TaskController.
 func (tc *taskController) GetById() echo.HandlerFunc {
 	return func(c echo.Context) error {
-		id := c.Param("id")
+		path := TaskPath{}
 
-		// Get validator from middleware
-		validate := middleware.GetValidator(c)
-		if validate == nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, "Validator not configured")
-		}
-
-		err := validate.Var(id, "required,uuid")
-		if err != nil {
+		if err := middleware.ValidateRequest(c, &path); err != nil {
 			return err
 		}
 
-		task, err := tc.taskService.Get(c.Request().Context(), id)
+		task, err := tc.taskService.Get(c.Request().Context(), path.ID)
 		if err != nil {
 			return err
 		}
@@ -129,34 +128,23 @@ func (tc *taskController) GetById() echo.HandlerFunc {
 	}
 }
 
+// UpdateTaskRequest is the path and the body of Update
+type UpdateTaskRequest struct {
+	ID          string `param:"id" json:"-" validate:"required,uuid"`
+	Description string `json:"description" validate:"required,notblank"`
+}
+
 // Update implements TaskController.
 func (tc *taskController) Update() echo.HandlerFunc {
-	type task struct {
-		Description string `json:"description" validate:"required"`
-	}
-
 	return func(c echo.Context) error {
-		id := c.Param("id")
-
-		// Get validator from middleware
-		validate := middleware.GetValidator(c)
-		if validate == nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, "Validator not configured")
-		}
-
-		err := validate.Var(id, "required,uuid")
-		if err != nil {
-			return err
-		}
-
-		t := task{}
+		t := UpdateTaskRequest{}
 
 		// Use middleware's validator helper function for validation
 		if err := middleware.ValidateRequest(c, &t); err != nil {
 			return err
 		}
 
-		createdTask, err := tc.taskService.Update(c.Request().Context(), id, map[string]any{
+		createdTask, err := tc.taskService.Update(c.Request().Context(), t.ID, map[string]any{
 			"description": t.Description,
 		})
 		if err != nil {
@@ -175,20 +163,13 @@ func (tc *taskController) Update() echo.HandlerFunc {
 // Delete implements TaskController.
 func (t *taskController) Delete() echo.HandlerFunc {
 	return func(c echo.Context) error {
-		id := c.Param("id")
+		path := TaskPath{}
 
-		// Get validator from middleware
-		validate := middleware.GetValidator(c)
-		if validate == nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, "Validator not configured")
-		}
-
-		err := validate.Var(id, "required,uuid")
-		if err != nil {
+		if err := middleware.ValidateRequest(c, &path); err != nil {
 			return err
 		}
 
-		err = t.taskService.Delete(c.Request().Context(), id)
+		err := t.taskService.Delete(c.Request().Context(), path.ID)
 		if err != nil {
 			return err
 		}
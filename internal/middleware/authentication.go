@@ -3,11 +3,18 @@ package middleware
 import (
 	"context"
 	"golang-service-template/internal/common"
+	"golang-service-template/internal/errz"
+	"golang-service-template/internal/flags"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/auth0/go-jwt-middleware/v2/validator"
+	"github.com/cockroachdb/errors"
 	"github.com/labstack/echo/v4"
 	"github.com/rs/zerolog"
+	"gopkg.in/go-jose/go-jose.v2"
+	"gopkg.in/go-jose/go-jose.v2/jwt"
 
 	jwtmiddleware "github.com/auth0/go-jwt-middleware/v2"
 )
@@ -19,38 +26,117 @@ func ValidateJWTMiddleware(config common.Config, logger zerolog.Logger) echo.Mid
 		logger.Fatal().Msg("JWT_SECRET is required but not configured")
 	}
 
+	jwtValidator, err := NewJWTValidator(config)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("failed to create jwt validator")
+	}
+
+	// new middleware that checks the JWT token
+	middlewareStruct := jwtmiddleware.New(jwtValidator.ValidateToken)
+
+	middleware := echo.WrapMiddleware(middlewareStruct.CheckJWT)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			return middleware(SetUserIdMiddleware(next))(c)
+		}
+	}
+}
+
+// NewJWTValidator validates the HS256 tokens of the issuer and the audience of the config,
+// with the CustomClaims. It is shared by ValidateJWTMiddleware and the RPC services.
+func NewJWTValidator(config common.Config) (*validator.Validator, error) {
 	keyFunc := func(ctx context.Context) (interface{}, error) {
 		// Our token must be signed using this data.
 		return []byte(config.Secret), nil
 	}
 
-	// Set up the validator.
-	jwtValidator, err := validator.New(
+	return validator.New(
 		keyFunc,
 		validator.HS256,
 		config.Issuer,
 		[]string{config.Audience},
+		validator.WithCustomClaims(func() validator.CustomClaims {
+			return &CustomClaims{}
+		}),
 	)
+}
 
-	if err != nil {
-		logger.Fatal().Err(err).Msg("failed to create jwt validator")
-	}
+// RoleAdmin grants access to the /admin routes
+const RoleAdmin = "admin"
 
-	// new middleware that checks the JWT token
-	middlewareStruct := jwtmiddleware.New(jwtValidator.ValidateToken)
+// CustomClaims are the claims we use on top of the registered ones
+type CustomClaims struct {
+	Roles []string `json:"roles,omitempty"`
+	// Tenant of the user, used to target feature flags
+	Tenant string `json:"tenant,omitempty"`
+}
 
-	middleware := echo.WrapMiddleware(middlewareStruct.CheckJWT)
+// Validate implements validator.CustomClaims, nothing to check for now
+func (c *CustomClaims) Validate(ctx context.Context) error {
+	return nil
+}
+
+func (c *CustomClaims) HasRole(role string) bool {
+	for _, r := range c.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
 
+// this middleware checks the token has the role
+// it must be used after ValidateJWTMiddleware
+func RequireRole(role string) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			return middleware(SetUserIdMiddleware(next))(c)
+			claims, ok := c.Request().Context().Value(jwtmiddleware.ContextKey{}).(*validator.ValidatedClaims)
+			if !ok {
+				return echo.NewHTTPError(http.StatusForbidden, "failed to get validated claims")
+			}
+
+			customClaims, ok := claims.CustomClaims.(*CustomClaims)
+			if !ok || !customClaims.HasRole(role) {
+				return echo.NewHTTPError(http.StatusForbidden, "missing role "+role)
+			}
+
+			return next(c)
 		}
 	}
 }
 
+// MintJWT signs a token ValidateJWTMiddleware accepts, for local testing and service accounts
+func MintJWT(config common.Config, subject string, customClaims CustomClaims, ttl time.Duration) (string, error) {
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.HS256, Key: []byte(config.Secret)},
+		(&jose.SignerOptions{}).WithType("JWT"),
+	)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create jwt signer")
+	}
+
+	now := time.Now()
+	claims := jwt.Claims{
+		Issuer:   config.Issuer,
+		Subject:  subject,
+		Audience: jwt.Audience{config.Audience},
+		IssuedAt: jwt.NewNumericDate(now),
+		Expiry:   jwt.NewNumericDate(now.Add(ttl)),
+	}
+
+	token, err := jwt.Signed(signer).Claims(claims).Claims(customClaims).CompactSerialize()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to sign jwt")
+	}
+
+	return token, nil
+}
+
 const ContextKeyUserId = "context_key_user_id"
 
-// this middleware sets the user ID in the context
+// this middleware sets the user ID in the context,
+// and the feature flags target in the request context
 // it must be used after ValidateJWTMiddleware
 func SetUserIdMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
@@ -63,6 +149,43 @@ func SetUserIdMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 		// Add the user ID to the echo context.
 		c.Set(ContextKeyUserId, claims.RegisteredClaims.Subject)
 
+		c.SetRequest(c.Request().WithContext(flags.WithTarget(c.Request().Context(), FlagsTarget(claims))))
+
 		return next(c)
 	}
 }
+
+// ValidateBearerToken validates the bearer token of an Authorization header, like ValidateJWTMiddleware,
+// for the APIs served outside of the echo routes (the RPC services, GraphQL). The errors are unauthorized.
+func ValidateBearerToken(ctx context.Context, jwtValidator *validator.Validator, authorization string) (*validator.ValidatedClaims, error) {
+	scheme, token, found := strings.Cut(authorization, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return nil, errz.New(errz.CodeUnauthorized, "a bearer token is required", nil)
+	}
+
+	validated, err := jwtValidator.ValidateToken(ctx, token)
+	if err != nil {
+		return nil, errz.New(errz.CodeUnauthorized, "invalid token", err)
+	}
+	claims, ok := validated.(*validator.ValidatedClaims)
+	if !ok {
+		return nil, errz.New(errz.CodeUnauthorized, "invalid token", nil)
+	}
+
+	return claims, nil
+}
+
+// WithClaims puts the claims in ctx where ValidateJWTMiddleware puts them, with the feature flags target of the user
+func WithClaims(ctx context.Context, claims *validator.ValidatedClaims) context.Context {
+	ctx = context.WithValue(ctx, jwtmiddleware.ContextKey{}, claims)
+	return flags.WithTarget(ctx, FlagsTarget(claims))
+}
+
+// FlagsTarget is the feature flags target of the user of the token
+func FlagsTarget(claims *validator.ValidatedClaims) flags.Target {
+	target := flags.Target{UserID: claims.RegisteredClaims.Subject}
+	if customClaims, ok := claims.CustomClaims.(*CustomClaims); ok {
+		target.Tenant = customClaims.Tenant
+	}
+	return target
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"golang-service-template/internal/flags"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequireFlag answers 404 when the flag is off for the request target,
+// to roll out a route gradually. The target is set by ValidateJWTMiddleware, use it first.
+//
+//	group.GET("/export", controller.Export(), middleware.RequireFlag(featureFlags, flags.TaskExport))
+func RequireFlag(featureFlags *flags.Flags, flag flags.BoolFlag) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !featureFlags.Enabled(c.Request().Context(), flag) {
+				return echo.NewHTTPError(http.StatusNotFound, "Not Found")
+			}
+
+			return next(c)
+		}
+	}
+}
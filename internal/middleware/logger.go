@@ -4,35 +4,19 @@ import (
 	"bytes"
 	"io"
 	"net/http"
-	"os"
-	"strconv"
-	"strings"
 	"time"
 
+	"golang-service-template/internal/redact"
+	"golang-service-template/internal/settings"
+
 	"github.com/labstack/echo/v4"
 	"github.com/rs/zerolog"
 )
 
 const (
-	maxBodyLogSize = 10 * 1024 // 10KB limit for body logging
+	maxBodyLogSize = 10 * 1024 // 10KB limit for body logging, after redaction
 )
 
-// isBodyLoggingEnabled checks if body logging is enabled via environment variable
-// Set ENABLE_BODY_LOGGING=true to enable body logging (default: false)
-func isBodyLoggingEnabled() bool {
-	enabled := os.Getenv("ENABLE_BODY_LOGGING")
-	if enabled == "" {
-		return false // Default to disabled for security/performance
-	}
-
-	result, err := strconv.ParseBool(enabled)
-	if err != nil {
-		return false // Default to disabled if invalid value
-	}
-
-	return result
-}
-
 // bodyDumpResponseWriter wraps http.ResponseWriter to capture response body
 type bodyDumpResponseWriter struct {
 	io.Writer
@@ -47,58 +31,29 @@ func (w *bodyDumpResponseWriter) Write(b []byte) (int, error) {
 	return w.Writer.Write(b)
 }
 
-// shouldLogBody determines if we should log the body based on content type
-func shouldLogBody(contentType string) bool {
-	// Only log text-based content types
-	textTypes := []string{
-		"application/json",
-		"application/xml",
-		"text/",
-		"application/x-www-form-urlencoded",
-	}
-
-	contentType = strings.ToLower(contentType)
-	for _, textType := range textTypes {
-		if strings.Contains(contentType, textType) {
-			return true
-		}
-	}
-	return false
+// Unwrap lets http.ResponseController reach the connection, e.g. to hijack it for a websocket
+func (w *bodyDumpResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
 }
 
-// truncateBody truncates body if it's too large and masks sensitive data
-func truncateBody(body string, contentType string) string {
-	// Don't log if content type suggests binary data
-	if !shouldLogBody(contentType) {
-		return "[binary content]"
-	}
-
-	if len(body) > maxBodyLogSize {
-		return body[:maxBodyLogSize] + "... [truncated]"
-	}
-
-	// Mask sensitive fields in JSON
-	if strings.Contains(strings.ToLower(contentType), "application/json") {
-		// Simple masking for common sensitive fields
-		sensitiveFields := []string{"password", "token", "secret", "key", "credential"}
-		for _, field := range sensitiveFields {
-			if strings.Contains(strings.ToLower(body), `"`+field+`"`) {
-				// This is a simple approach - in production you might want more sophisticated masking
-				body = strings.ReplaceAll(body, `"`+field+`":"`, `"`+field+`":"[MASKED]","masked_original":"`)
-			}
-		}
-	}
-
-	return body
-}
-
-// LoggerMiddleware is a middleware that logs the request and response.
-func LoggerMiddleware(logger zerolog.Logger) echo.MiddlewareFunc {
+// LoggerMiddleware is a middleware that logs the request and response, the query of the uri redacted by redactor.
+// Bodies and headers are logged when body logging is enabled in the runtime settings (ENABLE_BODY_LOGGING=true, default: false),
+// redacted by redactor.
+func LoggerMiddleware(logger zerolog.Logger, runtimeSettings *settings.RuntimeSettings, redactor *redact.Redactor) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
+			// Request scoped logger, stored in the request context so handlers and services
+			// can get it with telemetry.Logger(ctx). Events bound to ctx get trace_id and span_id
+			// of the span started by TelemetryMiddleware.
+			requestLogger := logger.With().
+				Str("request_id", c.Response().Header().Get(echo.HeaderXRequestID)).
+				Logger()
+			ctx := requestLogger.WithContext(c.Request().Context())
+			c.SetRequest(c.Request().WithContext(ctx))
+
 			// Skip logging for health check endpoints
 			path := c.Path()
-			if path == "/healthz" || path == "/readyz" || path == "/metrics" {
+			if path == "/healthz" || path == "/readyz" || path == "/startupz" || path == "/metrics" {
 				return next(c)
 			}
 
@@ -109,31 +64,32 @@ func LoggerMiddleware(logger zerolog.Logger) echo.MiddlewareFunc {
 
 			// Read and log request body (only if enabled)
 			var requestBody string
-			bodyLoggingEnabled := isBodyLoggingEnabled()
+			bodyLoggingEnabled := runtimeSettings.Get().BodyLogging
 
 			if bodyLoggingEnabled && req.Body != nil {
 				bodyBytes, err := io.ReadAll(req.Body)
 				if err == nil {
-					contentType := req.Header.Get("Content-Type")
-					requestBody = truncateBody(string(bodyBytes), contentType)
+					requestBody = redactor.Body(bodyBytes, req.Header.Get("Content-Type"), maxBodyLogSize)
 					// Restore the body for downstream handlers
 					req.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
 				}
 			}
 
-			logEvent := logger.Debug().
+			logEvent := requestLogger.Debug().
+				Ctx(ctx).
 				Str("method", req.Method).
-				Str("uri", req.RequestURI).
+				Str("uri", redactor.URL(req.URL)).
 				Str("host", req.Host).
 				Str("remote_ip", c.RealIP()).
 				Str("user_agent", req.UserAgent()).
 				Str("content_type", req.Header.Get("Content-Type")).
-				Str("request_id", res.Header().Get(echo.HeaderXRequestID)).
 				Str("correlation_id", res.Header().Get(echo.HeaderXCorrelationID))
 
-			// Only add request body if logging is enabled
+			// Only add request body and headers if logging is enabled
 			if bodyLoggingEnabled {
-				logEvent = logEvent.Str("request_body", requestBody)
+				logEvent = logEvent.
+					Interface("request_headers", redactor.Headers(req.Header)).
+					Str("request_body", requestBody)
 			}
 
 			logEvent.Msg("request received")
@@ -154,8 +110,7 @@ func LoggerMiddleware(logger zerolog.Logger) echo.MiddlewareFunc {
 			// Process response body for logging (only if enabled)
 			var responseBody string
 			if bodyLoggingEnabled && resBody != nil {
-				responseContentType := res.Header().Get("Content-Type")
-				responseBody = truncateBody(resBody.String(), responseContentType)
+				responseBody = redactor.Body(resBody.Bytes(), res.Header().Get("Content-Type"), maxBodyLogSize)
 			}
 
 			// Determine log level based on HTTP status code and error presence
@@ -169,9 +124,10 @@ func LoggerMiddleware(logger zerolog.Logger) echo.MiddlewareFunc {
 				lvl = zerolog.WarnLevel
 			}
 
-			logEvent = logger.WithLevel(lvl).
+			logEvent = requestLogger.WithLevel(lvl).
+				Ctx(ctx).
 				Str("method", req.Method).
-				Str("uri", req.RequestURI).
+				Str("uri", redactor.URL(req.URL)).
 				Int("status", res.Status).
 				Dur("latency", duration).
 				Str("latency_human", duration.String()).
@@ -180,7 +136,6 @@ func LoggerMiddleware(logger zerolog.Logger) echo.MiddlewareFunc {
 				Str("user_agent", req.UserAgent()).
 				Str("request_content_type", req.Header.Get("Content-Type")).
 				Str("response_content_type", res.Header().Get("Content-Type")).
-				Str("request_id", res.Header().Get(echo.HeaderXRequestID)).
 				Str("correlation_id", res.Header().Get(echo.HeaderXCorrelationID)).
 				Stack().
 				Err(err)
@@ -188,6 +143,8 @@ func LoggerMiddleware(logger zerolog.Logger) echo.MiddlewareFunc {
 			// Only add body fields if logging is enabled
 			if bodyLoggingEnabled {
 				logEvent = logEvent.
+					Interface("request_headers", redactor.Headers(req.Header)).
+					Interface("response_headers", redactor.Headers(res.Header())).
 					Str("request_body", requestBody).
 					Str("response_body", responseBody)
 			}
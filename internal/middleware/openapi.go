@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+
+	"golang-service-template/internal/errz"
+	"golang-service-template/internal/openapi"
+	"golang-service-template/internal/telemetry"
+
+	"github.com/labstack/echo/v4"
+)
+
+// OpenAPIValidationMiddleware validates the requests described by the OpenAPI document before the handlers bind them,
+// the invalid ones are rendered like the errors of ValidateRequest.
+// With validateResponses the responses are validated too, and one not matching the document fails the request with a 500.
+// The responses are buffered until then, keep it out of production.
+func OpenAPIValidationMiddleware(validator *openapi.Validator, validateResponses bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			request, err := validator.ValidateRequest(c.Request())
+			if err != nil {
+				return err
+			}
+			if request == nil || !validateResponses {
+				return next(c)
+			}
+
+			res := c.Response()
+			original := res.Writer
+			buffer := &bufferedResponseWriter{ResponseWriter: original, status: http.StatusOK}
+			res.Writer = buffer
+
+			err = next(c)
+			res.Writer = original
+
+			// the errors are rendered after, by the error renderer
+			if !res.Committed {
+				return err
+			}
+
+			if err == nil {
+				validationErr := validator.ValidateResponse(c.Request().Context(), request, buffer.status, res.Header(), buffer.body.Bytes())
+				if validationErr != nil {
+					telemetry.Logger(c.Request().Context()).Error().
+						Err(validationErr).
+						Str("route", c.Path()).
+						Int("status", buffer.status).
+						Msg("the response does not match the OpenAPI document")
+
+					// nothing was sent yet, the error is rendered instead
+					res.Committed = false
+					res.Status = http.StatusOK
+					res.Size = 0
+
+					return errz.New(errz.CodeInternal, "the response does not match the OpenAPI document", validationErr)
+				}
+			}
+
+			original.WriteHeader(buffer.status)
+			if _, writeErr := original.Write(buffer.body.Bytes()); writeErr != nil && err == nil {
+				return writeErr
+			}
+			return err
+		}
+	}
+}
+
+// bufferedResponseWriter keeps the response until it is validated, the headers are the ones of the response
+type bufferedResponseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+func (w *bufferedResponseWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
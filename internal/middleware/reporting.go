@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"net/http"
+
+	"golang-service-template/internal/errz"
+	"golang-service-template/internal/flags"
+	"golang-service-template/internal/redact"
+	"golang-service-template/internal/reporting"
+	"golang-service-template/internal/telemetry"
+
+	"github.com/cockroachdb/errors"
+	"github.com/labstack/echo/v4"
+)
+
+// ReportErrorsMiddleware reports the server errors (5xx) and the panics of the handlers to the error tracker.
+// Panics become errors, so they are rendered by ErrorRendererMiddleware like any other 500;
+// it goes right after ErrorRendererMiddleware.
+func ReportErrorsMiddleware(reporter *reporting.Reporter, redactor *redact.Redactor) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+				// the client went away, net/http handles it
+				if recovered == http.ErrAbortHandler {
+					panic(recovered)
+				}
+
+				err = panicError(recovered)
+				reportPanic(c, reporter, redactor, err)
+			}()
+
+			err = next(c)
+			if err != nil && errz.StatusCode(err) >= http.StatusInternalServerError {
+				reportError(c, reporter, redactor, err, reporting.LevelError)
+			}
+
+			return err
+		}
+	}
+}
+
+// RecoverMiddleware reports the panics of the middlewares running before ReportErrorsMiddleware, like it does
+// for the ones of the handlers. The error is rendered by errz.HTTPErrorHandler; it is the first middleware.
+func RecoverMiddleware(reporter *reporting.Reporter, redactor *redact.Redactor) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+				// the client went away, net/http handles it
+				if recovered == http.ErrAbortHandler {
+					panic(recovered)
+				}
+
+				err = panicError(recovered)
+				reportPanic(c, reporter, redactor, err)
+			}()
+
+			return next(c)
+		}
+	}
+}
+
+func reportPanic(c echo.Context, reporter *reporting.Reporter, redactor *redact.Redactor, err error) {
+	eventID := reportError(c, reporter, redactor, err, reporting.LevelFatal)
+
+	telemetry.Logger(c.Request().Context()).Error().
+		Err(err).
+		Str("event_id", eventID).
+		Msg("panic recovered")
+}
+
+// panicError keeps the stack of the panic, from the frame that panicked
+func panicError(recovered any) error {
+	// skips panicError and the deferred function
+	const depth = 2
+
+	if err, ok := recovered.(error); ok {
+		return errors.WrapWithDepth(depth, err, "panic")
+	}
+	return errors.NewWithDepthf(depth, "panic: %v", recovered)
+}
+
+func reportError(c echo.Context, reporter *reporting.Reporter, redactor *redact.Redactor, err error, level string) string {
+	req := c.Request()
+
+	request := &reporting.Request{
+		Method:    req.Method,
+		URL:       redactor.URL(req.URL),
+		Route:     c.Path(),
+		Headers:   redactor.Headers(req.Header),
+		RequestID: c.Response().Header().Get(echo.HeaderXRequestID),
+		ClientIP:  c.RealIP(),
+	}
+
+	var user *reporting.User
+	if userId, ok := c.Get(ContextKeyUserId).(string); ok {
+		user = &reporting.User{ID: userId, Tenant: flags.TargetFromContext(req.Context()).Tenant}
+	}
+
+	return reporter.Report(req.Context(), err, level, request, user)
+}
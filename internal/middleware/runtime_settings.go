@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"golang-service-template/internal/settings"
+	"slices"
+	"sync/atomic"
+
+	"github.com/labstack/echo/v4"
+	echo_middleware "github.com/labstack/echo/v4/middleware"
+	"golang.org/x/time/rate"
+)
+
+// reloadableMiddleware runs the middleware built from the current runtime settings.
+// build is called again only when the settings it depends on changed (as told by equal),
+// so middlewares keeping state (e.g. the rate limiter store) keep it across unrelated changes.
+func reloadableMiddleware(
+	runtimeSettings *settings.RuntimeSettings,
+	equal func(a, b settings.Settings) bool,
+	build func(settings.Settings) echo.MiddlewareFunc,
+) echo.MiddlewareFunc {
+	var (
+		current atomic.Pointer[echo.MiddlewareFunc]
+		built   *settings.Settings
+	)
+
+	// subscribers are called one at a time, built is not shared
+	runtimeSettings.Subscribe(func(s settings.Settings) {
+		if built != nil && equal(*built, s) {
+			return
+		}
+		built = &s
+
+		middleware := build(s)
+		current.Store(&middleware)
+	})
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			return (*current.Load())(next)(c)
+		}
+	}
+}
+
+// CORSMiddleware allows the origins of the runtime settings.
+// Credentials are only allowed with a single, non wildcard, origin.
+func CORSMiddleware(runtimeSettings *settings.RuntimeSettings) echo.MiddlewareFunc {
+	return reloadableMiddleware(
+		runtimeSettings,
+		func(a, b settings.Settings) bool {
+			return slices.Equal(a.AllowedOrigins, b.AllowedOrigins)
+		},
+		func(s settings.Settings) echo.MiddlewareFunc {
+			return echo_middleware.CORSWithConfig(echo_middleware.CORSConfig{
+				AllowOrigins:     s.AllowedOrigins,
+				AllowCredentials: len(s.AllowedOrigins) == 1 && s.AllowedOrigins[0] != "*",
+				AllowMethods:     []string{echo.GET, echo.POST, echo.PUT, echo.PATCH, echo.DELETE, echo.OPTIONS},
+				AllowHeaders:     []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
+				MaxAge:           86400, // 24 hours
+			})
+		},
+	)
+}
+
+// RateLimitMiddleware limits the requests per client IP with the rate limit of the runtime settings.
+// The counters are kept in memory, per instance, and reset when the rate limit changes.
+func RateLimitMiddleware(runtimeSettings *settings.RuntimeSettings) echo.MiddlewareFunc {
+	return reloadableMiddleware(
+		runtimeSettings,
+		func(a, b settings.Settings) bool {
+			return a.RateLimit == b.RateLimit
+		},
+		func(s settings.Settings) echo.MiddlewareFunc {
+			if s.RateLimit.RequestsPerSecond <= 0 {
+				return func(next echo.HandlerFunc) echo.HandlerFunc {
+					return next
+				}
+			}
+
+			return echo_middleware.RateLimiterWithConfig(echo_middleware.RateLimiterConfig{
+				Skipper: func(c echo.Context) bool {
+					// probes must not be throttled
+					path := c.Path()
+					return path == "/healthz" || path == "/readyz" || path == "/startupz" || path == "/metrics"
+				},
+				Store: echo_middleware.NewRateLimiterMemoryStoreWithConfig(echo_middleware.RateLimiterMemoryStoreConfig{
+					Rate:  rate.Limit(s.RateLimit.RequestsPerSecond),
+					Burst: s.RateLimit.Burst,
+				}),
+			})
+		},
+	)
+}
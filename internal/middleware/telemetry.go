@@ -1,23 +1,34 @@
 package middleware
 
 import (
+	"io"
+	"net/http"
 	"strconv"
 	"time"
 
+	"golang-service-template/internal/errz"
 	"golang-service-template/internal/telemetry"
 
 	"github.com/labstack/echo/v4"
 	"github.com/samber/do"
+	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // TelemetryMiddleware adds telemetry tracking to HTTP requests
+// It follows the OpenTelemetry HTTP server semantic conventions (the same as otelhttp):
+// the incoming traceparent is extracted, the span is a server span named "{method} {route}"
+// and the status is read after the error has been rendered.
 func TelemetryMiddleware(injector *do.Injector) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			// Skip telemetry for health check endpoints
 			path := c.Path()
-			if path == "/healthz" || path == "/readyz" {
+			if path == "/healthz" || path == "/readyz" || path == "/startupz" {
 				return next(c)
 			}
 
@@ -29,39 +40,101 @@ func TelemetryMiddleware(injector *do.Injector) echo.MiddlewareFunc {
 			}
 
 			start := time.Now()
+			req := c.Request()
+
+			// Continue the trace of the caller, if any
+			ctx := otel.GetTextMapPropagator().Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+
+			// Count the request body as it is read, Content-Length is not always set
+			body := &countingReadCloser{ReadCloser: req.Body}
+			if req.Body != nil && req.Body != http.NoBody {
+				req.Body = body
+			}
+
+			spanName := req.Method
+			if path != "" {
+				spanName += " " + path
+			}
 
 			// Create span for the HTTP request (automatically handles enabled/disabled)
-			ctx, span := tel.CreateSpan(c.Request().Context(), "http_request",
-				attribute.String("http.method", c.Request().Method),
-				attribute.String("http.path", c.Path()),
-				attribute.String("http.user_agent", c.Request().UserAgent()),
-				attribute.String("http.remote_addr", c.RealIP()),
-			)
+			ctx, span := tel.StartSpan(ctx, spanName, trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(requestAttributes(c)...))
 			defer span.End()
 
 			// Update the request context
-			c.SetRequest(c.Request().WithContext(ctx))
+			c.SetRequest(req.WithContext(ctx))
 
 			// Process request
 			err = next(c)
 
-			// Collect request information
-			method := c.Request().Method
-			path = c.Path()
-			status := strconv.Itoa(c.Response().Status)
+			// Normally ErrorRendererMiddleware has written the response already,
+			// only errors that escaped it need to be classified here
+			res := c.Response()
+			status := res.Status
+			if err != nil && !res.Committed {
+				status = errz.StatusCode(err)
+			}
 
-			// Record metrics (automatically handles enabled/disabled)
-			tel.RecordHTTPRequest(ctx, method, path, status, start)
+			requestSize := body.n
+			if req.ContentLength > requestSize {
+				requestSize = req.ContentLength
+			}
+
+			span.SetAttributes(
+				semconv.HTTPResponseStatusCode(status),
+				semconv.HTTPRequestBodySize(int(requestSize)),
+				semconv.HTTPResponseBodySize(int(res.Size)),
+				attribute.String("request.id", res.Header().Get(echo.HeaderXRequestID)),
+			)
 
 			// Record error if any
 			if err != nil {
 				tel.RecordError(ctx, err)
-				span.SetAttributes(attribute.String("http.status_code", "500"))
-			} else {
-				span.SetAttributes(attribute.String("http.status_code", status))
 			}
 
+			// For server spans only 5xx are errors, 4xx are the client's fault
+			if status >= http.StatusInternalServerError {
+				span.SetAttributes(semconv.ErrorTypeKey.String(strconv.Itoa(status)))
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+
+			// Record metrics (automatically handles enabled/disabled)
+			tel.RecordHTTPRequest(ctx, req.Method, path, strconv.Itoa(status), start, requestSize, res.Size)
+
 			return err
 		}
 	}
 }
+
+// requestAttributes returns the semantic convention attributes known before the request is handled
+func requestAttributes(c echo.Context) []attribute.KeyValue {
+	req := c.Request()
+
+	attrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(req.Method),
+		semconv.URLPath(req.URL.Path),
+		semconv.URLScheme(c.Scheme()),
+		semconv.ServerAddress(req.Host),
+		semconv.ClientAddress(c.RealIP()),
+		semconv.UserAgentOriginal(req.UserAgent()),
+		semconv.NetworkProtocolVersion(strconv.Itoa(req.ProtoMajor) + "." + strconv.Itoa(req.ProtoMinor)),
+	}
+
+	if route := c.Path(); route != "" {
+		attrs = append(attrs, semconv.HTTPRoute(route))
+	}
+
+	return attrs
+}
+
+// countingReadCloser counts the bytes read from the request body
+type countingReadCloser struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
@@ -2,112 +2,61 @@ package middleware
 
 import (
 	"golang-service-template/internal/errz"
+	"golang-service-template/internal/validation"
 	"net/http"
-	"strings"
+	"sort"
 
-	"github.com/go-playground/locales/en"
-	"github.com/go-playground/locales/id" // Indonesian
 	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
-	en_translations "github.com/go-playground/validator/v10/translations/en"
-	id_translations "github.com/go-playground/validator/v10/translations/id"
 
 	"github.com/cockroachdb/errors"
 	"github.com/labstack/echo/v4"
 )
 
 // ValidatorMiddleware adds request validation capabilities
-// This middleware sets up a validator instance with localized translations based on Accept-Language header
-func ValidatorMiddleware() echo.MiddlewareFunc {
+// It puts the shared validator and the translator of the Accept-Language header in the context
+func ValidatorMiddleware(v *validation.Validator) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			// Get language from Accept-Language header
-			acceptLang := c.Request().Header.Get("Accept-Language")
-			lang := parseAcceptLanguage(acceptLang)
-
-			// Create validator instance
-			validate := validator.New()
-
-			// Set up locales and universal translator
-			english := en.New()
-			indonesian := id.New()
-			uni := ut.New(english, english, indonesian)
-
-			// Get translator based on language
-			trans, found := uni.GetTranslator(lang)
-			if !found {
-				// Fallback to English if language not supported
-				trans, _ = uni.GetTranslator("en")
-			}
-
-			// Register translations based on language
-			switch lang {
-			case "id":
-				_ = id_translations.RegisterDefaultTranslations(validate, trans)
-			default:
-				_ = en_translations.RegisterDefaultTranslations(validate, trans)
-			}
+			// Get language from Accept-Language header, negotiated like the error messages
+			lang := errz.NegotiateLanguage(c.Request().Header.Get("Accept-Language"))
 
 			// Add both validator and translator to context for use by handlers
-			c.Set("validator", validate)
-			c.Set("translator", trans)
+			c.Set("validator", v)
+			c.Set("translator", v.Translator(lang))
 
 			return next(c)
 		}
 	}
 }
 
-// parseAcceptLanguage extracts the primary language from Accept-Language header
-func parseAcceptLanguage(acceptLang string) string {
-	if acceptLang == "" {
-		return "en" // Default to English
-	}
-
-	// Parse Accept-Language header (e.g., "en-US,en;q=0.9,id;q=0.8")
-	// Take the first language preference
-	langs := strings.Split(acceptLang, ",")
-	if len(langs) == 0 {
-		return "en"
-	}
-
-	// Get the first language and remove quality factor if present
-	firstLang := strings.TrimSpace(langs[0])
-	if idx := strings.Index(firstLang, ";"); idx != -1 {
-		firstLang = firstLang[:idx]
-	}
-
-	// Extract language code (e.g., "en-US" -> "en")
-	if idx := strings.Index(firstLang, "-"); idx != -1 {
-		firstLang = firstLang[:idx]
-	}
-
-	// Support specific languages, fallback to English
-	switch strings.ToLower(firstLang) {
-	case "id", "in": // Indonesian
-		return "id"
-	default:
-		return "en"
-	}
-}
-
-// ValidateRequest is a helper function that can be used in handlers to validate request structs
+// ValidateRequest binds the path parameters (`param` tags), the query parameters (`query` tags)
+// and the body, in this order, then validates req.
+//
+//	type request struct {
+//		ID          string `param:"id" json:"-" validate:"required,uuid"`
+//		Description string `json:"description" validate:"required"`
+//	}
 func ValidateRequest(c echo.Context, req interface{}) error {
 	// Bind the request
-	if err := c.Bind(req); err != nil {
-		return errz.NewPrettyError(
-			http.StatusBadRequest,
-			"invalid_request_format",
+	if err := bind(c, req); err != nil {
+		var httpError *echo.HTTPError
+		if errors.As(err, &httpError) && httpError.Code == http.StatusUnsupportedMediaType {
+			return errz.New(errz.CodeUnsupportedMediaType, "Unsupported request content type", err)
+		}
+
+		return errz.New(
+			errz.CodeInvalidRequestFormat,
 			"Invalid request format",
 			err,
 		)
 	}
 
 	// Get validator from context
-	v, ok := c.Get("validator").(*validator.Validate)
+	v, ok := c.Get("validator").(*validation.Validator)
 	if !ok {
-		return errz.NewPrettyError(
-			http.StatusInternalServerError,
-			"validator_not_found",
+		return errz.New(
+			errz.CodeInternal,
 			"Request validator not configured",
 			errors.New("validator not found in context"),
 		)
@@ -116,40 +65,44 @@ func ValidateRequest(c echo.Context, req interface{}) error {
 	// Get translator from context
 	trans, transOk := c.Get("translator").(ut.Translator)
 	if !transOk {
-		return errz.NewPrettyError(
-			http.StatusInternalServerError,
-			"translator_not_found",
+		return errz.New(
+			errz.CodeInternal,
 			"Request translator not configured",
 			errors.New("translator not found in context"),
 		)
 	}
 
-	// Validate the request
+	return ValidateStruct(v, trans, req)
+}
+
+// ValidateStruct validates req, the errors are validation_failed with the invalid-params translated by trans.
+// It is the validation of ValidateRequest, for the requests that are not bound by echo (e.g. the RPC services).
+func ValidateStruct(v *validation.Validator, trans ut.Translator, req interface{}) error {
 	if err := v.Struct(req); err != nil {
 		// Convert validation errors to user-friendly format using translator
 		var validationErrors validator.ValidationErrors
 		if errors.As(err, &validationErrors) {
 			// Use the translator to get localized error messages
-			errorMap := validationErrors.Translate(trans)
-
-			// Collect error messages for the main message
-			var errorMessages []string
-			for _, errMsg := range errorMap {
-				errorMessages = append(errorMessages, errMsg)
+			invalidParams := make([]errz.InvalidParam, 0, len(validationErrors))
+			for _, fieldError := range validationErrors {
+				invalidParams = append(invalidParams, errz.InvalidParam{
+					Name:   validation.ParamName(fieldError),
+					Reason: fieldError.Translate(trans),
+				})
 			}
+			sort.SliceStable(invalidParams, func(a, b int) bool {
+				return invalidParams[a].Name < invalidParams[b].Name
+			})
 
-			return errz.NewPrettyErrorDetail(
-				http.StatusBadRequest,
-				"validation_failed",
-				"Request validation failed: "+strings.Join(errorMessages, ", "),
+			return errz.New(
+				errz.CodeValidationFailed,
+				"Some request parameters are invalid, see invalid-params",
 				err,
-				errorMap, // Pass the detailed error map
-			)
+			).WithInvalidParams(invalidParams)
 		}
 
-		return errz.NewPrettyError(
-			http.StatusBadRequest,
-			"validation_error",
+		return errz.New(
+			errz.CodeValidationFailed,
 			"Request validation error",
 			err,
 		)
@@ -158,9 +111,22 @@ func ValidateRequest(c echo.Context, req interface{}) error {
 	return nil
 }
 
+// bind is echo's binding, with the query parameters bound for every method, not only GET and DELETE
+func bind(c echo.Context, req interface{}) error {
+	binder := &echo.DefaultBinder{}
+
+	if err := binder.BindPathParams(c, req); err != nil {
+		return err
+	}
+	if err := binder.BindQueryParams(c, req); err != nil {
+		return err
+	}
+	return binder.BindBody(c, req)
+}
+
 // GetValidator returns the validator instance from the echo context
-func GetValidator(c echo.Context) *validator.Validate {
-	if v, ok := c.Get("validator").(*validator.Validate); ok {
+func GetValidator(c echo.Context) *validation.Validator {
+	if v, ok := c.Get("validator").(*validation.Validator); ok {
 		return v
 	}
 	return nil
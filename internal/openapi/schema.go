@@ -0,0 +1,350 @@
+package openapi
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+const componentsPrefix = "#/components/schemas/"
+
+// schemas of the types encoded by their own MarshalJSON
+var knownTypes = map[reflect.Type]func() *Schema{
+	reflect.TypeOf(time.Time{}): func() *Schema {
+		return &Schema{Type: Types{"string"}, Format: "date-time"}
+	},
+	// null when not deleted
+	reflect.TypeOf(gorm.DeletedAt{}): func() *Schema {
+		return &Schema{Type: Types{"string", "null"}, Format: "date-time"}
+	},
+	reflect.TypeOf(json.RawMessage{}): func() *Schema {
+		return &Schema{}
+	},
+}
+
+// schemas builds the schemas of the Go types, the named structs are components referenced by $ref
+type schemas struct {
+	components map[string]*Schema
+	// the type of each component, to tell apart the types of the same name
+	types map[string]reflect.Type
+}
+
+func newSchemas() *schemas {
+	return &schemas{
+		components: map[string]*Schema{},
+		types:      map[string]reflect.Type{},
+	}
+}
+
+// of returns the schema of t as encoded by encoding/json
+func (s *schemas) of(t reflect.Type) *Schema {
+	if known, ok := knownTypes[t]; ok {
+		return known()
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return s.of(t.Elem())
+	case reflect.Bool:
+		return &Schema{Type: Types{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: Types{"integer"}}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: Types{"number"}}
+	case reflect.String:
+		return &Schema{Type: Types{"string"}}
+	case reflect.Slice, reflect.Array:
+		// []byte is base64
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: Types{"string"}, Format: "byte"}
+		}
+		return &Schema{Type: Types{"array"}, Items: s.of(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: Types{"object"}, AdditionalProperties: s.of(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		return &Schema{Ref: componentsPrefix + s.component(t)}
+	}
+
+	// interfaces, anything
+	return &Schema{}
+}
+
+// component adds the schema of a named struct to the components, and returns its name
+func (s *schemas) component(t reflect.Type) string {
+	name := componentName(t, false)
+	if other, ok := s.types[name]; ok && other != t {
+		name = componentName(t, true)
+	}
+
+	if _, ok := s.components[name]; !ok {
+		// set before building it, for the types referencing themselves
+		schema := &Schema{}
+		s.components[name] = schema
+		s.types[name] = t
+		*schema = *s.object(t)
+	}
+
+	return name
+}
+
+// componentName is the name of the type, prefixed by its package when qualified, e.g. FlagsState
+func componentName(t reflect.Type, qualified bool) string {
+	name := t.Name()
+	if qualified {
+		name = path.Base(t.PkgPath()) + "_" + name
+	}
+
+	// generic types, e.g. Page[golang-service-template/internal/dao/model.Task]
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// object is the schema of the json fields of a struct, the embedded structs flattened as encoding/json does
+func (s *schemas) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: Types{"object"}, Properties: map[string]*Schema{}}
+
+	for _, field := range bodyFields(t) {
+		property := s.of(field.Type)
+		constrain(property, field.Type, field.Tag.Get("validate"))
+
+		schema.Properties[field.Name] = property
+		if isRequired(field.Tag.Get("validate")) {
+			schema.Required = append(schema.Required, field.Name)
+		}
+	}
+
+	return schema
+}
+
+// bodyFields lists the fields encoded by encoding/json, without the path, query and header parameters.
+// The Name of the fields is their json name.
+func bodyFields(t reflect.Type) []reflect.StructField {
+	var fields, embeddedFields []reflect.StructField
+	seen := map[string]bool{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if isParameter(field) {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				embeddedFields = append(embeddedFields, bodyFields(embedded)...)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		field.Name = name
+		seen[name] = true
+		fields = append(fields, field)
+	}
+
+	// the fields of the outer struct win
+	for _, field := range embeddedFields {
+		if !seen[field.Name] {
+			seen[field.Name] = true
+			fields = append(fields, field)
+		}
+	}
+
+	return fields
+}
+
+// parameterTags are the binding tags of echo, by the location of the parameter
+var parameterTags = []struct{ tag, in string }{
+	{"param", "path"},
+	{"query", "query"},
+	{"header", "header"},
+}
+
+func isParameter(field reflect.StructField) bool {
+	for _, location := range parameterTags {
+		if field.Tag.Get(location.tag) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// parameters lists the path, query and header parameters of a request struct, embedded structs included
+func (s *schemas) parameters(t reflect.Type) []Parameter {
+	var parameters []Parameter
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if field.Anonymous && !isParameter(field) {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				parameters = append(parameters, s.parameters(embedded)...)
+			}
+			continue
+		}
+
+		for _, location := range parameterTags {
+			name := field.Tag.Get(location.tag)
+			if name == "" {
+				continue
+			}
+
+			validate := field.Tag.Get("validate")
+			schema := s.of(field.Type)
+			constrain(schema, field.Type, validate)
+
+			parameters = append(parameters, Parameter{
+				Name:     name,
+				In:       location.in,
+				Required: location.in == "path" || isRequired(validate),
+				Schema:   schema,
+			})
+		}
+	}
+
+	return parameters
+}
+
+func isRequired(validate string) bool {
+	for _, rule := range strings.Split(validate, ",") {
+		if rule == "dive" {
+			return false
+		}
+		if rule == "required" {
+			return true
+		}
+	}
+	return false
+}
+
+// constrain adds the rules of a validate tag the schema can express, e.g. min=1 is minLength for a string.
+// The rules after dive apply to the items.
+func constrain(schema *Schema, t reflect.Type, validate string) {
+	if validate == "" || schema.Ref != "" {
+		return
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	rules := strings.Split(validate, ",")
+	for index, rule := range rules {
+		if rule == "dive" {
+			if schema.Items != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+				constrain(schema.Items, t.Elem(), strings.Join(rules[index+1:], ","))
+			}
+			return
+		}
+
+		tag, param, _ := strings.Cut(rule, "=")
+		switch tag {
+		case "uuid", "uuid4", "uuid7", "uuid_rfc4122":
+			schema.Format = "uuid"
+		case "email":
+			schema.Format = "email"
+		case "url", "uri", "http_url":
+			schema.Format = "uri"
+		case "notblank":
+			schema.Pattern = `\S`
+		case "oneof":
+			for _, value := range strings.Fields(param) {
+				schema.Enum = append(schema.Enum, enumValue(t, value))
+			}
+		case "len":
+			bound(schema, t, param, true, true)
+		case "min", "gte":
+			bound(schema, t, param, true, false)
+		case "max", "lte":
+			bound(schema, t, param, false, true)
+		}
+	}
+}
+
+// bound sets the minimum and/or maximum of the length, the items or the value, depending on the type
+func bound(schema *Schema, t reflect.Type, param string, lower, upper bool) {
+	value, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	length := int(value)
+
+	switch t.Kind() {
+	case reflect.String:
+		if lower {
+			schema.MinLength = &length
+		}
+		if upper {
+			schema.MaxLength = &length
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if lower {
+			schema.MinItems = &length
+		}
+		if upper {
+			schema.MaxItems = &length
+		}
+	default:
+		if lower {
+			schema.Minimum = &value
+		}
+		if upper {
+			schema.Maximum = &value
+		}
+	}
+}
+
+// enumValue is a oneof value of the type of the field, e.g. a number for an int
+func enumValue(t reflect.Type, value string) any {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if number, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return number
+		}
+	case reflect.Float32, reflect.Float64:
+		if number, err := strconv.ParseFloat(value, 64); err == nil {
+			return number
+		}
+	}
+	return value
+}
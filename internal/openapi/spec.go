@@ -0,0 +1,367 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang-service-template/internal/errz"
+
+	"github.com/labstack/echo/v4"
+)
+
+// BearerAuth is the security scheme of the secured operations, a JWT in the Authorization header
+const BearerAuth = "bearerAuth"
+
+// Operation documents a route
+type Operation struct {
+	// Unique, the name of the method in the generated clients, e.g. getTask
+	ID          string
+	Summary     string
+	Description string
+	Tags        []string
+	Deprecated  bool
+
+	// Request is the struct bound by the handler (e.g. with middleware.ValidateRequest):
+	// its param, query and header fields are the parameters, its json fields the body.
+	// The path parameters it has no field for are documented as strings.
+	Request any
+
+	// Response is the data of the response envelope, e.g. model.Task{} or []*model.Task{}, none when nil
+	Response any
+	// The response is Response itself, without the envelope
+	Raw bool
+	// Of the successful response, http.StatusOK when zero
+	Status int
+
+	// Needs a bearer token
+	Secured bool
+	// Roles needed on top of the token, e.g. admin
+	Roles []string
+
+	// errz codes the operation responds with, on top of the ones of the request validation and the authentication
+	Errors []string
+}
+
+// Spec collects the documented routes, it is safe for concurrent use
+type Spec struct {
+	mu         sync.Mutex
+	info       Info
+	operations map[routeKey]Operation
+	excluded   map[routeKey]bool
+}
+
+type routeKey struct {
+	method string
+	path   string
+}
+
+func (key routeKey) String() string {
+	return key.method + " " + key.path
+}
+
+func New(info Info) *Spec {
+	return &Spec{
+		info:       info,
+		operations: map[routeKey]Operation{},
+		excluded:   map[routeKey]bool{},
+	}
+}
+
+// Add documents a route, and returns it.
+// Documenting the same method and path again replaces the operation.
+func (s *Spec) Add(route *echo.Route, operation Operation) *echo.Route {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.operations[routeKey{route.Method, route.Path}] = operation
+	return route
+}
+
+// Exclude leaves routes out of the document on purpose, e.g. the metrics, so Drift does not report them
+func (s *Spec) Exclude(routes ...*echo.Route) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, route := range routes {
+		s.excluded[routeKey{route.Method, route.Path}] = true
+	}
+}
+
+// Drift lists the routes of the server neither documented nor excluded,
+// and the documented operations without a route, sorted. Empty when the document matches the server.
+func (s *Spec) Drift(routes []*echo.Route) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	registered := map[routeKey]bool{}
+	var drift []string
+
+	for _, route := range routes {
+		// the catch-all of the groups with middlewares
+		if route.Method == echo.RouteNotFound {
+			continue
+		}
+
+		key := routeKey{route.Method, route.Path}
+		registered[key] = true
+
+		if _, ok := s.operations[key]; !ok && !s.excluded[key] {
+			drift = append(drift, fmt.Sprintf("%s is not documented", key))
+		}
+	}
+
+	for key := range s.operations {
+		if !registered[key] {
+			drift = append(drift, fmt.Sprintf("%s is documented but not registered", key))
+		}
+	}
+
+	sort.Strings(drift)
+	return drift
+}
+
+// Document builds the OpenAPI document of the documented routes
+func (s *Spec) Document() Document {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc := Document{
+		OpenAPI: Version,
+		Info:    s.info,
+		Paths:   map[string]*PathItem{},
+	}
+	schemas := newSchemas()
+	tags := map[string]bool{}
+	secured := false
+
+	keys := make([]routeKey, 0, len(s.operations))
+	for key := range s.operations {
+		keys = append(keys, key)
+	}
+	// the schemas of the same name are qualified the same way on every build
+	sort.Slice(keys, func(a, b int) bool {
+		if keys[a].path != keys[b].path {
+			return keys[a].path < keys[b].path
+		}
+		return keys[a].method < keys[b].method
+	})
+
+	for _, key := range keys {
+		operation := s.operations[key]
+		path, pathParams := openAPIPath(key.path)
+
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+		(*item)[strings.ToLower(key.method)] = operation.build(key.method, pathParams, schemas)
+
+		for _, tag := range operation.Tags {
+			tags[tag] = true
+		}
+		secured = secured || operation.Secured
+	}
+
+	for tag := range tags {
+		doc.Tags = append(doc.Tags, Tag{Name: tag})
+	}
+	sort.Slice(doc.Tags, func(a, b int) bool { return doc.Tags[a].Name < doc.Tags[b].Name })
+
+	doc.Components.Schemas = schemas.components
+	if secured {
+		doc.Components.SecuritySchemes = map[string]*SecurityScheme{
+			BearerAuth: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+		}
+	}
+
+	return doc
+}
+
+// openAPIPath turns an echo path into a templated path, e.g. /tasks/:id is /tasks/{id}, with its parameters
+func openAPIPath(echoPath string) (string, []string) {
+	var params []string
+
+	segments := strings.Split(echoPath, "/")
+	for index, segment := range segments {
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			params = append(params, name)
+			segments[index] = "{" + name + "}"
+		}
+	}
+
+	return strings.Join(segments, "/"), params
+}
+
+func (operation Operation) build(method string, pathParams []string, schemas *schemas) *OperationObject {
+	object := &OperationObject{
+		OperationID: operation.ID,
+		Summary:     operation.Summary,
+		Description: operation.Description,
+		Tags:        operation.Tags,
+		Deprecated:  operation.Deprecated,
+		Responses:   map[string]*Response{},
+	}
+
+	var parameters []Parameter
+	if operation.Request != nil {
+		t := reflect.TypeOf(operation.Request)
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+
+		parameters = schemas.parameters(t)
+
+		body := schemas.object(t)
+		if len(body.Properties) > 0 && method != http.MethodGet && method != http.MethodDelete {
+			object.RequestBody = &RequestBody{
+				Required: len(body.Required) > 0,
+				Content:  map[string]MediaType{echo.MIMEApplicationJSON: {Schema: schemas.of(t)}},
+			}
+		}
+	}
+
+	// the path parameters first, in the order of the path
+	for _, name := range pathParams {
+		parameter := Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: Types{"string"}}}
+		for _, documented := range parameters {
+			if documented.In == "path" && documented.Name == name {
+				parameter = documented
+			}
+		}
+		object.Parameters = append(object.Parameters, parameter)
+	}
+	for _, parameter := range parameters {
+		if parameter.In != "path" {
+			object.Parameters = append(object.Parameters, parameter)
+		}
+	}
+
+	status := operation.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	object.Responses[strconv.Itoa(status)] = operation.response(status, schemas)
+
+	if operation.Secured {
+		object.Security = []map[string][]string{{BearerAuth: {}}}
+		if len(operation.Roles) > 0 {
+			object.Description = strings.TrimSpace(object.Description + "\n\nRequires the role: " + strings.Join(operation.Roles, ", ") + ".")
+		}
+	}
+
+	for status, response := range operation.problems(schemas) {
+		object.Responses[status] = response
+	}
+
+	return object
+}
+
+// response is the successful response, the data in the envelope unless Raw
+func (operation Operation) response(status int, schemas *schemas) *Response {
+	response := &Response{Description: http.StatusText(status)}
+
+	var data *Schema
+	if operation.Response != nil {
+		data = schemas.of(reflect.TypeOf(operation.Response))
+	}
+
+	if operation.Raw {
+		if data != nil {
+			response.Content = map[string]MediaType{echo.MIMEApplicationJSON: {Schema: data}}
+		}
+		return response
+	}
+
+	envelope := &Schema{
+		Type: Types{"object"},
+		Properties: map[string]*Schema{
+			"message": {Type: Types{"string"}},
+			"meta":    {Ref: componentsPrefix + metaComponent(schemas)},
+		},
+		Required: []string{"meta"},
+	}
+	if data != nil {
+		envelope.Properties["data"] = data
+		envelope.Required = append(envelope.Required, "data")
+	}
+	response.Content = map[string]MediaType{echo.MIMEApplicationJSON: {Schema: envelope}}
+
+	return response
+}
+
+// metaComponent adds the meta of the envelope to the components
+func metaComponent(schemas *schemas) string {
+	const name = "Meta"
+
+	if _, ok := schemas.components[name]; !ok {
+		schemas.components[name] = &Schema{
+			Type: Types{"object"},
+			Properties: map[string]*Schema{
+				"status": {Type: Types{"integer"}, Description: "The http status of the response"},
+				"total":  {Type: Types{"integer"}, Description: "The number of items of the lists"},
+			},
+			Required:             []string{"status"},
+			AdditionalProperties: &Schema{},
+		}
+	}
+	return name
+}
+
+// problems are the error responses by status, the codes of the same status in the same response.
+// The codes nobody listed (e.g. internal_server_error) are the default response.
+func (operation Operation) problems(schemas *schemas) map[string]*Response {
+	codes := append([]string{}, operation.Errors...)
+	if operation.Request != nil {
+		codes = append(codes, errz.CodeInvalidRequestFormat, errz.CodeUnsupportedMediaType, errz.CodeValidationFailed)
+	}
+	if operation.Secured {
+		codes = append(codes, errz.CodeUnauthorized)
+	}
+	if len(operation.Roles) > 0 {
+		codes = append(codes, errz.CodeForbidden)
+	}
+
+	problem := map[string]MediaType{errz.ProblemContentType: {Schema: schemas.of(reflect.TypeOf(errz.Problem{}))}}
+	responses := map[string]*Response{
+		"default": {Description: "Unexpected error, e.g. " + errz.CodeInternal, Content: problem},
+	}
+
+	descriptions := map[string][]string{}
+	for _, code := range codes {
+		entry, ok := errz.Lookup(code)
+		if !ok {
+			continue
+		}
+
+		status := strconv.Itoa(entry.Status)
+		response, ok := responses[status]
+		if !ok {
+			response = &Response{Content: problem}
+			responses[status] = response
+		}
+
+		description := code + ": " + entry.LocalizedTitle(errz.DefaultLanguage)
+		if !slices.Contains(descriptions[status], description) {
+			descriptions[status] = append(descriptions[status], description)
+		}
+		if entry.Retryable {
+			response.Headers = map[string]Header{
+				"Retry-After": {Description: "Seconds to wait before retrying", Schema: &Schema{Type: Types{"integer"}}},
+			}
+		}
+	}
+
+	for status, description := range descriptions {
+		sort.Strings(description)
+		responses[status].Description = strings.Join(description, "\n\n")
+	}
+
+	return responses
+}
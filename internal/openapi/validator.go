@@ -0,0 +1,230 @@
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang-service-template/internal/errz"
+
+	"github.com/cockroachdb/errors"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+// formats validated on top of the ones of kin-openapi (date, date-time, byte), the uuid of any version (ours are v7)
+func init() {
+	openapi3.DefineStringFormat("uuid", `^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	openapi3.DefineStringFormat("email", openapi3.FormatOfStringForEmail)
+	openapi3.DefineIPv4Format()
+	openapi3.DefineIPv6Format()
+}
+
+// Validator validates the requests, and the responses, against a hand-written OpenAPI document.
+// The authentication is not validated, it is done by the middlewares of the routes.
+type Validator struct {
+	router routers.Router
+}
+
+// NewValidator loads the document, yaml or json, its external $refs relative to it
+func NewValidator(ctx context.Context, path string) (*Validator, error) {
+	loader := openapi3.NewLoader()
+	loader.Context = ctx
+	loader.IsExternalRefsAllowed = true
+
+	doc, err := loader.LoadFromFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load the OpenAPI document %s", path)
+	}
+	if err := doc.Validate(ctx); err != nil {
+		return nil, errors.Wrapf(err, "invalid OpenAPI document %s", path)
+	}
+
+	// the paths are matched whatever the host the service is reached by, keeping the base path of the servers
+	servers := openapi3.Servers{}
+	seen := map[string]bool{}
+	for _, server := range doc.Servers {
+		base := "/"
+		if u, err := url.Parse(server.URL); err == nil && u.Path != "" {
+			base = u.Path
+		}
+		if !seen[base] {
+			seen[base] = true
+			servers = append(servers, &openapi3.Server{URL: base})
+		}
+	}
+	if len(servers) == 0 {
+		servers = append(servers, &openapi3.Server{URL: "/"})
+	}
+	doc.Servers = servers
+
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid OpenAPI document %s", path)
+	}
+
+	return &Validator{router: router}, nil
+}
+
+// Request is a request matched to an operation of the document, to validate its response
+type Request struct {
+	input *openapi3filter.RequestValidationInput
+}
+
+// ValidateRequest validates the parameters and the body of req, the body is read and put back.
+// The request is nil, without an error, when the document has no operation for it.
+// The errors are errz errors: validation_failed with the invalid-params, or the format of the body.
+func (v *Validator) ValidateRequest(req *http.Request) (*Request, error) {
+	route, pathParams, err := v.router.FindRoute(req)
+	if err != nil {
+		// not described, not validated
+		return nil, nil
+	}
+
+	input := &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: pathParams,
+		Route:      route,
+		Options: &openapi3filter.Options{
+			MultiError:         true,
+			AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		},
+	}
+
+	if err := openapi3filter.ValidateRequest(req.Context(), input); err != nil {
+		return nil, requestProblem(err)
+	}
+
+	return &Request{input: input}, nil
+}
+
+// ValidateResponse validates the status, the headers and the body of the response to a validated request
+func (v *Validator) ValidateResponse(ctx context.Context, request *Request, status int, header http.Header, body []byte) error {
+	return openapi3filter.ValidateResponse(ctx, &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: request.input,
+		Status:                 status,
+		Header:                 header,
+		Body:                   io.NopCloser(bytes.NewReader(body)),
+		Options: &openapi3filter.Options{
+			MultiError:            true,
+			IncludeResponseStatus: true,
+		},
+	})
+}
+
+// requestProblem turns the errors of openapi3filter into the errz errors of the validation middleware
+func requestProblem(err error) error {
+	var params []errz.InvalidParam
+
+	for _, e := range flatten(err) {
+		var requestError *openapi3filter.RequestError
+		if !errors.As(e, &requestError) {
+			return errz.New(errz.CodeValidationFailed, "Request validation error", err)
+		}
+
+		switch {
+		case requestError.Parameter != nil:
+			schemaErrs := schemaErrors(requestError.Err)
+			if len(schemaErrs) == 0 {
+				params = append(params, errz.InvalidParam{Name: requestError.Parameter.Name, Reason: reason(requestError)})
+			}
+			for _, schemaError := range schemaErrs {
+				params = append(params, errz.InvalidParam{Name: requestError.Parameter.Name, Reason: schemaError.Reason})
+			}
+
+		case requestError.RequestBody != nil:
+			if requestError.Err == openapi3filter.ErrInvalidRequired {
+				return errz.New(errz.CodeInvalidRequestFormat, "The request body is required", err)
+			}
+			schemaErrs := schemaErrors(requestError.Err)
+			if len(schemaErrs) == 0 {
+				if requestError.Err == nil && strings.Contains(requestError.Reason, "Content-Type") {
+					return errz.New(errz.CodeUnsupportedMediaType, "Unsupported request content type", err)
+				}
+				return errz.New(errz.CodeInvalidRequestFormat, "Invalid request format", err)
+			}
+			for _, schemaError := range schemaErrs {
+				params = append(params, errz.InvalidParam{Name: paramName(schemaError.JSONPointer()), Reason: schemaError.Reason})
+			}
+
+		default:
+			params = append(params, errz.InvalidParam{Name: "request", Reason: reason(requestError)})
+		}
+	}
+
+	sort.SliceStable(params, func(a, b int) bool {
+		return params[a].Name < params[b].Name
+	})
+
+	return errz.New(
+		errz.CodeValidationFailed,
+		"Some request parameters are invalid, see invalid-params",
+		err,
+	).WithInvalidParams(params)
+}
+
+// flatten lists the errors of the multi errors, recursively
+func flatten(err error) []error {
+	// not errors.As, it would find the multi errors of the body inside the request errors
+	multi, ok := err.(openapi3.MultiError)
+	if !ok {
+		return []error{err}
+	}
+
+	var errs []error
+	for _, e := range multi {
+		errs = append(errs, flatten(e)...)
+	}
+	return errs
+}
+
+func schemaErrors(err error) []*openapi3.SchemaError {
+	if err == nil {
+		return nil
+	}
+
+	var schemaErrs []*openapi3.SchemaError
+	for _, e := range flatten(err) {
+		var schemaError *openapi3.SchemaError
+		if errors.As(e, &schemaError) {
+			schemaErrs = append(schemaErrs, schemaError)
+		}
+	}
+	return schemaErrs
+}
+
+func reason(requestError *openapi3filter.RequestError) string {
+	if requestError.Err == openapi3filter.ErrInvalidRequired {
+		return "is required"
+	}
+	if requestError.Err != nil {
+		return requestError.Err.Error()
+	}
+	return requestError.Reason
+}
+
+// paramName is the path of a value of the body, as named by the validation middleware, e.g. rules[0].variant
+func paramName(pointer []string) string {
+	var name strings.Builder
+	for _, segment := range pointer {
+		if _, err := strconv.Atoi(segment); err == nil {
+			name.WriteString("[" + segment + "]")
+			continue
+		}
+		if name.Len() > 0 {
+			name.WriteString(".")
+		}
+		name.WriteString(segment)
+	}
+	if name.Len() == 0 {
+		return "body"
+	}
+	return name.String()
+}
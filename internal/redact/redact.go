@@ -0,0 +1,334 @@
+// Package redact removes secrets and personal data from what we log.
+//
+// Values are redacted by key (JSON object keys, form fields, headers), matched case-insensitively
+// and ignoring '-' and '_' (so "api_key" matches "X-Api-Key" and "apiKey"),
+// and by value patterns (emails, card numbers, JWTs) anywhere in the text.
+// JSON and form bodies are parsed, redacted and encoded again, so the output stays well-formed.
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	// Redacted replaces the values of the redacted keys
+	Redacted = "[REDACTED]"
+
+	// BinaryContent replaces the bodies that are not text
+	BinaryContent = "[binary content]"
+
+	// limits applied to the values of JSON bodies too large for the size limit
+	truncatedStringSize = 64
+	truncatedArrayItems = 10
+)
+
+// DefaultKeys are always redacted
+var DefaultKeys = []string{
+	"password", "passwd", "secret", "token", "apikey", "credential",
+	"authorization", "cookie", "session",
+	"cardnumber", "cvv", "cvc", "ssn",
+}
+
+// Pattern redacts the values matching Regexp, as [REDACTED:Name]
+type Pattern struct {
+	Name   string
+	Regexp *regexp.Regexp
+	// Optional, the match is only redacted when it returns true
+	Check func(match string) bool
+}
+
+// DefaultPatterns are always redacted
+var DefaultPatterns = []Pattern{
+	{Name: "jwt", Regexp: regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)},
+	{Name: "email", Regexp: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)},
+	{Name: "card", Regexp: regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`), Check: luhn},
+}
+
+// Options of New, on top of DefaultKeys and DefaultPatterns
+type Options struct {
+	Keys []string
+	// Regular expressions, redacted as [REDACTED:custom]
+	Patterns []string
+}
+
+// Redactor redacts bodies and headers, it is safe for concurrent use
+type Redactor struct {
+	keys     []string
+	patterns []Pattern
+}
+
+func New(options Options) (*Redactor, error) {
+	r := &Redactor{
+		patterns: append([]Pattern{}, DefaultPatterns...),
+	}
+
+	for _, key := range append(append([]string{}, DefaultKeys...), options.Keys...) {
+		if key = normalizeKey(key); key != "" {
+			r.keys = append(r.keys, key)
+		}
+	}
+
+	for _, pattern := range options.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid redaction pattern %q", pattern)
+		}
+		r.patterns = append(r.patterns, Pattern{Name: "custom", Regexp: re})
+	}
+
+	return r, nil
+}
+
+func normalizeKey(key string) string {
+	return strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(strings.TrimSpace(key)))
+}
+
+// IsSensitiveKey tells if the values of the key are redacted
+func (r *Redactor) IsSensitiveKey(key string) bool {
+	key = normalizeKey(key)
+	for _, sensitive := range r.keys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// String redacts the patterns in s
+func (r *Redactor) String(s string) string {
+	for _, pattern := range r.patterns {
+		s = pattern.Regexp.ReplaceAllStringFunc(s, func(match string) string {
+			if pattern.Check != nil && !pattern.Check(match) {
+				return match
+			}
+			return "[REDACTED:" + pattern.Name + "]"
+		})
+	}
+	return s
+}
+
+// Body redacts a body by its content type, the result is at most limit bytes (0 for no limit).
+// Valid JSON stays valid JSON and forms stay valid forms, values are shortened to fit when needed.
+func (r *Redactor) Body(body []byte, contentType string, limit int) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	mediaType = strings.ToLower(mediaType)
+
+	switch {
+	case len(body) == 0:
+		return ""
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		if redacted, ok := r.jsonBody(body, limit); ok {
+			return redacted
+		}
+		// not valid json (e.g. cut by the client), redact the "key": value pairs found in it
+		return truncate(r.String(r.jsonPairs(string(body))), limit)
+	case mediaType == "application/x-www-form-urlencoded":
+		return r.formBody(body, limit)
+	case strings.HasPrefix(mediaType, "text/") || mediaType == "application/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return truncate(r.String(string(body)), limit)
+	default:
+		return BinaryContent
+	}
+}
+
+// Headers redacts the sensitive headers (Authorization, Cookie, ...) and the patterns in the others
+func (r *Redactor) Headers(headers http.Header) map[string]string {
+	redacted := make(map[string]string, len(headers))
+	for key, values := range headers {
+		if r.IsSensitiveKey(key) {
+			redacted[key] = Redacted
+			continue
+		}
+		redacted[key] = r.String(strings.Join(values, ", "))
+	}
+	return redacted
+}
+
+// URL is the path and the query of u, the values of the sensitive query parameters redacted
+// and the patterns redacted in the others
+func (r *Redactor) URL(u *url.URL) string {
+	query := u.Query()
+	for key, values := range query {
+		for index, value := range values {
+			if r.IsSensitiveKey(key) {
+				values[index] = Redacted
+			} else {
+				values[index] = r.String(value)
+			}
+		}
+		query[key] = values
+	}
+
+	redacted := url.URL{Path: u.Path, RawQuery: query.Encode()}
+	return redacted.String()
+}
+
+func (r *Redactor) jsonBody(body []byte, limit int) (string, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	// keep the numbers as they are
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return "", false
+	}
+
+	value = r.jsonValue(value)
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", false
+	}
+	if limit <= 0 || len(encoded) <= limit {
+		return string(encoded), true
+	}
+
+	// too large, keep the shape and shorten the values
+	encoded, err = json.Marshal(shorten(value))
+	if err == nil && len(encoded) <= limit {
+		return string(encoded), true
+	}
+
+	summary, _ := json.Marshal(map[string]any{"truncated": true, "size": len(body)})
+	return string(summary), true
+}
+
+func (r *Redactor) jsonValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if r.IsSensitiveKey(key) {
+				v[key] = Redacted
+				continue
+			}
+			v[key] = r.jsonValue(item)
+		}
+		return v
+	case []any:
+		for index, item := range v {
+			v[index] = r.jsonValue(item)
+		}
+		return v
+	case string:
+		return r.String(v)
+	default:
+		return v
+	}
+}
+
+// shorten cuts the long strings and arrays of a JSON value
+func shorten(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			v[key] = shorten(item)
+		}
+		return v
+	case []any:
+		if len(v) > truncatedArrayItems {
+			v = append(v[:truncatedArrayItems:truncatedArrayItems], fmt.Sprintf("... %d more", len(v)-truncatedArrayItems))
+		}
+		for index, item := range v {
+			v[index] = shorten(item)
+		}
+		return v
+	case string:
+		if len(v) > truncatedStringSize {
+			return strings.ToValidUTF8(v[:truncatedStringSize], "") + "... [truncated]"
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// jsonPair is a "key": value pair, the value being a string or a scalar
+var jsonPair = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"(\s*:\s*)("(?:[^"\\]|\\.)*"?|[^,{}\[\]\s]+)`)
+
+func (r *Redactor) jsonPairs(s string) string {
+	return jsonPair.ReplaceAllStringFunc(s, func(pair string) string {
+		groups := jsonPair.FindStringSubmatch(pair)
+		if !r.IsSensitiveKey(groups[1]) {
+			return pair
+		}
+		return `"` + groups[1] + `"` + groups[2] + `"` + Redacted + `"`
+	})
+}
+
+func (r *Redactor) formBody(body []byte, limit int) string {
+	// the invalid pairs are skipped, the others are still returned
+	values, _ := url.ParseQuery(string(body))
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var builder strings.Builder
+	for _, key := range keys {
+		for _, value := range values[key] {
+			if r.IsSensitiveKey(key) {
+				value = Redacted
+			} else {
+				value = r.String(value)
+			}
+
+			pair := url.QueryEscape(key) + "=" + url.QueryEscape(value)
+			if builder.Len() > 0 {
+				pair = "&" + pair
+			}
+
+			// stop at a pair boundary, the output stays a valid form
+			if limit > 0 && builder.Len()+len(pair) > limit {
+				return builder.String()
+			}
+			builder.WriteString(pair)
+		}
+	}
+
+	return builder.String()
+}
+
+func truncate(s string, limit int) string {
+	const marker = "... [truncated]"
+	if limit <= 0 || len(s) <= limit {
+		return s
+	}
+	if limit <= len(marker) {
+		return s[:limit]
+	}
+	return strings.ToValidUTF8(s[:limit-len(marker)], "") + marker
+}
+
+// luhn tells if the digits of s pass the Luhn checksum of card numbers
+func luhn(s string) bool {
+	sum, double, digits := 0, false, 0
+	for index := len(s) - 1; index >= 0; index-- {
+		c := s[index]
+		if c < '0' || c > '9' {
+			continue
+		}
+		digit := int(c - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+		digits++
+	}
+	return digits >= 13 && sum%10 == 0
+}
@@ -0,0 +1,61 @@
+package reporting
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/cockroachdb/errors"
+)
+
+// FileReporter appends the events to a file, one JSON per line, for development and tests:
+//
+//	tail -f errors.jsonl | jq .
+type FileReporter struct {
+	mu     sync.Mutex
+	writer io.Writer
+	closer io.Closer
+}
+
+// NewFileReporter appends to path, it is created when missing
+func NewFileReporter(path string) (*FileReporter, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open the error report file %s", path)
+	}
+
+	return &FileReporter{writer: file, closer: file}, nil
+}
+
+// NewWriterReporter writes the events to w, e.g. a bytes.Buffer in tests
+func NewWriterReporter(w io.Writer) *FileReporter {
+	return &FileReporter{writer: w}
+}
+
+func (f *FileReporter) Send(_ context.Context, event Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode the event")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	_, err = f.writer.Write(append(line, '\n'))
+	return errors.Wrap(err, "failed to write the event")
+}
+
+// Flush has nothing to wait for, the events are written by Send
+func (f *FileReporter) Flush(context.Context) error {
+	return nil
+}
+
+// Close closes the file
+func (f *FileReporter) Close() error {
+	if f.closer == nil {
+		return nil
+	}
+	return f.closer.Close()
+}
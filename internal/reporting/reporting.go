@@ -0,0 +1,294 @@
+// Package reporting sends the unhandled errors and panics to an error tracker.
+//
+// Reporter builds an Event from the error (its stack trace, the request, the user and the trace),
+// groups the events by fingerprint and rate limits each group, then hands them to an ErrorReporter:
+// SentryReporter in production, FileReporter (JSON lines) in development and tests.
+package reporting
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"golang-service-template/internal/redact"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	LevelError = "error"
+	// LevelFatal is the level of the panics
+	LevelFatal = "fatal"
+
+	// frames of the stack used for the fingerprint, from the innermost
+	fingerprintFrames = 5
+
+	// groups kept by the rate limit before the past ones are swept
+	maxGroups = 1024
+)
+
+// ErrorReporter sends the events to an error tracker
+type ErrorReporter interface {
+	Send(ctx context.Context, event Event) error
+	// Flush waits for the events being sent, on shutdown
+	Flush(ctx context.Context) error
+}
+
+// Event is a reported error
+type Event struct {
+	ID          string    `json:"id"`
+	Timestamp   time.Time `json:"timestamp"`
+	Level       string    `json:"level"`
+	Message     string    `json:"message"`
+	Type        string    `json:"type"`
+	Fingerprint string    `json:"fingerprint"`
+	// Events of the same fingerprint dropped by the rate limit since the last one sent
+	Suppressed int `json:"suppressed,omitempty"`
+
+	// From the innermost stack trace of the error, the outermost call first
+	Stack []Frame `json:"stack,omitempty"`
+
+	Request *Request `json:"request,omitempty"`
+	User    *User    `json:"user,omitempty"`
+	TraceID string   `json:"trace_id,omitempty"`
+	SpanID  string   `json:"span_id,omitempty"`
+
+	Service     string            `json:"service,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	Environment string            `json:"environment,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+}
+
+// Frame is a call of the stack trace
+type Frame struct {
+	Function string `json:"function"`
+	Module   string `json:"module,omitempty"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// Request is the request that failed, its headers are redacted by the caller
+type Request struct {
+	Method    string            `json:"method"`
+	URL       string            `json:"url"`
+	Route     string            `json:"route,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
+	ClientIP  string            `json:"client_ip,omitempty"`
+}
+
+// User is who made the failed request
+type User struct {
+	ID     string `json:"id,omitempty"`
+	Tenant string `json:"tenant,omitempty"`
+}
+
+// Options of New
+type Options struct {
+	Service     string
+	Release     string
+	Environment string
+
+	// Events sent per fingerprint per RateWindow, 0 for no limit
+	RateLimit  int
+	RateWindow time.Duration
+
+	// Optional, redacts the messages (database errors often carry the values)
+	Redactor *redact.Redactor
+
+	Logger zerolog.Logger
+}
+
+// Reporter reports the errors to an ErrorReporter, it is safe for concurrent use
+type Reporter struct {
+	sink    ErrorReporter
+	options Options
+	limiter *limiter
+}
+
+func New(sink ErrorReporter, options Options) *Reporter {
+	if options.RateWindow <= 0 {
+		options.RateWindow = time.Minute
+	}
+
+	return &Reporter{
+		sink:    sink,
+		options: options,
+		limiter: newLimiter(options.RateLimit, options.RateWindow),
+	}
+}
+
+// Report sends err, with the request when there is one, and returns the event id.
+// The id is empty when the event is dropped by the rate limit or could not be sent.
+func (r *Reporter) Report(ctx context.Context, err error, level string, request *Request, user *User) string {
+	if r == nil || err == nil {
+		return ""
+	}
+
+	event := NewEvent(err, level)
+	if r.options.Redactor != nil {
+		event.Message = r.options.Redactor.String(event.Message)
+	}
+	event.Request = request
+	event.User = user
+	event.Service = r.options.Service
+	event.Release = r.options.Release
+	event.Environment = r.options.Environment
+
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		event.TraceID = spanContext.TraceID().String()
+		event.SpanID = spanContext.SpanID().String()
+	}
+
+	allowed, suppressed := r.limiter.allow(event.Fingerprint, event.Timestamp)
+	if !allowed {
+		return ""
+	}
+	event.Suppressed = suppressed
+
+	if err := r.sink.Send(ctx, event); err != nil {
+		r.options.Logger.Error().Err(err).Str("fingerprint", event.Fingerprint).Msg("failed to report error")
+		return ""
+	}
+
+	return event.ID
+}
+
+// Flush waits for the events being sent
+func (r *Reporter) Flush(ctx context.Context) error {
+	if r == nil {
+		return nil
+	}
+	return r.sink.Flush(ctx)
+}
+
+// NewEvent describes err, without the request
+func NewEvent(err error, level string) Event {
+	event := Event{
+		ID:        strings.ReplaceAll(uuid.New().String(), "-", ""),
+		Timestamp: time.Now().UTC(),
+		Level:     level,
+		Message:   err.Error(),
+		Type:      fmt.Sprintf("%T", errors.UnwrapAll(err)),
+		Stack:     stackOf(err),
+	}
+	event.Fingerprint = fingerprint(event)
+
+	return event
+}
+
+// stackOf returns the innermost stack trace of the error chain, the closest to where it happened
+func stackOf(err error) []Frame {
+	var stack []Frame
+	for ; err != nil; err = errors.UnwrapOnce(err) {
+		reportable := errors.GetReportableStackTrace(err)
+		if reportable == nil || len(reportable.Frames) == 0 {
+			continue
+		}
+
+		stack = make([]Frame, 0, len(reportable.Frames))
+		for _, frame := range reportable.Frames {
+			stack = append(stack, Frame{
+				Function: frame.Function,
+				Module:   frame.Module,
+				File:     frame.AbsPath,
+				Line:     frame.Lineno,
+			})
+		}
+	}
+	return stack
+}
+
+var digits = regexp.MustCompile(`\d+`)
+
+// fingerprint groups the events of the same error: same type, raised from the same functions.
+// Line numbers are left out so a group survives unrelated changes of the file,
+// without a stack the message is used, with its numbers (ids, sizes) left out.
+func fingerprint(event Event) string {
+	hash := sha256.New()
+	hash.Write([]byte(event.Type))
+
+	if len(event.Stack) == 0 {
+		hash.Write([]byte(digits.ReplaceAllString(event.Message, "0")))
+	}
+
+	frames := 0
+	for index := len(event.Stack) - 1; index >= 0 && frames < fingerprintFrames; index-- {
+		frame := event.Stack[index]
+		// the panic machinery is the same for every panic
+		if frame.Module == "runtime" {
+			continue
+		}
+		hash.Write([]byte(frame.Module + "." + frame.Function))
+		frames++
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))[:16]
+}
+
+// limiter lets limit events of each fingerprint per window, and counts the others
+type limiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	groups map[string]*group
+}
+
+type group struct {
+	start      time.Time
+	count      int
+	suppressed int
+}
+
+func newLimiter(limit int, window time.Duration) *limiter {
+	return &limiter{limit: limit, window: window, groups: map[string]*group{}}
+}
+
+// allow tells if the event is sent, and how many of the group were dropped since the last one sent
+func (l *limiter) allow(fingerprint string, now time.Time) (bool, int) {
+	if l.limit <= 0 {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	g, ok := l.groups[fingerprint]
+	if !ok || now.Sub(g.start) >= l.window {
+		if !ok {
+			if len(l.groups) >= maxGroups {
+				l.sweep(now)
+			}
+			g = &group{}
+			l.groups[fingerprint] = g
+		}
+		g.start, g.count = now, 0
+	}
+
+	if g.count >= l.limit {
+		g.suppressed++
+		return false, 0
+	}
+
+	g.count++
+	suppressed := g.suppressed
+	g.suppressed = 0
+	return true, suppressed
+}
+
+// sweep forgets the groups of the past windows, so the map does not grow forever
+func (l *limiter) sweep(now time.Time) {
+	for fingerprint, g := range l.groups {
+		if now.Sub(g.start) >= l.window {
+			delete(l.groups, fingerprint)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package reporting
+
+import (
+	"context"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/getsentry/sentry-go"
+)
+
+// SentryReporter sends the events to Sentry, or anything speaking its protocol (e.g. GlitchTip).
+// The events are sent in the background, Flush waits for them.
+type SentryReporter struct {
+	client *sentry.Client
+	// the frames of this module are marked in app
+	module string
+}
+
+// NewSentryReporter sends to the project of the DSN, e.g. https://key@o0.ingest.sentry.io/0
+func NewSentryReporter(dsn string, httpClient *http.Client) (*SentryReporter, error) {
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		Dsn:        dsn,
+		HTTPClient: httpClient,
+		// the events are already grouped and rate limited, and the stack is from the error
+		Integrations: func([]sentry.Integration) []sentry.Integration { return nil },
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid sentry dsn")
+	}
+
+	module := ""
+	if info, ok := debug.ReadBuildInfo(); ok {
+		module = info.Main.Path
+	}
+
+	return &SentryReporter{client: client, module: module}, nil
+}
+
+func (s *SentryReporter) Send(_ context.Context, event Event) error {
+	if s.client.CaptureEvent(s.sentryEvent(event), nil, nil) == nil {
+		return errors.New("sentry dropped the event")
+	}
+	return nil
+}
+
+func (s *SentryReporter) Flush(ctx context.Context) error {
+	timeout := 5 * time.Second
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+
+	if !s.client.Flush(timeout) {
+		return errors.New("timed out sending the events to sentry")
+	}
+	return nil
+}
+
+func (s *SentryReporter) sentryEvent(event Event) *sentry.Event {
+	sentryEvent := sentry.NewEvent()
+	sentryEvent.EventID = sentry.EventID(event.ID)
+	sentryEvent.Timestamp = event.Timestamp
+	sentryEvent.Level = sentry.Level(event.Level)
+	sentryEvent.Message = event.Message
+	sentryEvent.Fingerprint = []string{event.Fingerprint}
+	sentryEvent.Environment = event.Environment
+	sentryEvent.Release = event.Release
+	sentryEvent.ServerName = event.Service
+	sentryEvent.Platform = "go"
+
+	frames := make([]sentry.Frame, 0, len(event.Stack))
+	for _, frame := range event.Stack {
+		frames = append(frames, sentry.Frame{
+			Function: frame.Function,
+			Module:   frame.Module,
+			AbsPath:  frame.File,
+			Lineno:   frame.Line,
+			InApp:    s.module != "" && strings.HasPrefix(frame.Module, s.module),
+		})
+	}
+
+	exception := sentry.Exception{Type: event.Type, Value: event.Message}
+	if len(frames) > 0 {
+		exception.Stacktrace = &sentry.Stacktrace{Frames: frames}
+	}
+	sentryEvent.Exception = []sentry.Exception{exception}
+
+	for key, value := range event.Tags {
+		sentryEvent.Tags[key] = value
+	}
+	if event.Suppressed > 0 {
+		sentryEvent.Extra["suppressed"] = event.Suppressed
+	}
+
+	if event.Request != nil {
+		sentryEvent.Request = &sentry.Request{
+			URL:     event.Request.URL,
+			Method:  event.Request.Method,
+			Headers: event.Request.Headers,
+		}
+		sentryEvent.Transaction = event.Request.Method + " " + event.Request.Route
+		if event.Request.RequestID != "" {
+			sentryEvent.Tags["request_id"] = event.Request.RequestID
+		}
+	}
+
+	if event.User != nil {
+		sentryEvent.User = sentry.User{ID: event.User.ID, Segment: event.User.Tenant}
+		if event.Request != nil {
+			sentryEvent.User.IPAddress = event.Request.ClientIP
+		}
+		if event.User.Tenant != "" {
+			sentryEvent.Tags["tenant"] = event.User.Tenant
+		}
+	}
+
+	if event.TraceID != "" {
+		sentryEvent.Contexts["trace"] = sentry.Context{"trace_id": event.TraceID, "span_id": event.SpanID}
+	}
+
+	return sentryEvent
+}
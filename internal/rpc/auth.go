@@ -0,0 +1,36 @@
+package rpc
+
+import (
+	"context"
+	"slices"
+
+	"golang-service-template/internal/middleware"
+
+	"connectrpc.com/connect"
+	jwtmiddleware "github.com/auth0/go-jwt-middleware/v2"
+	"github.com/auth0/go-jwt-middleware/v2/validator"
+)
+
+// AuthInterceptor validates the bearer token of the calls of the procedures, e.g. taskv1connect.TaskServiceListMyTasksProcedure,
+// with the validator of ValidateJWTMiddleware. The claims are put in the context where the http routes have them,
+// with the feature flags target. The other procedures need no token. It is the last interceptor.
+func AuthInterceptor(jwtValidator *validator.Validator, procedures ...string) connect.Interceptor {
+	return Around(func(ctx context.Context, call Call, next func(ctx context.Context) error) error {
+		if !slices.Contains(procedures, call.Spec.Procedure) {
+			return next(ctx)
+		}
+
+		claims, err := middleware.ValidateBearerToken(ctx, jwtValidator, call.RequestHeader.Get("Authorization"))
+		if err != nil {
+			return err
+		}
+
+		return next(middleware.WithClaims(ctx, claims))
+	})
+}
+
+// Claims are the claims of the token of the call, for the procedures of AuthInterceptor
+func Claims(ctx context.Context) (*validator.ValidatedClaims, bool) {
+	claims, ok := ctx.Value(jwtmiddleware.ContextKey{}).(*validator.ValidatedClaims)
+	return claims, ok
+}
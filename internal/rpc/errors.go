@@ -0,0 +1,203 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"golang-service-template/internal/errz"
+	"golang-service-template/internal/redact"
+	"golang-service-template/internal/reporting"
+	"golang-service-template/internal/telemetry"
+
+	"connectrpc.com/connect"
+	"github.com/cockroachdb/errors"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// ErrorInterceptor turns the errors of the handlers into Connect errors, as ErrorRendererMiddleware renders the problems,
+// and reports the server errors and the panics like ReportErrorsMiddleware.
+// domain is the domain of the google.rpc.ErrorInfo of the errors, e.g. the name of the service.
+// It goes right after LoggerInterceptor.
+func ErrorInterceptor(reporter *reporting.Reporter, redactor *redact.Redactor, domain string) connect.Interceptor {
+	return Around(func(ctx context.Context, call Call, next func(ctx context.Context) error) (err error) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			// the client went away, net/http handles it
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			err = panicError(recovered)
+			eventID := reportError(ctx, call, reporter, redactor, err, reporting.LevelFatal)
+
+			telemetry.Logger(ctx).Error().
+				Err(err).
+				Str("event_id", eventID).
+				Msg("panic recovered")
+
+			err = connectError(ctx, call, err, domain)
+		}()
+
+		err = next(ctx)
+		if err == nil {
+			return nil
+		}
+
+		if errz.StatusCode(err) >= http.StatusInternalServerError && !isConnectError(err) {
+			reportError(ctx, call, reporter, redactor, err, reporting.LevelError)
+		}
+
+		return connectError(ctx, call, err, domain)
+	})
+}
+
+// panicError keeps the stack of the panic, from the frame that panicked
+func panicError(recovered any) error {
+	// skips panicError and the deferred function
+	const depth = 2
+
+	if err, ok := recovered.(error); ok {
+		return errors.WrapWithDepth(depth, err, "panic")
+	}
+	return errors.NewWithDepthf(depth, "panic: %v", recovered)
+}
+
+func reportError(ctx context.Context, call Call, reporter *reporting.Reporter, redactor *redact.Redactor, err error, level string) string {
+	request := &reporting.Request{
+		Method:    call.HTTPMethod,
+		URL:       call.Spec.Procedure,
+		Route:     call.Spec.Procedure,
+		Headers:   redactor.Headers(call.RequestHeader),
+		RequestID: RequestID(ctx),
+		ClientIP:  call.Peer.Addr,
+	}
+
+	return reporter.Report(ctx, err, level, request, nil)
+}
+
+func isConnectError(err error) bool {
+	var connectErr *connect.Error
+	return errors.As(err, &connectErr)
+}
+
+// problemError is the message of the problem sent to the client, the error stays the cause for the interceptors
+type problemError struct {
+	message string
+	cause   error
+}
+
+func (e *problemError) Error() string {
+	return e.message
+}
+
+func (e *problemError) Unwrap() error {
+	return e.cause
+}
+
+// connectError describes err like errz.NewProblem, in the language of the Accept-Language header.
+// The message is the detail of the problem, else its title. The details are
+// a google.rpc.ErrorInfo whose reason is the errz code (its metadata are the details of the problem and the trace_id),
+// a google.rpc.BadRequest with the invalid-params, a google.rpc.RetryInfo when it is retryable
+// and a google.rpc.RequestInfo with the request id.
+// The errors of connect itself, and the ones of the contexts, are left as is.
+func connectError(ctx context.Context, call Call, err error, domain string) error {
+	if isConnectError(err) {
+		return err
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return connect.NewError(connect.CodeOf(err), err)
+	}
+
+	problem := errz.NewProblem(err, errz.NegotiateLanguage(call.RequestHeader.Get("Accept-Language")))
+
+	message := problem.Detail
+	if message == "" {
+		message = problem.Title
+	}
+	connectErr := connect.NewError(CodeOf(problem), &problemError{message: message, cause: err})
+
+	metadata := map[string]string{}
+	for key, value := range problem.Details {
+		metadata[key] = value
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+		metadata["trace_id"] = spanContext.TraceID().String()
+	}
+	details := []proto.Message{
+		&errdetails.ErrorInfo{Reason: problem.Code, Domain: domain, Metadata: metadata},
+	}
+
+	if len(problem.InvalidParams) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, param := range problem.InvalidParams {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       param.Name,
+				Description: param.Reason,
+			})
+		}
+		details = append(details, badRequest)
+	}
+
+	if problem.Retryable {
+		// the Retry-After of the problems
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(time.Second)})
+	}
+
+	if requestID := RequestID(ctx); requestID != "" {
+		details = append(details, &errdetails.RequestInfo{RequestId: requestID})
+	}
+
+	for _, message := range details {
+		if detail, err := connect.NewErrorDetail(message); err == nil {
+			connectErr.AddDetail(detail)
+		}
+	}
+
+	return connectErr
+}
+
+// codesByErrz are the codes of the errz codes more precise than the code of their status
+var codesByErrz = map[string]connect.Code{
+	errz.CodeConcurrentUpdate:   connect.CodeAborted,
+	errz.CodeReferenceViolation: connect.CodeFailedPrecondition,
+}
+
+// codesByStatus follow the mapping of the gRPC gateways
+var codesByStatus = map[int]connect.Code{
+	http.StatusBadRequest:            connect.CodeInvalidArgument,
+	http.StatusUnauthorized:          connect.CodeUnauthenticated,
+	http.StatusForbidden:             connect.CodePermissionDenied,
+	http.StatusNotFound:              connect.CodeNotFound,
+	http.StatusMethodNotAllowed:      connect.CodeUnimplemented,
+	http.StatusRequestTimeout:        connect.CodeDeadlineExceeded,
+	http.StatusConflict:              connect.CodeAlreadyExists,
+	http.StatusPreconditionFailed:    connect.CodeFailedPrecondition,
+	http.StatusRequestEntityTooLarge: connect.CodeResourceExhausted,
+	http.StatusUnsupportedMediaType:  connect.CodeInvalidArgument,
+	http.StatusUnprocessableEntity:   connect.CodeFailedPrecondition,
+	http.StatusTooManyRequests:       connect.CodeResourceExhausted,
+	http.StatusNotImplemented:        connect.CodeUnimplemented,
+	http.StatusServiceUnavailable:    connect.CodeUnavailable,
+	http.StatusGatewayTimeout:        connect.CodeDeadlineExceeded,
+}
+
+// CodeOf is the Connect code of a problem, from its errz code, else from its status
+func CodeOf(problem errz.Problem) connect.Code {
+	if code, ok := codesByErrz[problem.Code]; ok {
+		return code
+	}
+	if code, ok := codesByStatus[problem.Status]; ok {
+		return code
+	}
+	if problem.Status >= http.StatusInternalServerError {
+		return connect.CodeInternal
+	}
+	return connect.CodeUnknown
+}
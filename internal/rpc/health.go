@@ -0,0 +1,131 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"golang-service-template/internal/rpc/gen/task/v1/taskv1connect"
+	"golang-service-template/internal/service"
+
+	"connectrpc.com/connect"
+	"github.com/cockroachdb/errors"
+	healthv1 "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// HealthServiceName is the gRPC health service, grpc.health.v1.Health
+const HealthServiceName = "grpc.health.v1.Health"
+
+// HealthServer implements the gRPC health service with the readiness check, for the gRPC load balancers and probes.
+// The services are the empty name, the whole server, and the RPC services. They are all serving when the service is ready.
+type HealthServer struct {
+	healthService service.HealthService
+	services      []string
+	// how often Watch runs the readiness check
+	interval time.Duration
+	// closed by Shutdown, to end the Watch streams
+	done     chan struct{}
+	shutdown sync.Once
+}
+
+func NewHealthServer(healthService service.HealthService, interval time.Duration) *HealthServer {
+	return &HealthServer{
+		healthService: healthService,
+		services:      []string{"", taskv1connect.TaskServiceName},
+		interval:      interval,
+		done:          make(chan struct{}),
+	}
+}
+
+// Shutdown ends the Watch streams with NOT_SERVING, the http server waits for them otherwise
+func (s *HealthServer) Shutdown() {
+	s.shutdown.Do(func() {
+		close(s.done)
+	})
+}
+
+// Handler serves Check, List and Watch
+func (s *HealthServer) Handler(options ...connect.HandlerOption) (string, http.Handler) {
+	methods := healthv1.File_grpc_health_v1_health_proto.Services().ByName("Health").Methods()
+	mux := http.NewServeMux()
+
+	mux.Handle(healthv1.Health_Check_FullMethodName, connect.NewUnaryHandler(
+		healthv1.Health_Check_FullMethodName,
+		s.Check,
+		connect.WithSchema(methods.ByName("Check")),
+		connect.WithHandlerOptions(options...),
+	))
+	mux.Handle(healthv1.Health_List_FullMethodName, connect.NewUnaryHandler(
+		healthv1.Health_List_FullMethodName,
+		s.List,
+		connect.WithSchema(methods.ByName("List")),
+		connect.WithHandlerOptions(options...),
+	))
+	mux.Handle(healthv1.Health_Watch_FullMethodName, connect.NewServerStreamHandler(
+		healthv1.Health_Watch_FullMethodName,
+		s.Watch,
+		connect.WithSchema(methods.ByName("Watch")),
+		connect.WithHandlerOptions(options...),
+	))
+
+	return "/" + HealthServiceName + "/", mux
+}
+
+// Check is the status of a service, NotFound for the unknown services
+func (s *HealthServer) Check(ctx context.Context, req *connect.Request[healthv1.HealthCheckRequest]) (*connect.Response[healthv1.HealthCheckResponse], error) {
+	if !slices.Contains(s.services, req.Msg.GetService()) {
+		return nil, connect.NewError(connect.CodeNotFound, errors.New("unknown service"))
+	}
+
+	return connect.NewResponse(&healthv1.HealthCheckResponse{Status: s.status(ctx)}), nil
+}
+
+// List is the status of every service
+func (s *HealthServer) List(ctx context.Context, req *connect.Request[healthv1.HealthListRequest]) (*connect.Response[healthv1.HealthListResponse], error) {
+	status := s.status(ctx)
+
+	statuses := map[string]*healthv1.HealthCheckResponse{}
+	for _, name := range s.services {
+		statuses[name] = &healthv1.HealthCheckResponse{Status: status}
+	}
+
+	return connect.NewResponse(&healthv1.HealthListResponse{Statuses: statuses}), nil
+}
+
+// Watch sends the status of the service, then every change of it, SERVICE_UNKNOWN for the unknown services
+func (s *HealthServer) Watch(ctx context.Context, req *connect.Request[healthv1.HealthCheckRequest], stream *connect.ServerStream[healthv1.HealthCheckResponse]) error {
+	if !slices.Contains(s.services, req.Msg.GetService()) {
+		return stream.Send(&healthv1.HealthCheckResponse{Status: healthv1.HealthCheckResponse_SERVICE_UNKNOWN})
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	last := healthv1.HealthCheckResponse_UNKNOWN
+	for {
+		if status := s.status(ctx); status != last {
+			if err := stream.Send(&healthv1.HealthCheckResponse{Status: status}); err != nil {
+				return err
+			}
+			last = status
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.done:
+			return stream.Send(&healthv1.HealthCheckResponse{Status: healthv1.HealthCheckResponse_NOT_SERVING})
+		case <-ticker.C:
+		}
+	}
+}
+
+// status is SERVING when the readiness check passes
+func (s *HealthServer) status(ctx context.Context) healthv1.HealthCheckResponse_ServingStatus {
+	if _, err := s.healthService.ReadinessCheck(ctx); err != nil {
+		return healthv1.HealthCheckResponse_NOT_SERVING
+	}
+	return healthv1.HealthCheckResponse_SERVING
+}
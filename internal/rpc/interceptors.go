@@ -0,0 +1,261 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang-service-template/internal/telemetry"
+
+	"connectrpc.com/connect"
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Call is a call of a service, unary or streaming, as seen by the interceptors
+type Call struct {
+	Spec connect.Spec
+	Peer connect.Peer
+	// POST, or GET for the Connect calls of the procedures without side effects
+	HTTPMethod    string
+	RequestHeader http.Header
+	// Sent with the response, or with the error
+	ResponseHeader http.Header
+}
+
+// Service and Method of the procedure, e.g. task.v1.TaskService and GetTask
+func (call Call) Service() string {
+	service, _, _ := strings.Cut(strings.TrimPrefix(call.Spec.Procedure, "/"), "/")
+	return service
+}
+
+func (call Call) Method() string {
+	_, method, _ := strings.Cut(strings.TrimPrefix(call.Spec.Procedure, "/"), "/")
+	return method
+}
+
+// AroundFunc is an interceptor of the handlers, next runs the rest of the chain
+type AroundFunc func(ctx context.Context, call Call, next func(ctx context.Context) error) error
+
+// Around wraps the unary and the streaming handlers with the same function, the clients are left alone
+func Around(around AroundFunc) connect.Interceptor {
+	return aroundInterceptor{around: around}
+}
+
+type aroundInterceptor struct {
+	around AroundFunc
+}
+
+func (i aroundInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if req.Spec().IsClient {
+			return next(ctx, req)
+		}
+
+		call := Call{
+			Spec:           req.Spec(),
+			Peer:           req.Peer(),
+			HTTPMethod:     req.HTTPMethod(),
+			RequestHeader:  req.Header(),
+			ResponseHeader: http.Header{},
+		}
+
+		var res connect.AnyResponse
+		err := i.around(ctx, call, func(ctx context.Context) error {
+			var err error
+			res, err = next(ctx, req)
+			return err
+		})
+
+		// the headers of a unary error are its metadata
+		var connectError *connect.Error
+		if err != nil && errors.As(err, &connectError) {
+			mergeHeader(connectError.Meta(), call.ResponseHeader)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		mergeHeader(res.Header(), call.ResponseHeader)
+		return res, nil
+	}
+}
+
+func (i aroundInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (i aroundInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		// the response headers are sent with the first message
+		call := Call{
+			Spec:           conn.Spec(),
+			Peer:           conn.Peer(),
+			HTTPMethod:     http.MethodPost,
+			RequestHeader:  conn.RequestHeader(),
+			ResponseHeader: conn.ResponseHeader(),
+		}
+
+		return i.around(ctx, call, func(ctx context.Context) error {
+			return next(ctx, conn)
+		})
+	}
+}
+
+func mergeHeader(into, from http.Header) {
+	for key, values := range from {
+		into[key] = append(into[key], values...)
+	}
+}
+
+type requestIDKey struct{}
+
+// RequestID is the id of the call, set by RequestIDInterceptor
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// RequestIDInterceptor gives every call the X-Request-Id of the caller, or a new one, sent back in the response headers.
+// It is the first interceptor.
+func RequestIDInterceptor() connect.Interceptor {
+	return Around(func(ctx context.Context, call Call, next func(ctx context.Context) error) error {
+		requestID := call.RequestHeader.Get(echo.HeaderXRequestID)
+		if requestID == "" {
+			requestID = uuid.New().String()
+		}
+		call.ResponseHeader.Set(echo.HeaderXRequestID, requestID)
+
+		return next(context.WithValue(ctx, requestIDKey{}, requestID))
+	})
+}
+
+// isHealthCheck is true for the calls of the health service, they are neither traced nor logged
+func isHealthCheck(call Call) bool {
+	return call.Service() == HealthServiceName
+}
+
+// TelemetryInterceptor traces the calls and records their metrics, following the OpenTelemetry RPC server conventions:
+// the incoming traceparent is extracted, the span is a server span named "{service}/{method}".
+// It goes right after RequestIDInterceptor.
+func TelemetryInterceptor(tel *telemetry.Telemetry) connect.Interceptor {
+	return Around(func(ctx context.Context, call Call, next func(ctx context.Context) error) error {
+		if tel == nil || isHealthCheck(call) {
+			return next(ctx)
+		}
+
+		start := time.Now()
+
+		// Continue the trace of the caller, if any
+		ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(call.RequestHeader))
+
+		system := semconv.RPCSystemConnectRPC
+		if call.Peer.Protocol == connect.ProtocolGRPC || call.Peer.Protocol == connect.ProtocolGRPCWeb {
+			system = semconv.RPCSystemGRPC
+		}
+
+		ctx, span := tel.StartSpan(ctx, call.Service()+"/"+call.Method(), trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				system,
+				semconv.RPCService(call.Service()),
+				semconv.RPCMethod(call.Method()),
+				semconv.NetworkPeerAddress(call.Peer.Addr),
+				attribute.String("request.id", RequestID(ctx)),
+			))
+		defer span.End()
+
+		err := next(ctx)
+
+		code := "ok"
+		if err != nil {
+			code = connect.CodeOf(err).String()
+		}
+
+		if system == semconv.RPCSystemGRPC {
+			grpcCode := 0
+			if err != nil {
+				grpcCode = int(connect.CodeOf(err))
+			}
+			span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(grpcCode))
+		} else if err != nil {
+			span.SetAttributes(semconv.RPCConnectRPCErrorCodeKey.String(code))
+		}
+
+		// For server spans only the codes of the server's fault are errors
+		if err != nil && isServerError(connect.CodeOf(err)) {
+			tel.RecordError(ctx, err)
+			span.SetStatus(codes.Error, code)
+		}
+
+		tel.RecordRPCRequest(ctx, call.Service(), call.Method(), code, start)
+
+		return err
+	})
+}
+
+func isServerError(code connect.Code) bool {
+	switch code {
+	case connect.CodeUnknown, connect.CodeDeadlineExceeded, connect.CodeUnimplemented,
+		connect.CodeInternal, connect.CodeUnavailable, connect.CodeDataLoss:
+		return true
+	}
+	return false
+}
+
+// LoggerInterceptor puts the request scoped logger in the context, for telemetry.Logger(ctx),
+// and logs the calls like LoggerMiddleware, without the bodies. It goes right after TelemetryInterceptor.
+func LoggerInterceptor(logger zerolog.Logger) connect.Interceptor {
+	return Around(func(ctx context.Context, call Call, next func(ctx context.Context) error) error {
+		requestLogger := logger.With().
+			Str("request_id", RequestID(ctx)).
+			Logger()
+		ctx = requestLogger.WithContext(ctx)
+
+		if isHealthCheck(call) {
+			return next(ctx)
+		}
+
+		requestLogger.Debug().
+			Ctx(ctx).
+			Str("procedure", call.Spec.Procedure).
+			Str("protocol", call.Peer.Protocol).
+			Str("remote_ip", call.Peer.Addr).
+			Str("user_agent", call.RequestHeader.Get("User-Agent")).
+			Msg("rpc received")
+
+		start := time.Now()
+		err := next(ctx)
+		duration := time.Since(start)
+
+		code := "ok"
+		lvl := zerolog.InfoLevel
+		if err != nil {
+			code = connect.CodeOf(err).String()
+			lvl = zerolog.ErrorLevel
+		}
+
+		requestLogger.WithLevel(lvl).
+			Ctx(ctx).
+			Str("procedure", call.Spec.Procedure).
+			Str("protocol", call.Peer.Protocol).
+			Str("code", code).
+			Dur("latency", duration).
+			Str("latency_human", duration.String()).
+			Str("remote_ip", call.Peer.Addr).
+			Str("user_agent", call.RequestHeader.Get("User-Agent")).
+			Stack().
+			Err(err).
+			Msg("rpc processed")
+
+		return err
+	})
+}
@@ -0,0 +1,76 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync/atomic"
+
+	"golang-service-template/internal/settings"
+
+	"connectrpc.com/connect"
+	"github.com/cockroachdb/errors"
+	"github.com/labstack/echo/v4"
+	echo_middleware "github.com/labstack/echo/v4/middleware"
+	"golang.org/x/time/rate"
+)
+
+// RateLimitInterceptor limits the calls per client IP with the rate limit of the runtime settings, like RateLimitMiddleware:
+// the RPC services are served before the middlewares of echo. The counters are kept in memory, per instance,
+// apart from the ones of the http routes, and reset when the rate limit changes.
+// The health checks are not limited. It goes right after ErrorInterceptor, so the rejected calls are logged.
+func RateLimitInterceptor(runtimeSettings *settings.RuntimeSettings) connect.Interceptor {
+	var (
+		// nil when the rate limit is disabled
+		current atomic.Pointer[echo_middleware.RateLimiterMemoryStore]
+		built   *settings.RateLimit
+	)
+
+	// subscribers are called one at a time, built is not shared
+	runtimeSettings.Subscribe(func(s settings.Settings) {
+		if built != nil && *built == s.RateLimit {
+			return
+		}
+		built = &s.RateLimit
+
+		if s.RateLimit.RequestsPerSecond <= 0 {
+			current.Store(nil)
+			return
+		}
+		current.Store(echo_middleware.NewRateLimiterMemoryStoreWithConfig(echo_middleware.RateLimiterMemoryStoreConfig{
+			Rate:  rate.Limit(s.RateLimit.RequestsPerSecond),
+			Burst: s.RateLimit.Burst,
+		}))
+	})
+
+	return Around(func(ctx context.Context, call Call, next func(ctx context.Context) error) error {
+		store := current.Load()
+		if store == nil || isHealthCheck(call) {
+			return next(ctx)
+		}
+
+		if allowed, _ := store.Allow(clientIP(call)); !allowed {
+			return connect.NewError(connect.CodeResourceExhausted, errors.New("rate limit exceeded"))
+		}
+
+		return next(ctx)
+	})
+}
+
+// clientIP is the IP of the caller as echo's RealIP has it for the http routes:
+// the first X-Forwarded-For address, X-Real-IP, or the address of the peer
+func clientIP(call Call) string {
+	if forwardedFor := call.RequestHeader.Get(echo.HeaderXForwardedFor); forwardedFor != "" {
+		ip, _, _ := strings.Cut(forwardedFor, ",")
+		return strings.TrimSpace(ip)
+	}
+	if realIP := call.RequestHeader.Get(echo.HeaderXRealIP); realIP != "" {
+		return realIP
+	}
+
+	host, _, err := net.SplitHostPort(call.Peer.Addr)
+	if err != nil {
+		return call.Peer.Addr
+	}
+	return host
+}
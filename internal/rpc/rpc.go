@@ -0,0 +1,77 @@
+// Package rpc serves the task operations over Connect, gRPC and gRPC-Web, for the backend services
+// that would rather call typed RPCs than the JSON API. The services are defined in proto/,
+// the code in gen/ is generated from them by `buf generate`.
+//
+// The services reuse service.TaskService, and the interceptors mirror the http middlewares:
+// the request id, the telemetry, the logger, the errz errors (as Connect codes), the rate limit and the JWT auth.
+// The gRPC health (grpc.health.v1.Health) and reflection services are served too.
+package rpc
+
+import (
+	"net/http"
+	"strings"
+
+	"golang-service-template/internal/rpc/gen/task/v1/taskv1connect"
+
+	"connectrpc.com/connect"
+	"connectrpc.com/grpcreflect"
+)
+
+// maxMessageSize is the largest message read or sent, the body limit of the http routes (BodyLimit("1M"))
+const maxMessageSize = 1 << 20
+
+// Server is the http handler of the RPC services, mounted on the http server
+type Server struct {
+	mux    *http.ServeMux
+	health *HealthServer
+	// the paths of the services, e.g. /task.v1.TaskService/
+	prefixes []string
+}
+
+// NewServer serves the services, every call going through the interceptors in order.
+// The services are served before the middlewares of echo, the messages are limited to maxMessageSize here.
+// The reflection lists the services, for grpcurl and the like, when reflection is true.
+func NewServer(tasks *TaskServer, health *HealthServer, reflection bool, interceptors ...connect.Interceptor) *Server {
+	server := &Server{mux: http.NewServeMux(), health: health}
+	options := connect.WithHandlerOptions(
+		connect.WithInterceptors(interceptors...),
+		connect.WithReadMaxBytes(maxMessageSize),
+		connect.WithSendMaxBytes(maxMessageSize),
+	)
+
+	server.handle(taskv1connect.NewTaskServiceHandler(tasks, options))
+	server.handle(health.Handler(options))
+
+	if reflection {
+		reflector := grpcreflect.NewStaticReflector(taskv1connect.TaskServiceName, HealthServiceName)
+		server.handle(grpcreflect.NewHandlerV1(reflector))
+		// grpcurl and most of the tools still ask the alpha version
+		server.handle(grpcreflect.NewHandlerV1Alpha(reflector))
+	}
+
+	return server
+}
+
+func (s *Server) handle(prefix string, handler http.Handler) {
+	s.prefixes = append(s.prefixes, prefix)
+	s.mux.Handle(prefix, handler)
+}
+
+// Handles is true when the request is a call of one of the services
+func (s *Server) Handles(req *http.Request) bool {
+	for _, prefix := range s.prefixes {
+		if strings.HasPrefix(req.URL.Path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.mux.ServeHTTP(w, req)
+}
+
+// Shutdown ends the streams that would keep the http server from stopping, e.g. the health Watch
+func (s *Server) Shutdown() {
+	s.health.Shutdown()
+}
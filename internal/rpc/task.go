@@ -0,0 +1,150 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+
+	"golang-service-template/internal/dao/model"
+	"golang-service-template/internal/errz"
+	"golang-service-template/internal/handler"
+	"golang-service-template/internal/middleware"
+	taskv1 "golang-service-template/internal/rpc/gen/task/v1"
+	"golang-service-template/internal/rpc/gen/task/v1/taskv1connect"
+	"golang-service-template/internal/service"
+	"golang-service-template/internal/validation"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// TaskServer implements the TaskService of task.proto with service.TaskService.
+// The requests are validated with the rules of the http requests (e.g. handler.CreateTaskRequest).
+type TaskServer struct {
+	taskService service.TaskService
+	validator   *validation.Validator
+}
+
+var _ taskv1connect.TaskServiceHandler = (*TaskServer)(nil)
+
+func NewTaskServer(taskService service.TaskService, validator *validation.Validator) *TaskServer {
+	return &TaskServer{
+		taskService: taskService,
+		validator:   validator,
+	}
+}
+
+// validate validates req like middleware.ValidateRequest, the reasons in the language of the Accept-Language header
+func (s *TaskServer) validate(header http.Header, req any) error {
+	language := errz.NegotiateLanguage(header.Get("Accept-Language"))
+	return middleware.ValidateStruct(s.validator, s.validator.Translator(language), req)
+}
+
+// CreateTask implements taskv1connect.TaskServiceHandler.
+func (s *TaskServer) CreateTask(ctx context.Context, req *connect.Request[taskv1.CreateTaskRequest]) (*connect.Response[taskv1.CreateTaskResponse], error) {
+	request := handler.CreateTaskRequest{Description: req.Msg.GetDescription()}
+	if err := s.validate(req.Header(), request); err != nil {
+		return nil, err
+	}
+
+	task, err := s.taskService.Create(ctx, model.Task{Description: request.Description})
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&taskv1.CreateTaskResponse{Task: toProto(task)}), nil
+}
+
+// GetTask implements taskv1connect.TaskServiceHandler.
+func (s *TaskServer) GetTask(ctx context.Context, req *connect.Request[taskv1.GetTaskRequest]) (*connect.Response[taskv1.GetTaskResponse], error) {
+	path := handler.TaskPath{ID: req.Msg.GetId()}
+	if err := s.validate(req.Header(), path); err != nil {
+		return nil, err
+	}
+
+	task, err := s.taskService.Get(ctx, path.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&taskv1.GetTaskResponse{Task: toProto(task)}), nil
+}
+
+// ListTasks implements taskv1connect.TaskServiceHandler.
+func (s *TaskServer) ListTasks(ctx context.Context, req *connect.Request[taskv1.ListTasksRequest]) (*connect.Response[taskv1.ListTasksResponse], error) {
+	tasks, err := s.taskService.Find(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&taskv1.ListTasksResponse{Tasks: toProtos(tasks)}), nil
+}
+
+// ListMyTasks implements taskv1connect.TaskServiceHandler, it needs the AuthInterceptor.
+func (s *TaskServer) ListMyTasks(ctx context.Context, req *connect.Request[taskv1.ListMyTasksRequest]) (*connect.Response[taskv1.ListMyTasksResponse], error) {
+	claims, ok := Claims(ctx)
+	if !ok {
+		return nil, errz.New(errz.CodeUnauthorized, "a bearer token is required", nil)
+	}
+
+	tasks, err := s.taskService.FindByUserId(ctx, claims.RegisteredClaims.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&taskv1.ListMyTasksResponse{Tasks: toProtos(tasks)}), nil
+}
+
+// UpdateTask implements taskv1connect.TaskServiceHandler.
+func (s *TaskServer) UpdateTask(ctx context.Context, req *connect.Request[taskv1.UpdateTaskRequest]) (*connect.Response[taskv1.UpdateTaskResponse], error) {
+	request := handler.UpdateTaskRequest{ID: req.Msg.GetId(), Description: req.Msg.GetDescription()}
+	if err := s.validate(req.Header(), request); err != nil {
+		return nil, err
+	}
+
+	task, err := s.taskService.Update(ctx, request.ID, map[string]any{
+		"description": request.Description,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&taskv1.UpdateTaskResponse{Task: toProto(task)}), nil
+}
+
+// DeleteTask implements taskv1connect.TaskServiceHandler.
+func (s *TaskServer) DeleteTask(ctx context.Context, req *connect.Request[taskv1.DeleteTaskRequest]) (*connect.Response[taskv1.DeleteTaskResponse], error) {
+	path := handler.TaskPath{ID: req.Msg.GetId()}
+	if err := s.validate(req.Header(), path); err != nil {
+		return nil, err
+	}
+
+	if err := s.taskService.Delete(ctx, path.ID); err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&taskv1.DeleteTaskResponse{}), nil
+}
+
+func toProto(task *model.Task) *taskv1.Task {
+	message := &taskv1.Task{
+		Id:          task.ID,
+		Description: task.Description,
+		State:       task.State,
+		CreatedBy:   task.CreatedBy,
+	}
+	if task.CreatedAt != nil {
+		message.CreatedAt = timestamppb.New(*task.CreatedAt)
+	}
+	if task.UpdatedAt != nil {
+		message.UpdatedAt = timestamppb.New(*task.UpdatedAt)
+	}
+	return message
+}
+
+func toProtos(tasks []*model.Task) []*taskv1.Task {
+	messages := make([]*taskv1.Task, 0, len(tasks))
+	for _, task := range tasks {
+		messages = append(messages, toProto(task))
+	}
+	return messages
+}
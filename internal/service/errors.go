@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+	"net/http"
+
+	"golang-service-template/internal/errz"
+	"golang-service-template/internal/telemetry"
+)
+
+// logFailure logs a failure of a service with the logger of ctx, so the line carries the request id
+// and the trace and span ids of the operation, and returns it.
+// The database errors errz.New translates (e.g. a unique violation) are expected outcomes, only logged in debug.
+//
+//	return nil, logFailure(ctx, errz.New(errz.CodeInternal, "failed to get entity", err))
+func logFailure(ctx context.Context, err errz.PrettyError) error {
+	event := telemetry.Logger(ctx).Debug()
+	if err.HttpStatusCode >= http.StatusInternalServerError {
+		event = telemetry.Logger(ctx).Error()
+	}
+	event.Err(err).Str("code", err.Code).Msg(err.Message)
+
+	return err
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"golang-service-template/internal/common"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/samber/do"
+	"go.temporal.io/sdk/client"
+	"gorm.io/gorm"
+)
+
+// NewRedisHealthCheck pings redis
+func NewRedisHealthCheck(i *do.Injector) (HealthCheck, error) {
+	rdb := do.MustInvoke[*redis.Client](i)
+
+	return HealthCheck{
+		Critical: true,
+		Checker: HealthCheckerFunc(func(ctx context.Context) error {
+			if err := rdb.Ping(ctx).Err(); err != nil {
+				return errors.Wrap(err, "failed to ping redis")
+			}
+			return nil
+		}),
+	}, nil
+}
+
+// NewDatabaseHealthCheck pings the database.
+// A successful ping is cached in redis for HEALTHCHECK_TIMEOUT_SECONDS,
+// so frequent readiness probes don't hit the database every time.
+func NewDatabaseHealthCheck(i *do.Injector) (HealthCheck, error) {
+	db := do.MustInvoke[*gorm.DB](i)
+	rdb := do.MustInvoke[*redis.Client](i)
+	config := do.MustInvoke[common.Config](i)
+
+	healthcheckKey := fmt.Sprintf("%s:healthcheck:%s", config.ServiceName, uuid.New().String())
+	ttl := time.Duration(config.HealthcheckTimeoutSeconds) * time.Second
+
+	return HealthCheck{
+		Critical: true,
+		Checker: HealthCheckerFunc(func(ctx context.Context) error {
+			result := rdb.Get(ctx, healthcheckKey)
+			if result.Err() == nil && result.Val() == "OK" {
+				// still OK, lets wait for expiry time before ping db again
+				return nil
+			}
+
+			sqlDB, err := db.DB()
+			if err != nil {
+				return errors.Wrap(err, "failed to get db connection")
+			}
+
+			if err := sqlDB.PingContext(ctx); err != nil {
+				return errors.Wrap(err, "failed to ping db")
+			}
+
+			rdb.SetEx(ctx, healthcheckKey, "OK", ttl)
+			return nil
+		}),
+	}, nil
+}
+
+// NewTemporalHealthCheck checks the Temporal frontend is reachable.
+// It is not critical: task notifications are fire-and-forget, the API keeps working without them.
+func NewTemporalHealthCheck(i *do.Injector) (HealthCheck, error) {
+	temporalClient, err := do.Invoke[client.Client](i)
+	if err != nil || temporalClient == nil {
+		// Temporal is not configured, nothing to check
+		return HealthCheck{}, nil
+	}
+
+	return HealthCheck{
+		Critical: false,
+		Checker: HealthCheckerFunc(func(ctx context.Context) error {
+			if _, err := temporalClient.CheckHealth(ctx, &client.CheckHealthRequest{}); err != nil {
+				return errors.Wrap(err, "failed to check temporal health")
+			}
+			return nil
+		}),
+	}, nil
+}
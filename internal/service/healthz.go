@@ -2,73 +2,178 @@ package service
 
 import (
 	"context"
-	"fmt"
-	"golang-service-template/internal/common"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/cockroachdb/errors"
-	"github.com/google/uuid"
 	"github.com/samber/do"
+)
+
+const (
+	// healthCheckPrefix is the injector name prefix of every registered HealthCheck
+	healthCheckPrefix = "healthcheck:"
+
+	// defaultHealthCheckTimeout applies to checks registered without a timeout
+	defaultHealthCheckTimeout = 2 * time.Second
 
-	"github.com/redis/go-redis/v9"
-	"gorm.io/gorm"
+	HealthStatusOK     = "ok"
+	HealthStatusFailed = "failed"
 )
 
+// HealthChecker is implemented by any component the readiness probe should check
+type HealthChecker interface {
+	Check(ctx context.Context) error
+}
+
+// HealthCheckerFunc adapts a function to HealthChecker
+type HealthCheckerFunc func(ctx context.Context) error
+
+func (f HealthCheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// HealthCheck is a HealthChecker registered in the readiness probe
+type HealthCheck struct {
+	Checker HealthChecker
+	// Timeout of a single check, defaults to 2 seconds
+	Timeout time.Duration
+	// Critical checks make the service not ready when they fail,
+	// non critical ones are only reported (e.g. a dependency with a fallback)
+	Critical bool
+}
+
+// ProvideHealthCheck registers a health check in the injector, NewHealthService picks up all of them.
+// A provider may return a HealthCheck with a nil Checker to skip the check (e.g. optional dependency not configured).
+//
+//	service.ProvideHealthCheck(injector, "redis", service.NewRedisHealthCheck)
+func ProvideHealthCheck(i *do.Injector, name string, provider do.Provider[HealthCheck]) {
+	do.ProvideNamed(i, healthCheckPrefix+name, provider)
+}
+
+// HealthCheckResult is the outcome of a single check
+type HealthCheckResult struct {
+	Name     string  `json:"name"`
+	Status   string  `json:"status"`
+	Critical bool    `json:"critical"`
+	Latency  float64 `json:"latency_ms"`
+	Error    string  `json:"error,omitempty"`
+}
+
+// HealthReport is the outcome of all the checks
+type HealthReport struct {
+	Ready  bool                `json:"ready"`
+	Checks []HealthCheckResult `json:"checks"`
+}
+
 type HealthService interface {
 	LivenessCheck(ctx context.Context) error
-	ReadinessCheck(ctx context.Context) error
+	ReadinessCheck(ctx context.Context) (HealthReport, error)
+	StartupCheck(ctx context.Context) (HealthReport, error)
+	// Drain makes the readiness check fail from now on, so the load balancer
+	// stops sending traffic while the service shuts down
+	Drain()
 }
 
 type healthService struct {
-	db         *gorm.DB
-	redis      *redis.Client
-	config     common.Config
-	instanceID string
+	checks   map[string]HealthCheck
+	started  atomic.Bool
+	draining atomic.Bool
 }
 
 func NewHealthService(i *do.Injector) (HealthService, error) {
-	instanceID := uuid.New().String()
+	checks := map[string]HealthCheck{}
+
+	for _, name := range i.ListProvidedServices() {
+		if !strings.HasPrefix(name, healthCheckPrefix) {
+			continue
+		}
+
+		check, err := do.InvokeNamed[HealthCheck](i, name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create health check %s", name)
+		}
+
+		if check.Checker == nil {
+			continue
+		}
+
+		if check.Timeout <= 0 {
+			check.Timeout = defaultHealthCheckTimeout
+		}
+
+		checks[strings.TrimPrefix(name, healthCheckPrefix)] = check
+	}
 
 	return &healthService{
-		db:         do.MustInvoke[*gorm.DB](i),
-		redis:      do.MustInvoke[*redis.Client](i),
-		config:     do.MustInvoke[common.Config](i),
-		instanceID: instanceID,
+		checks: checks,
 	}, nil
 }
 
-func (service *healthService) ReadinessCheck(ctx context.Context) error {
-
-	status := service.redis.Ping(ctx)
-	if status.Err() != nil {
-		return errors.Join(status.Err(), errors.New("failed to ping redis"))
+// ReadinessCheck runs all the registered checks concurrently, each with its own timeout.
+// The error is set when at least one critical check failed, or when the service is draining.
+func (service *healthService) ReadinessCheck(ctx context.Context) (HealthReport, error) {
+	if service.draining.Load() {
+		return HealthReport{Ready: false, Checks: []HealthCheckResult{}}, errors.New("service is shutting down")
 	}
 
-	healthcheckKey := fmt.Sprintf("%s:healthcheck:%s", service.config.ServiceName, service.instanceID)
-	result := service.redis.Get(ctx, healthcheckKey)
-	if result.Err() != nil && result.Err() != redis.Nil {
-		return errors.Join(result.Err(), errors.New("failed to get healthcheck"))
+	report := HealthReport{
+		Ready:  true,
+		Checks: make([]HealthCheckResult, 0, len(service.checks)),
 	}
 
-	if result.Val() == "OK" {
-		// still OK, lets wait for expiry time before ping db again
-		return nil
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		errs []error
+	)
+
+	for name, check := range service.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			result, err := runHealthCheck(ctx, name, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Checks = append(report.Checks, result)
+			if err != nil && check.Critical {
+				report.Ready = false
+				errs = append(errs, errors.Wrapf(err, "%s check failed", name))
+			}
+		}()
 	}
 
-	db, err := service.db.DB()
-	if err != nil {
-		return errors.Join(err, errors.New("failed to get db connection"))
+	wg.Wait()
+
+	sort.Slice(report.Checks, func(a, b int) bool {
+		return report.Checks[a].Name < report.Checks[b].Name
+	})
+
+	return report, errors.Join(errs...)
+}
+
+// StartupCheck succeeds once the critical checks passed for the first time,
+// after that it never runs the checks again so slow dependencies don't restart the pod.
+func (service *healthService) StartupCheck(ctx context.Context) (HealthReport, error) {
+	if service.started.Load() {
+		return HealthReport{Ready: true, Checks: []HealthCheckResult{}}, nil
 	}
 
-	err = db.Ping()
-	if err != nil {
-		return errors.Join(err, errors.New("failed to get ping db"))
+	report, err := service.ReadinessCheck(ctx)
+	if err == nil {
+		service.started.Store(true)
 	}
 
-	timeout := time.Duration(service.config.HealthcheckTimeoutSeconds) * time.Second
-	service.redis.SetEx(ctx, healthcheckKey, "OK", timeout)
+	return report, err
+}
 
-	return nil
+func (service *healthService) Drain() {
+	service.draining.Store(true)
 }
 
 // LivenessCheck performs a basic liveness check - just returns current time (very lightweight)
@@ -78,3 +183,25 @@ func (service *healthService) LivenessCheck(ctx context.Context) error {
 	// The handler will include the current timestamp in the response
 	return nil
 }
+
+func runHealthCheck(ctx context.Context, name string, check HealthCheck) (HealthCheckResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, check.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Checker.Check(ctx)
+
+	result := HealthCheckResult{
+		Name:     name,
+		Status:   HealthStatusOK,
+		Critical: check.Critical,
+		Latency:  float64(time.Since(start).Microseconds()) / 1000,
+	}
+
+	if err != nil {
+		result.Status = HealthStatusFailed
+		result.Error = err.Error()
+	}
+
+	return result, err
+}
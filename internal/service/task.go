@@ -6,15 +6,15 @@ import (
 	"golang-service-template/internal/dao/model"
 	"golang-service-template/internal/dao/query"
 	"golang-service-template/internal/errz"
+	"golang-service-template/internal/flags"
 	"golang-service-template/internal/telemetry"
 	"golang-service-template/internal/temporal/workflow"
-	"net/http"
-	"time"
 
 	"github.com/cockroachdb/errors"
 	"github.com/redis/go-redis/v9"
 	"github.com/samber/do"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.temporal.io/sdk/client"
 	"gorm.io/gorm"
 
@@ -31,358 +31,236 @@ type TaskService interface {
 }
 
 type taskService struct {
-	db            *gorm.DB
-	q             *query.Query
-	redis         *redis.Client
-	telemetry     *telemetry.Telemetry
+	db             *gorm.DB
+	q              *query.Query
+	redis          *redis.Client
+	telemetry      *telemetry.Telemetry
 	temporalClient client.Client
 	config         common.Config
+	flags          *flags.Flags
+	events         TaskEvents
 }
 
 func NewTaskService(i *do.Injector) (TaskService, error) {
 	db := do.MustInvoke[*gorm.DB](i)
 	tel := do.MustInvoke[*telemetry.Telemetry](i)
-	temporalClient := do.MustInvoke[client.Client](i) 
+	temporalClient := do.MustInvoke[client.Client](i)
 	config := do.MustInvoke[common.Config](i)
 
 	return &taskService{
-		db:            db,
-		q:             query.Use(db),
-		redis:         do.MustInvoke[*redis.Client](i),
-		telemetry:     tel,
+		db:             db,
+		q:              query.Use(db),
+		redis:          do.MustInvoke[*redis.Client](i),
+		telemetry:      tel,
 		temporalClient: temporalClient,
 		config:         config,
+		flags:          do.MustInvoke[*flags.Flags](i),
+		events:         do.MustInvoke[TaskEvents](i),
 	}, nil
 }
 
 // Create implements TaskService.
 func (s *taskService) Create(ctx context.Context, entity model.Task) (*model.Task, error) {
-	start := time.Now()
+	return telemetry.Observe(ctx, s.telemetry, "task_create", func(ctx context.Context) (*model.Task, error) {
+		newID, err := uuid.NewV7()
+		if err != nil {
+			return nil, logFailure(ctx, errz.New(errz.CodeInternal, "failed to generate new id", err))
+		}
 
-	// Create trace span using generic method
-	ctx, span := s.telemetry.CreateSpan(ctx, "task_create",
-		attribute.String("operation", "create"))
-	defer span.End()
+		entityp := &entity
+		entityp.ID = newID.String()
+		entityp.CreatedBy = newID.String() // TODO: get user id from context
 
-	newID, err := uuid.NewV7()
-	if err != nil {
-		s.telemetry.RecordError(ctx, err)
-		return nil, errz.NewPrettyError(http.StatusInternalServerError, "internal_server_error", "failed to generate new id", err)
-	}
+		if err := query.Use(s.db).WithContext(ctx).Task.Create(entityp); err != nil {
+			return nil, logFailure(ctx, errz.New(errz.CodeInternal, "failed to create task", err))
+		}
 
-	entityp := &entity
-	entityp.ID = newID.String()
-	entityp.CreatedBy = newID.String() // TODO: get user id from context
-
-	if err := query.Use(s.db).WithContext(ctx).Task.Create(entityp); err != nil {
-		// Record error metrics using generic methods
-		s.telemetry.Increment(ctx, "task_create_total",
-			attribute.String("status", "error"))
-		s.telemetry.RecordDuration(ctx, "task_create_duration_seconds",
-			start,
-			attribute.String("status", "error"))
-		s.telemetry.RecordError(ctx, err)
-		return nil, errz.NewPrettyError(http.StatusInternalServerError, "internal_server_error", "failed to create task", err)
-	}
+		// Add task ID to span now that we have it
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("task.id", entityp.ID))
 
-	// Record success metrics using generic methods
-	s.telemetry.Increment(ctx, "task_create_total",
-		attribute.String("status", "success"))
-	s.telemetry.RecordDuration(ctx, "task_create_duration_seconds",
-		start,
-		attribute.String("status", "success"))
-
-	// Add task ID to span now that we have it
-	span.SetAttributes(attribute.String("task.id", entityp.ID))
-
-	// Trigger Temporal workflow for task notification (fire-and-forget)
-	if s.temporalClient != nil {
-		taskQueue := s.config.TemporalConfig.TaskQueue
-		if taskQueue == "" {
-			taskQueue = "task-notifications"
-		}
-		go func() {
-			_, err := s.temporalClient.ExecuteWorkflow(context.Background(), client.StartWorkflowOptions{
-				TaskQueue: taskQueue,
-			}, workflow.TaskNotificationWorkflow, workflow.TaskNotificationInput{
-				TaskID:           entityp.ID,
-				NotificationType: "create",
-			})
-			if err != nil {
-				s.telemetry.RecordError(context.Background(), err)
-			}
-		}()
-	}
+		s.notify(ctx, entityp.ID, "create")
+		s.publish(ctx, TaskEvent{Type: TaskCreated, TaskID: entityp.ID, Task: entityp})
 
-	return entityp, nil
+		return entityp, nil
+	}, attribute.String("operation", "create"))
 }
 
 // Get implements TaskService.
 func (s *taskService) Get(ctx context.Context, id string) (*model.Task, error) {
-	start := time.Now()
-
-	// Create trace span using generic method
-	ctx, span := s.telemetry.CreateSpan(ctx, "task_get",
-		attribute.String("operation", "get"),
-		attribute.String("task.id", id))
-	defer span.End()
-
-	entity, err := s.q.WithContext(ctx).Task.Where(s.q.Task.ID.Eq(id)).First()
-
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		s.telemetry.Increment(ctx, "task_get_total",
-			attribute.String("status", "not_found"))
-		s.telemetry.RecordDuration(ctx, "task_get_duration_seconds",
-			start,
-			attribute.String("status", "not_found"))
-		return nil, errz.NewPrettyError(http.StatusNotFound, "not_found", "entity not found", err)
-	}
+	return telemetry.Observe(ctx, s.telemetry, "task_get", func(ctx context.Context) (*model.Task, error) {
+		entity, err := s.q.WithContext(ctx).Task.Where(s.q.Task.ID.Eq(id)).First()
 
-	if err != nil {
-		s.telemetry.Increment(ctx, "task_get_total",
-			attribute.String("status", "error"))
-		s.telemetry.RecordDuration(ctx, "task_get_duration_seconds",
-			start,
-			attribute.String("status", "error"))
-		s.telemetry.RecordError(ctx, err)
-		return nil, errz.NewPrettyError(http.StatusInternalServerError, "internal_server_error", "failed to get entity", err)
-	}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errz.New(errz.CodeNotFound, "entity not found", err)
+		}
 
-	// Authorization check: verify user owns the task
-	if userId := ctx.Value("context_key_user_id"); userId != nil {
-		if userIdStr, ok := userId.(string); ok && entity.CreatedBy != userIdStr {
-			s.telemetry.Increment(ctx, "task_get_total",
-				attribute.String("status", "forbidden"))
-			s.telemetry.RecordDuration(ctx, "task_get_duration_seconds",
-				start,
-				attribute.String("status", "forbidden"))
-			return nil, errz.NewPrettyError(http.StatusForbidden, "forbidden", "you don't have permission to access this task", nil)
+		if err != nil {
+			return nil, logFailure(ctx, errz.New(errz.CodeInternal, "failed to get entity", err))
 		}
-	}
 
-	// Record success
-	s.telemetry.Increment(ctx, "task_get_total",
-		attribute.String("status", "success"))
-	s.telemetry.RecordDuration(ctx, "task_get_duration_seconds",
-		start,
-		attribute.String("status", "success"))
-	return entity, nil
+		// Authorization check: verify user owns the task
+		if userId := ctx.Value("context_key_user_id"); userId != nil {
+			if userIdStr, ok := userId.(string); ok && entity.CreatedBy != userIdStr {
+				return nil, errz.New(errz.CodeForbidden, "you don't have permission to access this task", nil)
+			}
+		}
+
+		return entity, nil
+	}, attribute.String("operation", "get"), attribute.String("task.id", id))
 }
 
 // GetAll implements TaskService.
 func (s *taskService) Find(ctx context.Context) ([]*model.Task, error) {
-	start := time.Now()
-
-	// Create trace span using generic method
-	ctx, span := s.telemetry.CreateSpan(ctx, "task_find_all",
-		attribute.String("operation", "find_all"))
-	defer span.End()
-
-	entities, err := s.q.WithContext(ctx).Task.Find()
+	return telemetry.Observe(ctx, s.telemetry, "task_find_all", func(ctx context.Context) ([]*model.Task, error) {
+		entities, err := s.q.WithContext(ctx).Task.Find()
 
-	if err != nil {
-		s.telemetry.Increment(ctx, "task_find_all_total",
-			attribute.String("status", "error"))
-		s.telemetry.RecordDuration(ctx, "task_find_all_duration_seconds",
-			start,
-			attribute.String("status", "error"))
-		s.telemetry.RecordError(ctx, err)
-		return nil, errz.NewPrettyError(http.StatusInternalServerError, "internal_server_error", "failed to get entities", err)
-	}
+		if err != nil {
+			return nil, logFailure(ctx, errz.New(errz.CodeInternal, "failed to get entities", err))
+		}
 
-	// Record success
-	s.telemetry.Increment(ctx, "task_find_all_total",
-		attribute.String("status", "success"))
-	s.telemetry.RecordDuration(ctx, "task_find_all_duration_seconds",
-		start,
-		attribute.String("status", "success"))
-	span.SetAttributes(attribute.Int("task.count", len(entities)))
-	return entities, nil
+		trace.SpanFromContext(ctx).SetAttributes(attribute.Int("task.count", len(entities)))
+		return entities, nil
+	}, attribute.String("operation", "find_all"))
 }
 
 // GetAll implements TaskService.
 func (s *taskService) FindByUserId(ctx context.Context, userId string) ([]*model.Task, error) {
-	start := time.Now()
-
-	// Create trace span using generic method
-	ctx, span := s.telemetry.CreateSpan(ctx, "task_find_by_user",
-		attribute.String("operation", "find_by_user"),
-		attribute.String("user.id", userId))
-	defer span.End()
+	return telemetry.Observe(ctx, s.telemetry, "task_find_by_user", func(ctx context.Context) ([]*model.Task, error) {
+		taskQuery := s.q.WithContext(ctx).Task.Where(s.q.Task.CreatedBy.Eq(userId))
+		if s.flags.Variant(ctx, flags.TaskListOrder) == "newest_first" {
+			taskQuery = taskQuery.Order(s.q.Task.CreatedAt.Desc())
+		}
 
-	entities, err := s.q.WithContext(ctx).Task.Where(s.q.Task.CreatedBy.Eq(userId)).Find()
+		entities, err := taskQuery.Find()
 
-	if err != nil {
-		s.telemetry.Increment(ctx, "task_find_by_user_total",
-			attribute.String("status", "error"))
-		s.telemetry.RecordDuration(ctx, "task_find_by_user_duration_seconds",
-			start,
-			attribute.String("status", "error"))
-		s.telemetry.RecordError(ctx, err)
-		return nil, errz.NewPrettyError(http.StatusInternalServerError, "internal_server_error", "failed to get entities", err)
-	}
+		if err != nil {
+			return nil, logFailure(ctx, errz.New(errz.CodeInternal, "failed to get entities", err))
+		}
 
-	// Record success
-	s.telemetry.Increment(ctx, "task_find_by_user_total",
-		attribute.String("status", "success"))
-	s.telemetry.RecordDuration(ctx, "task_find_by_user_duration_seconds",
-		start,
-		attribute.String("status", "success"))
-	span.SetAttributes(attribute.Int("task.count", len(entities)))
-	return entities, nil
+		trace.SpanFromContext(ctx).SetAttributes(attribute.Int("task.count", len(entities)))
+		return entities, nil
+	}, attribute.String("operation", "find_by_user"), attribute.String("user.id", userId))
 }
 
 // Update implements TaskService.
 // using map here to avoid headache of handling Go's zero value
 // we pass whatever passed validation in handler
 func (s *taskService) Update(ctx context.Context, id string, entity map[string]any) (*model.Task, error) {
-	start := time.Now()
+	err := telemetry.ObserveErr(ctx, s.telemetry, "task_update", func(ctx context.Context) error {
+		// Authorization check: verify user owns the task before updating
+		if err := s.checkOwnership(ctx, id, "you don't have permission to update this task"); err != nil {
+			return err
+		}
 
-	// Create trace span using generic method
-	ctx, span := s.telemetry.CreateSpan(ctx, "task_update",
-		attribute.String("operation", "update"),
-		attribute.String("task.id", id))
-	defer span.End()
+		_, err := s.q.WithContext(ctx).Task.Where(s.q.Task.ID.Eq(id)).Updates(entity)
 
-	// Authorization check: verify user owns the task before updating
-	if userId := ctx.Value("context_key_user_id"); userId != nil {
-		existingTask, err := s.q.WithContext(ctx).Task.Where(s.q.Task.ID.Eq(id)).First()
-		if err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				s.telemetry.Increment(ctx, "task_update_total",
-					attribute.String("status", "not_found"))
-				s.telemetry.RecordDuration(ctx, "task_update_duration_seconds",
-					start,
-					attribute.String("status", "not_found"))
-				return nil, errz.NewPrettyError(http.StatusNotFound, "not_found", "entity not found", err)
-			}
-			s.telemetry.Increment(ctx, "task_update_total",
-				attribute.String("status", "error"))
-			s.telemetry.RecordDuration(ctx, "task_update_duration_seconds",
-				start,
-				attribute.String("status", "error"))
-			s.telemetry.RecordError(ctx, err)
-			return nil, errz.NewPrettyError(http.StatusInternalServerError, "internal_server_error", "failed to check task ownership", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errz.New(errz.CodeNotFound, "entity not found", err)
 		}
 
-		if userIdStr, ok := userId.(string); ok && existingTask.CreatedBy != userIdStr {
-			s.telemetry.Increment(ctx, "task_update_total",
-				attribute.String("status", "forbidden"))
-			s.telemetry.RecordDuration(ctx, "task_update_duration_seconds",
-				start,
-				attribute.String("status", "forbidden"))
-			return nil, errz.NewPrettyError(http.StatusForbidden, "forbidden", "you don't have permission to update this task", nil)
+		if err != nil {
+			return logFailure(ctx, errz.New(errz.CodeInternal, "failed to update entities", err))
 		}
-	}
 
-	_, err := s.q.WithContext(ctx).Task.Where(s.q.Task.ID.Eq(id)).Updates(entity)
+		s.notify(ctx, id, "update")
 
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		s.telemetry.Increment(ctx, "task_update_total",
-			attribute.String("status", "not_found"))
-		s.telemetry.RecordDuration(ctx, "task_update_duration_seconds",
-			start,
-			attribute.String("status", "not_found"))
-		return nil, errz.NewPrettyError(http.StatusNotFound, "not_found", "entity not found", err)
+		return nil
+	}, attribute.String("operation", "update"), attribute.String("task.id", id))
+	if err != nil {
+		return nil, err
 	}
 
+	// Get the updated entity (this will have its own telemetry and authorization check)
+	updated, err := s.Get(ctx, id)
 	if err != nil {
-		s.telemetry.Increment(ctx, "task_update_total",
-			attribute.String("status", "error"))
-		s.telemetry.RecordDuration(ctx, "task_update_duration_seconds",
-			start,
-			attribute.String("status", "error"))
-		s.telemetry.RecordError(ctx, err)
-		return nil, errz.NewPrettyError(http.StatusInternalServerError, "internal_server_error", "failed to update entities", err)
+		return nil, err
 	}
 
-	// Record success for update operation
-	s.telemetry.Increment(ctx, "task_update_total",
-		attribute.String("status", "success"))
-	s.telemetry.RecordDuration(ctx, "task_update_duration_seconds",
-		start,
-		attribute.String("status", "success"))
-
-	// Trigger Temporal workflow for task notification (fire-and-forget)
-	if s.temporalClient != nil {
-		taskQueue := s.config.TemporalConfig.TaskQueue
-		if taskQueue == "" {
-			taskQueue = "task-notifications"
-		}
-		go func() {
-			_, err := s.temporalClient.ExecuteWorkflow(context.Background(), client.StartWorkflowOptions{
-				TaskQueue: taskQueue,
-			}, workflow.TaskNotificationWorkflow, workflow.TaskNotificationInput{
-				TaskID:           id,
-				NotificationType: "update",
-			})
-			if err != nil {
-				s.telemetry.RecordError(context.Background(), err)
-			}
-		}()
-	}
+	s.publish(ctx, TaskEvent{Type: TaskUpdated, TaskID: id, Task: updated})
 
-	// Get the updated entity (this will have its own telemetry and authorization check)
-	return s.Get(ctx, id)
+	return updated, nil
 }
 
 // Delete implements TaskService.
 func (s *taskService) Delete(ctx context.Context, id string) error {
-	start := time.Now()
-
-	// Create trace span using generic method
-	ctx, span := s.telemetry.CreateSpan(ctx, "task_delete",
-		attribute.String("operation", "delete"),
-		attribute.String("task.id", id))
-	defer span.End()
+	return telemetry.ObserveErr(ctx, s.telemetry, "task_delete", func(ctx context.Context) error {
+		// Authorization check: verify user owns the task before deleting
+		if err := s.checkOwnership(ctx, id, "you don't have permission to delete this task"); err != nil {
+			return err
+		}
 
-	// Authorization check: verify user owns the task before deleting
-	if userId := ctx.Value("context_key_user_id"); userId != nil {
-		existingTask, err := s.q.WithContext(ctx).Task.Where(s.q.Task.ID.Eq(id)).First()
+		// the task goes in the event as it was, for the subscribers of the tasks of its creator
+		deleted, err := s.q.WithContext(ctx).Task.Where(s.q.Task.ID.Eq(id)).First()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
 		if err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				s.telemetry.Increment(ctx, "task_delete_total",
-					attribute.String("status", "not_found"))
-				s.telemetry.RecordDuration(ctx, "task_delete_duration_seconds",
-					start,
-					attribute.String("status", "not_found"))
-				return errz.NewPrettyError(http.StatusNotFound, "not_found", "entity not found", err)
-			}
-			s.telemetry.Increment(ctx, "task_delete_total",
-				attribute.String("status", "error"))
-			s.telemetry.RecordDuration(ctx, "task_delete_duration_seconds",
-				start,
-				attribute.String("status", "error"))
-			s.telemetry.RecordError(ctx, err)
-			return errors.Wrap(err, "failed to check task ownership")
+			return logFailure(ctx, errz.New(errz.CodeInternal, "failed to get task", err))
 		}
 
-		if userIdStr, ok := userId.(string); ok && existingTask.CreatedBy != userIdStr {
-			s.telemetry.Increment(ctx, "task_delete_total",
-				attribute.String("status", "forbidden"))
-			s.telemetry.RecordDuration(ctx, "task_delete_duration_seconds",
-				start,
-				attribute.String("status", "forbidden"))
-			return errz.NewPrettyError(http.StatusForbidden, "forbidden", "you don't have permission to delete this task", nil)
+		_, err = s.q.WithContext(ctx).Task.Where(s.q.Task.ID.Eq(id)).Delete()
+		if err != nil {
+			return logFailure(ctx, errz.New(errz.CodeInternal, "failed to delete task", err))
 		}
+
+		s.publish(ctx, TaskEvent{Type: TaskDeleted, TaskID: id, Task: deleted})
+
+		return nil
+	}, attribute.String("operation", "delete"), attribute.String("task.id", id))
+}
+
+// checkOwnership verifies the user in ctx (if any) created the task
+func (s *taskService) checkOwnership(ctx context.Context, id string, forbiddenMessage string) error {
+	userId := ctx.Value("context_key_user_id")
+	if userId == nil {
+		return nil
 	}
 
-	_, err := s.q.WithContext(ctx).Task.Where(s.q.Task.ID.Eq(id)).Delete()
+	existingTask, err := s.q.WithContext(ctx).Task.Where(s.q.Task.ID.Eq(id)).First()
 	if err != nil {
-		s.telemetry.Increment(ctx, "task_delete_total",
-			attribute.String("status", "error"))
-		s.telemetry.RecordDuration(ctx, "task_delete_duration_seconds",
-			start,
-			attribute.String("status", "error"))
-		s.telemetry.RecordError(ctx, err)
-		return errors.Wrap(err, "failed to delete task")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errz.New(errz.CodeNotFound, "entity not found", err)
+		}
+		return logFailure(ctx, errz.New(errz.CodeInternal, "failed to check task ownership", err))
+	}
+
+	if userIdStr, ok := userId.(string); ok && existingTask.CreatedBy != userIdStr {
+		return errz.New(errz.CodeForbidden, forbiddenMessage, nil)
 	}
 
-	// Record success
-	s.telemetry.Increment(ctx, "task_delete_total",
-		attribute.String("status", "success"))
-	s.telemetry.RecordDuration(ctx, "task_delete_duration_seconds",
-		start,
-		attribute.String("status", "success"))
 	return nil
 }
+
+// notify triggers the Temporal workflow for task notification (fire-and-forget)
+func (s *taskService) notify(ctx context.Context, taskID string, notificationType string) {
+	if s.temporalClient == nil || !s.flags.Enabled(ctx, flags.TaskNotifications) {
+		return
+	}
+
+	taskQueue := s.config.TemporalConfig.TaskQueue
+	if taskQueue == "" {
+		taskQueue = "task-notifications"
+	}
+
+	logger := telemetry.Logger(ctx)
+	go func() {
+		_, err := s.temporalClient.ExecuteWorkflow(context.Background(), client.StartWorkflowOptions{
+			TaskQueue: taskQueue,
+		}, workflow.TaskNotificationWorkflow, workflow.TaskNotificationInput{
+			TaskID:           taskID,
+			NotificationType: notificationType,
+		})
+		if err != nil {
+			s.telemetry.RecordError(context.Background(), err)
+			logger.Error().Err(err).Str("task.id", taskID).Msg("failed to start task notification workflow")
+		}
+	}()
+}
+
+// publish sends the change to the TaskEvents subscribers, a failure is only logged
+func (s *taskService) publish(ctx context.Context, event TaskEvent) {
+	if err := s.events.Publish(ctx, event); err != nil {
+		s.telemetry.RecordError(ctx, err)
+		telemetry.Logger(ctx).Error().Err(err).Str("task.id", event.TaskID).Msg("failed to publish task event")
+	}
+}
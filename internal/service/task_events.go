@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"golang-service-template/internal/dao/model"
+
+	"github.com/cockroachdb/errors"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+// Types of TaskEvent
+const (
+	TaskCreated = "created"
+	TaskUpdated = "updated"
+	TaskDeleted = "deleted"
+)
+
+// TaskEvent is a change of a task made through TaskService
+type TaskEvent struct {
+	Type   string `json:"type"`
+	TaskID string `json:"task_id"`
+	// The task after the change, the last state of the deleted ones
+	Task *model.Task `json:"task"`
+}
+
+// TaskEvents carries the TaskEvents to the subscribers, e.g. the GraphQL subscriptions
+type TaskEvents interface {
+	Publish(ctx context.Context, event TaskEvent) error
+	// Subscribe returns the events published from now on, until ctx is done or TaskEvents is closed.
+	// A subscriber that does not keep up misses events.
+	Subscribe(ctx context.Context) <-chan TaskEvent
+}
+
+// subscriberBuffer is how many events a subscriber can be behind before it misses some
+const subscriberBuffer = 64
+
+// RedisTaskEvents publishes the events on a redis channel, so the subscribers of every instance of the service get them.
+// The instance has a single redis subscription, opened with its first subscriber, the events are fanned out from it.
+type RedisTaskEvents struct {
+	client  *redis.Client
+	channel string
+	logger  zerolog.Logger
+
+	mu          sync.Mutex
+	pubsub      *redis.PubSub
+	subscribers map[chan TaskEvent]struct{}
+	closed      bool
+}
+
+var _ TaskEvents = (*RedisTaskEvents)(nil)
+
+func NewRedisTaskEvents(client *redis.Client, channel string, logger zerolog.Logger) *RedisTaskEvents {
+	return &RedisTaskEvents{
+		client:      client,
+		channel:     channel,
+		logger:      logger,
+		subscribers: map[chan TaskEvent]struct{}{},
+	}
+}
+
+// Publish implements TaskEvents.
+func (e *RedisTaskEvents) Publish(ctx context.Context, event TaskEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode task event")
+	}
+
+	if err := e.client.Publish(ctx, e.channel, payload).Err(); err != nil {
+		return errors.Wrapf(err, "failed to publish on redis channel %s", e.channel)
+	}
+
+	return nil
+}
+
+// Subscribe implements TaskEvents.
+func (e *RedisTaskEvents) Subscribe(ctx context.Context) <-chan TaskEvent {
+	events := make(chan TaskEvent, subscriberBuffer)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.closed {
+		close(events)
+		return events
+	}
+
+	if e.pubsub == nil {
+		// not bound to ctx, it is shared by the subscribers to come
+		e.pubsub = e.client.Subscribe(context.Background(), e.channel)
+		go e.relay(e.pubsub.Channel())
+	}
+	e.subscribers[events] = struct{}{}
+
+	go func() {
+		<-ctx.Done()
+		e.unsubscribe(events)
+	}()
+
+	return events
+}
+
+func (e *RedisTaskEvents) unsubscribe(events chan TaskEvent) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, ok := e.subscribers[events]; ok {
+		delete(e.subscribers, events)
+		close(events)
+	}
+}
+
+// relay fans the messages of the redis subscription out to the subscribers, until it is closed
+func (e *RedisTaskEvents) relay(messages <-chan *redis.Message) {
+	for message := range messages {
+		event := TaskEvent{}
+		if err := json.Unmarshal([]byte(message.Payload), &event); err != nil || event.Task == nil {
+			e.logger.Warn().Err(err).Str("channel", e.channel).Msg("invalid task event")
+			continue
+		}
+
+		e.mu.Lock()
+		for events := range e.subscribers {
+			select {
+			case events <- event:
+			default:
+				e.logger.Warn().Str("task.id", event.TaskID).Msg("task event dropped, the subscriber is too slow")
+			}
+		}
+		e.mu.Unlock()
+	}
+}
+
+// Close ends the subscriptions and closes the redis subscription
+func (e *RedisTaskEvents) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.closed = true
+	for events := range e.subscribers {
+		delete(e.subscribers, events)
+		close(events)
+	}
+
+	if e.pubsub == nil {
+		return nil
+	}
+	return errors.Wrap(e.pubsub.Close(), "failed to close the redis subscription")
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"golang-service-template/internal/dao/model"
+	"golang-service-template/internal/dao/query"
+	"golang-service-template/internal/errz"
+	"golang-service-template/internal/telemetry"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"github.com/samber/do"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type UserService interface {
+	Create(ctx context.Context, email string, password string) (*model.User, error)
+	Get(ctx context.Context, id string) (*model.User, error)
+	GetByEmail(ctx context.Context, email string) (*model.User, error)
+}
+
+func init() {
+	// two concurrent signups with the same email pass the check in Create, the unique index rejects the second
+	errz.RegisterConstraint("idx_users_email", errz.CodeEmailTaken, "email is already registered")
+}
+
+type userService struct {
+	q         *query.Query
+	telemetry *telemetry.Telemetry
+}
+
+func NewUserService(i *do.Injector) (UserService, error) {
+	return &userService{
+		q:         query.Use(do.MustInvoke[*gorm.DB](i)),
+		telemetry: do.MustInvoke[*telemetry.Telemetry](i),
+	}, nil
+}
+
+// Create implements UserService.
+// The password is stored as a bcrypt hash.
+func (s *userService) Create(ctx context.Context, email string, password string) (*model.User, error) {
+	return telemetry.Observe(ctx, s.telemetry, "user_create", func(ctx context.Context) (*model.User, error) {
+		email = strings.ToLower(strings.TrimSpace(email))
+
+		_, err := s.q.WithContext(ctx).User.Where(s.q.User.Email.Eq(email)).First()
+		if err == nil {
+			return nil, errz.New(errz.CodeEmailTaken, "email is already registered", nil)
+		}
+
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, logFailure(ctx, errz.New(errz.CodeInternal, "failed to check email", err))
+		}
+
+		newID, err := uuid.NewV7()
+		if err != nil {
+			return nil, logFailure(ctx, errz.New(errz.CodeInternal, "failed to generate new id", err))
+		}
+
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, logFailure(ctx, errz.New(errz.CodeInternal, "failed to hash password", err))
+		}
+
+		user := &model.User{
+			ID:       newID.String(),
+			Email:    email,
+			Password: string(hash),
+		}
+
+		if err := s.q.WithContext(ctx).User.Create(user); err != nil {
+			return nil, logFailure(ctx, errz.New(errz.CodeInternal, "failed to create user", err))
+		}
+
+		return user, nil
+	}, attribute.String("operation", "create"))
+}
+
+// Get implements UserService.
+func (s *userService) Get(ctx context.Context, id string) (*model.User, error) {
+	user, err := s.q.WithContext(ctx).User.Where(s.q.User.ID.Eq(id)).First()
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errz.New(errz.CodeNotFound, "user not found", err)
+	}
+
+	if err != nil {
+		return nil, logFailure(ctx, errz.New(errz.CodeInternal, "failed to get user", err))
+	}
+
+	return user, nil
+}
+
+// GetByEmail implements UserService.
+func (s *userService) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	user, err := s.q.WithContext(ctx).User.Where(s.q.User.Email.Eq(strings.ToLower(strings.TrimSpace(email)))).First()
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errz.New(errz.CodeNotFound, "user not found", err)
+	}
+
+	if err != nil {
+		return nil, logFailure(ctx, errz.New(errz.CodeInternal, "failed to get user", err))
+	}
+
+	return user, nil
+}
@@ -0,0 +1,127 @@
+// Package settings holds the settings that can change without a restart.
+//
+// RuntimeSettings keeps an immutable snapshot, swapped atomically when a watched source
+// (a file, e.g. a mounted ConfigMap, or a Redis key) changes.
+// Middlewares and services read the current snapshot on every use, or subscribe to the changes.
+package settings
+
+import (
+	"maps"
+	"sync"
+	"sync/atomic"
+
+	"github.com/cockroachdb/errors"
+	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog"
+	"gopkg.in/yaml.v3"
+)
+
+// Settings is a snapshot of the runtime settings, never modify one in place
+type Settings struct {
+	// trace, debug, info, warn, error, fatal, panic or disabled, LOG_LEVEL if empty
+	LogLevel string `json:"log_level" yaml:"log_level" validate:"omitempty,oneof=trace debug info warn error fatal panic disabled"`
+	// Log request and response bodies
+	BodyLogging bool `json:"body_logging" yaml:"body_logging"`
+	// CORS allowed origins, ["*"] allows all without credentials
+	AllowedOrigins []string `json:"allowed_origins" yaml:"allowed_origins" validate:"min=1"`
+	// Per client IP rate limit
+	RateLimit RateLimit `json:"rate_limit" yaml:"rate_limit"`
+	// Kill switches, e.g. {"task_notifications": false}
+	Flags map[string]bool `json:"flags" yaml:"flags"`
+}
+
+type RateLimit struct {
+	// 0 disables the rate limit
+	RequestsPerSecond float64 `json:"requests_per_second" yaml:"requests_per_second" validate:"min=0"`
+	// Requests allowed at once above the rate, defaults to the rate
+	Burst int `json:"burst" yaml:"burst" validate:"min=0"`
+}
+
+// Flag returns the flag value, def if it is not set
+func (s Settings) Flag(name string, def bool) bool {
+	if value, ok := s.Flags[name]; ok {
+		return value
+	}
+	return def
+}
+
+// RuntimeSettings holds the current settings snapshot
+type RuntimeSettings struct {
+	// base is used for everything a source does not set
+	base    Settings
+	current atomic.Pointer[Settings]
+
+	mu          sync.Mutex
+	subscribers []func(Settings)
+
+	validate *validator.Validate
+	logger   zerolog.Logger
+}
+
+func NewRuntimeSettings(base Settings, logger zerolog.Logger) (*RuntimeSettings, error) {
+	r := &RuntimeSettings{
+		base:     base,
+		validate: validator.New(),
+		logger:   logger,
+	}
+
+	if err := r.validate.Struct(base); err != nil {
+		return nil, errors.Wrap(err, "invalid runtime settings")
+	}
+
+	r.current.Store(&base)
+
+	return r, nil
+}
+
+// Get returns the current snapshot
+func (r *RuntimeSettings) Get() Settings {
+	return *r.current.Load()
+}
+
+// Subscribe calls fn with the current snapshot, then after every change.
+// fn must be fast, it runs on the goroutine applying the change.
+func (r *RuntimeSettings) Subscribe(fn func(Settings)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.subscribers = append(r.subscribers, fn)
+	fn(*r.current.Load())
+}
+
+// Update validates the settings, swaps the snapshot and notifies the subscribers.
+// The current snapshot is kept if they are invalid.
+func (r *RuntimeSettings) Update(settings Settings) error {
+	if err := r.validate.Struct(settings); err != nil {
+		return errors.Wrap(err, "invalid runtime settings")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.current.Store(&settings)
+
+	for _, subscriber := range r.subscribers {
+		subscriber(settings)
+	}
+
+	return nil
+}
+
+// Apply parses yaml or json documents over the base settings, each one over the previous,
+// and updates the snapshot.
+// Keys missing from every document keep their base value, no documents resets to the base settings.
+func (r *RuntimeSettings) Apply(documents ...[]byte) error {
+	settings := r.base
+	// maps are merged by the decoder, don't write into the base one
+	settings.Flags = maps.Clone(r.base.Flags)
+
+	for _, document := range documents {
+		// yaml is a superset of json
+		if err := yaml.Unmarshal(document, &settings); err != nil {
+			return errors.Wrap(err, "failed to parse runtime settings")
+		}
+	}
+
+	return r.Update(settings)
+}
@@ -0,0 +1,119 @@
+package settings
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// Source is where the settings documents are read from
+type Source interface {
+	Name() string
+	// Load returns the yaml or json document, nil if there is none
+	Load(ctx context.Context) ([]byte, error)
+}
+
+// FileSource reads a file, e.g. a mounted Kubernetes ConfigMap
+type FileSource struct {
+	Path string
+}
+
+func (s FileSource) Name() string {
+	return "file:" + s.Path
+}
+
+func (s FileSource) Load(ctx context.Context) ([]byte, error) {
+	content, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", s.Path)
+	}
+	return content, nil
+}
+
+// RedisSource reads a string key, shared by every instance of the service
+type RedisSource struct {
+	Client *redis.Client
+	Key    string
+}
+
+func (s RedisSource) Name() string {
+	return "redis:" + s.Key
+}
+
+func (s RedisSource) Load(ctx context.Context) ([]byte, error) {
+	content, err := s.Client.Get(ctx, s.Key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get redis key %s", s.Key)
+	}
+	return content, nil
+}
+
+// Watch loads the sources, then polls them every interval in the background until ctx is done.
+// Their documents are applied in order (the later sources override the earlier ones) whenever one of them changes.
+// A source failing to load keeps its last document, an invalid change keeps the current snapshot.
+// The returned channel is closed once polling stopped.
+func (r *RuntimeSettings) Watch(ctx context.Context, interval time.Duration, sources ...Source) <-chan struct{} {
+	done := make(chan struct{})
+
+	documents := make([][]byte, len(sources))
+	r.reload(ctx, sources, documents, true)
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.reload(ctx, sources, documents, false)
+			}
+		}
+	}()
+
+	return done
+}
+
+func (r *RuntimeSettings) reload(ctx context.Context, sources []Source, documents [][]byte, force bool) {
+	changed := []string{}
+
+	for index, source := range sources {
+		document, err := source.Load(ctx)
+		if err != nil {
+			r.logger.Warn().Err(err).Str("source", source.Name()).Msg("failed to load runtime settings, keeping the last ones")
+			continue
+		}
+
+		// bytes.Equal treats nil and empty as equal, which is what we want
+		if !bytes.Equal(document, documents[index]) {
+			documents[index] = document
+			changed = append(changed, source.Name())
+		}
+	}
+
+	if len(changed) == 0 && !force {
+		return
+	}
+
+	if err := r.Apply(documents...); err != nil {
+		r.logger.Error().Err(err).Strs("sources", changed).Msg("invalid runtime settings, keeping the current ones")
+		return
+	}
+
+	if len(changed) > 0 {
+		r.logger.Info().Strs("sources", changed).Msg("runtime settings reloaded")
+	}
+}
@@ -0,0 +1,31 @@
+package telemetry
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RecordFeatureFlag records a flag evaluation on the active span, as the feature_flag.{key} attribute
+// and a feature_flag event (OpenTelemetry semantic conventions), and counts it in feature_flag_evaluations_total
+func (t *Telemetry) RecordFeatureFlag(ctx context.Context, key, variant, reason string) {
+	if t == nil {
+		return
+	}
+
+	if span := trace.SpanFromContext(ctx); span.IsRecording() {
+		span.SetAttributes(attribute.String("feature_flag."+key, variant))
+		span.AddEvent("feature_flag", trace.WithAttributes(
+			attribute.String("feature_flag.key", key),
+			attribute.String("feature_flag.variant", variant),
+			attribute.String("feature_flag.reason", reason),
+		))
+	}
+
+	t.Increment(ctx, "feature_flag_evaluations_total",
+		attribute.String("flag", key),
+		attribute.String("variant", variant),
+		attribute.String("reason", reason),
+	)
+}
@@ -0,0 +1,37 @@
+package telemetry
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	LogFieldTraceID = "trace_id"
+	LogFieldSpanID  = "span_id"
+)
+
+// TraceHook adds trace_id and span_id of the active span to every event that carries a context.
+// Events get a context through Logger(ctx), logger.With().Ctx(ctx) or event.Ctx(ctx).
+type TraceHook struct{}
+
+func (TraceHook) Run(e *zerolog.Event, _ zerolog.Level, _ string) {
+	spanContext := trace.SpanContextFromContext(e.GetCtx())
+	if !spanContext.IsValid() {
+		return
+	}
+
+	e.Str(LogFieldTraceID, spanContext.TraceID().String()).
+		Str(LogFieldSpanID, spanContext.SpanID().String())
+}
+
+// Logger returns the logger stored in ctx (request scoped, with request_id)
+// bound to ctx, so every line it writes carries the trace_id and span_id of the active span.
+// Use this in services instead of the global zerolog logger.
+//
+//	telemetry.Logger(ctx).Info().Str("task.id", id).Msg("task created")
+func Logger(ctx context.Context) *zerolog.Logger {
+	logger := zerolog.Ctx(ctx).With().Ctx(ctx).Logger()
+	return &logger
+}
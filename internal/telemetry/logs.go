@@ -0,0 +1,184 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/rs/zerolog"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func (t *Telemetry) setupLogs(res *resource.Resource) error {
+	if t.config.OtelEndpoint == "" {
+		t.logger.Info().Msg("Logs export enabled but no OTLP endpoint configured, skipping")
+		return nil
+	}
+
+	exporter, err := t.newLogExporter(context.Background())
+	if err != nil {
+		return err
+	}
+
+	t.loggerProvider = sdklog.NewLoggerProvider(
+		sdklog.WithResource(res),
+		sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter)),
+	)
+
+	global.SetLoggerProvider(t.loggerProvider)
+	t.shutdownFuncs = append(t.shutdownFuncs, t.loggerProvider.Shutdown)
+
+	t.logger.Info().Str("protocol", t.config.OtelProtocol).Msg("Using OTLP log exporter")
+	return nil
+}
+
+// logBridge is a zerolog.LevelWriter that forwards every log line to the OTel logs SDK.
+// It uses the global LoggerProvider, so it can be created before telemetry is initialized
+// and starts emitting once setupLogs installs the provider. Until then it is a no-op.
+type logBridge struct {
+	logger otellog.Logger
+}
+
+// NewLogBridge returns a writer to tee zerolog output into the OTel logs pipeline
+//
+//	zerolog.New(zerolog.MultiLevelWriter(os.Stdout, telemetry.NewLogBridge("my-service")))
+func NewLogBridge(name string) zerolog.LevelWriter {
+	return &logBridge{
+		logger: global.Logger(name),
+	}
+}
+
+func (b *logBridge) Write(p []byte) (int, error) {
+	return b.WriteLevel(zerolog.NoLevel, p)
+}
+
+func (b *logBridge) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	ctx := context.Background()
+	severity := severityFromLevel(level)
+
+	if !b.logger.Enabled(ctx, otellog.EnabledParameters{Severity: severity}) {
+		return len(p), nil
+	}
+
+	fields := map[string]any{}
+	if err := json.Unmarshal(p, &fields); err != nil {
+		// not a JSON line (e.g. console writer), ship it as is
+		fields = map[string]any{zerolog.MessageFieldName: string(p)}
+	}
+
+	var record otellog.Record
+	record.SetObservedTimestamp(time.Now())
+	record.SetTimestamp(time.Now())
+	record.SetSeverity(severity)
+	record.SetSeverityText(level.String())
+
+	if ts, ok := fields[zerolog.TimestampFieldName].(string); ok {
+		if parsed, err := time.Parse(zerolog.TimeFieldFormat, ts); err == nil {
+			record.SetTimestamp(parsed)
+		}
+	}
+
+	if msg, ok := fields[zerolog.MessageFieldName].(string); ok {
+		record.SetBody(otellog.StringValue(msg))
+	}
+
+	// correlate the record with the span it was logged in (see TraceHook)
+	ctx = contextWithLoggedSpan(ctx, fields)
+
+	delete(fields, zerolog.TimestampFieldName)
+	delete(fields, zerolog.LevelFieldName)
+	delete(fields, zerolog.MessageFieldName)
+
+	attrs := make([]otellog.KeyValue, 0, len(fields))
+	for key, value := range fields {
+		attrs = append(attrs, otellog.KeyValue{Key: key, Value: logValue(value)})
+	}
+	record.AddAttributes(attrs...)
+
+	b.logger.Emit(ctx, record)
+
+	return len(p), nil
+}
+
+func severityFromLevel(level zerolog.Level) otellog.Severity {
+	switch level {
+	case zerolog.TraceLevel:
+		return otellog.SeverityTrace
+	case zerolog.DebugLevel:
+		return otellog.SeverityDebug
+	case zerolog.InfoLevel:
+		return otellog.SeverityInfo
+	case zerolog.WarnLevel:
+		return otellog.SeverityWarn
+	case zerolog.ErrorLevel:
+		return otellog.SeverityError
+	case zerolog.FatalLevel:
+		return otellog.SeverityFatal
+	case zerolog.PanicLevel:
+		return otellog.SeverityFatal4
+	default:
+		return otellog.SeverityInfo
+	}
+}
+
+// logValue converts a decoded JSON value into an OTel log value
+func logValue(v any) otellog.Value {
+	switch val := v.(type) {
+	case string:
+		return otellog.StringValue(val)
+	case bool:
+		return otellog.BoolValue(val)
+	case float64:
+		if val == float64(int64(val)) {
+			return otellog.Int64Value(int64(val))
+		}
+		return otellog.Float64Value(val)
+	case []any:
+		values := make([]otellog.Value, 0, len(val))
+		for _, item := range val {
+			values = append(values, logValue(item))
+		}
+		return otellog.SliceValue(values...)
+	case map[string]any:
+		kvs := make([]otellog.KeyValue, 0, len(val))
+		for key, item := range val {
+			kvs = append(kvs, otellog.KeyValue{Key: key, Value: logValue(item)})
+		}
+		return otellog.MapValue(kvs...)
+	case nil:
+		return otellog.Value{}
+	default:
+		raw, _ := json.Marshal(val)
+		return otellog.StringValue(string(raw))
+	}
+}
+
+// contextWithLoggedSpan rebuilds the span context from the trace_id and span_id fields,
+// the logs SDK reads it from ctx to fill the record's trace context.
+func contextWithLoggedSpan(ctx context.Context, fields map[string]any) context.Context {
+	traceIDStr, _ := fields[LogFieldTraceID].(string)
+	spanIDStr, _ := fields[LogFieldSpanID].(string)
+
+	traceID, err := trace.TraceIDFromHex(traceIDStr)
+	if err != nil {
+		return ctx
+	}
+
+	spanID, err := trace.SpanIDFromHex(spanIDStr)
+	if err != nil {
+		return ctx
+	}
+
+	delete(fields, LogFieldTraceID)
+	delete(fields, LogFieldSpanID)
+
+	return trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+}
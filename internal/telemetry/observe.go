@@ -0,0 +1,80 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"golang-service-template/internal/errz"
+
+	"github.com/cockroachdb/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	StatusSuccess = "success"
+	StatusError   = "error"
+)
+
+// Observe runs fn inside a span named op and records the {op}_total counter and
+// the {op}_duration_seconds histogram, both labelled with the status derived from the returned error.
+// attrs are added to the span only, use trace.SpanFromContext(ctx) inside fn to add more.
+//
+//	return telemetry.Observe(ctx, s.telemetry, "task_get", func(ctx context.Context) (*model.Task, error) {
+//		return s.q.WithContext(ctx).Task.Where(s.q.Task.ID.Eq(id)).First()
+//	}, attribute.String("task.id", id))
+func Observe[T any](ctx context.Context, t *Telemetry, op string, fn func(ctx context.Context) (T, error), attrs ...attribute.KeyValue) (T, error) {
+	if t == nil {
+		return fn(ctx)
+	}
+
+	start := time.Now()
+
+	ctx, span := t.CreateSpan(ctx, op, attrs...)
+	defer span.End()
+
+	result, err := fn(ctx)
+
+	status := StatusFromError(err)
+
+	t.Increment(ctx, op+"_total", attribute.String("status", status))
+	t.RecordDuration(ctx, op+"_duration_seconds", start, attribute.String("status", status))
+
+	if status == StatusError {
+		t.RecordError(ctx, err)
+		span.SetStatus(codes.Error, err.Error())
+	} else if err != nil {
+		// expected outcomes (not_found, forbidden, ...) are not span errors, but are worth seeing
+		span.SetAttributes(attribute.String("error.code", status))
+	}
+
+	return result, err
+}
+
+// ObserveErr is Observe for operations that only return an error
+func ObserveErr(ctx context.Context, t *Telemetry, op string, fn func(ctx context.Context) error, attrs ...attribute.KeyValue) error {
+	_, err := Observe(ctx, t, op, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	}, attrs...)
+	return err
+}
+
+// StatusFromError derives the status label of an operation from its error:
+// success when nil, the PrettyError code for client errors (not_found, forbidden, ...)
+// and error for server errors or anything that is not a PrettyError.
+func StatusFromError(err error) string {
+	if err == nil {
+		return StatusSuccess
+	}
+
+	var prettyError errz.PrettyError
+	if !errors.As(err, &prettyError) {
+		prettyError, _ = errz.FromDatabase(err)
+	}
+	if prettyError.HttpStatusCode < http.StatusInternalServerError && prettyError.Code != "" {
+		return prettyError.Code
+	}
+
+	return StatusError
+}
@@ -0,0 +1,194 @@
+package telemetry
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/credentials"
+)
+
+const (
+	ProtocolGRPC         = "grpc"
+	ProtocolHTTPProtobuf = "http/protobuf"
+)
+
+// otlpTarget resolves the configured endpoint into the host:port the OTLP exporters expect.
+// OTEL_EXPORTER_OTLP_ENDPOINT may be a bare host:port or a URL, in which case
+// the scheme decides whether TLS is used and any path is dropped so every signal
+// uses its own default path (/v1/traces, /v1/metrics, /v1/logs).
+func (t *Telemetry) otlpTarget() (endpoint string, insecure bool) {
+	endpoint = t.config.OtelEndpoint
+	insecure = t.config.OtelInsecure
+
+	if u, err := url.Parse(endpoint); err == nil && u.Host != "" {
+		endpoint = u.Host
+		insecure = insecure || u.Scheme == "http"
+	}
+
+	return endpoint, insecure
+}
+
+// otlpTLSConfig builds the TLS config used when the exporter is not insecure.
+// If OtelCACertFile is set it is used instead of the system roots.
+func (t *Telemetry) otlpTLSConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if t.config.OtelCACertFile == "" {
+		return tlsConfig, nil
+	}
+
+	pem, err := os.ReadFile(t.config.OtelCACertFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OTLP CA certificate: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", t.config.OtelCACertFile)
+	}
+	tlsConfig.RootCAs = pool
+
+	return tlsConfig, nil
+}
+
+func (t *Telemetry) newTraceExporter(ctx context.Context) (sdktrace.SpanExporter, error) {
+	endpoint, insecure := t.otlpTarget()
+
+	tlsConfig, err := t.otlpTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if t.config.OtelProtocol == ProtocolGRPC {
+		opts := []otlptracegrpc.Option{
+			otlptracegrpc.WithEndpoint(endpoint),
+			otlptracegrpc.WithHeaders(t.config.OtelHeaders),
+		}
+		if insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		} else {
+			opts = append(opts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	}
+
+	opts := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(endpoint),
+		otlptracehttp.WithHeaders(t.config.OtelHeaders),
+	}
+	if insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	} else {
+		opts = append(opts, otlptracehttp.WithTLSClientConfig(tlsConfig))
+	}
+	return otlptracehttp.New(ctx, opts...)
+}
+
+func (t *Telemetry) newMetricExporter(ctx context.Context) (sdkmetric.Exporter, error) {
+	endpoint, insecure := t.otlpTarget()
+
+	tlsConfig, err := t.otlpTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if t.config.OtelProtocol == ProtocolGRPC {
+		opts := []otlpmetricgrpc.Option{
+			otlpmetricgrpc.WithEndpoint(endpoint),
+			otlpmetricgrpc.WithHeaders(t.config.OtelHeaders),
+		}
+		if insecure {
+			opts = append(opts, otlpmetricgrpc.WithInsecure())
+		} else {
+			opts = append(opts, otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+		}
+		return otlpmetricgrpc.New(ctx, opts...)
+	}
+
+	opts := []otlpmetrichttp.Option{
+		otlpmetrichttp.WithEndpoint(endpoint),
+		otlpmetrichttp.WithHeaders(t.config.OtelHeaders),
+	}
+	if insecure {
+		opts = append(opts, otlpmetrichttp.WithInsecure())
+	} else {
+		opts = append(opts, otlpmetrichttp.WithTLSClientConfig(tlsConfig))
+	}
+	return otlpmetrichttp.New(ctx, opts...)
+}
+
+func (t *Telemetry) newLogExporter(ctx context.Context) (sdklog.Exporter, error) {
+	endpoint, insecure := t.otlpTarget()
+
+	tlsConfig, err := t.otlpTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if t.config.OtelProtocol == ProtocolGRPC {
+		opts := []otlploggrpc.Option{
+			otlploggrpc.WithEndpoint(endpoint),
+			otlploggrpc.WithHeaders(t.config.OtelHeaders),
+		}
+		if insecure {
+			opts = append(opts, otlploggrpc.WithInsecure())
+		} else {
+			opts = append(opts, otlploggrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+		}
+		return otlploggrpc.New(ctx, opts...)
+	}
+
+	opts := []otlploghttp.Option{
+		otlploghttp.WithEndpoint(endpoint),
+		otlploghttp.WithHeaders(t.config.OtelHeaders),
+	}
+	if insecure {
+		opts = append(opts, otlploghttp.WithInsecure())
+	} else {
+		opts = append(opts, otlploghttp.WithTLSClientConfig(tlsConfig))
+	}
+	return otlploghttp.New(ctx, opts...)
+}
+
+// metricExportInterval returns how often metrics are pushed to the OTLP endpoint
+func (t *Telemetry) metricExportInterval() time.Duration {
+	if t.config.MetricsExportIntervalSeconds <= 0 {
+		return 60 * time.Second
+	}
+	return time.Duration(t.config.MetricsExportIntervalSeconds) * time.Second
+}
+
+// sampler builds the trace sampler from OTEL_TRACES_SAMPLER / OTEL_TRACES_SAMPLER_ARG,
+// using the same names as the OpenTelemetry SDK environment spec.
+func (t *Telemetry) sampler() sdktrace.Sampler {
+	ratio := t.config.TracesSamplerRatio
+
+	switch t.config.TracesSampler {
+	case "always_on":
+		return sdktrace.AlwaysSample()
+	case "always_off":
+		return sdktrace.NeverSample()
+	case "traceidratio":
+		return sdktrace.TraceIDRatioBased(ratio)
+	case "parentbased_always_off":
+		return sdktrace.ParentBased(sdktrace.NeverSample())
+	case "parentbased_traceidratio":
+		return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))
+	default: // parentbased_always_on
+		return sdktrace.ParentBased(sdktrace.AlwaysSample())
+	}
+}
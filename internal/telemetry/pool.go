@@ -0,0 +1,90 @@
+package telemetry
+
+import (
+	"context"
+	"database/sql"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// RegisterDBStats reports the sql.DBStats of a connection pool as db.client.connections.* metrics.
+// The names and the pool.name/state attributes are the same ones redisotel uses for the redis pool,
+// so database and redis pool saturation can share one dashboard.
+//
+//	sqlDB, _ := gormDB.DB()
+//	tel.RegisterDBStats("postgres", sqlDB.Stats)
+func (t *Telemetry) RegisterDBStats(poolName string, stats func() sql.DBStats) error {
+	if !t.config.Enabled || !t.config.MetricsEnabled || t.meter == nil {
+		return nil
+	}
+
+	poolAttrs := attribute.NewSet(attribute.String("pool.name", poolName))
+	idleAttrs := attribute.NewSet(attribute.String("pool.name", poolName), attribute.String("state", "idle"))
+	usedAttrs := attribute.NewSet(attribute.String("pool.name", poolName), attribute.String("state", "used"))
+
+	maxConnections, err := t.meter.Int64ObservableUpDownCounter(
+		"db.client.connections.max",
+		metric.WithDescription("The maximum number of open connections allowed"),
+	)
+	if err != nil {
+		return err
+	}
+
+	usage, err := t.meter.Int64ObservableUpDownCounter(
+		"db.client.connections.usage",
+		metric.WithDescription("The number of connections that are currently in state described by the state attribute"),
+	)
+	if err != nil {
+		return err
+	}
+
+	waits, err := t.meter.Int64ObservableCounter(
+		"db.client.connections.waits",
+		metric.WithDescription("The number of times a connection was waited for"),
+	)
+	if err != nil {
+		return err
+	}
+
+	waitsDuration, err := t.meter.Int64ObservableCounter(
+		"db.client.connections.waits_duration",
+		metric.WithDescription("The total time spent for waiting a connection in nanoseconds"),
+		metric.WithUnit("ns"),
+	)
+	if err != nil {
+		return err
+	}
+
+	closed, err := t.meter.Int64ObservableCounter(
+		"db.client.connections.closed",
+		metric.WithDescription("The number of connections closed, by reason"),
+	)
+	if err != nil {
+		return err
+	}
+
+	closedMaxIdle := attribute.NewSet(attribute.String("pool.name", poolName), attribute.String("reason", "max_idle"))
+	closedMaxIdleTime := attribute.NewSet(attribute.String("pool.name", poolName), attribute.String("reason", "max_idle_time"))
+	closedMaxLifetime := attribute.NewSet(attribute.String("pool.name", poolName), attribute.String("reason", "max_lifetime"))
+
+	_, err = t.meter.RegisterCallback(
+		func(ctx context.Context, o metric.Observer) error {
+			s := stats()
+
+			o.ObserveInt64(maxConnections, int64(s.MaxOpenConnections), metric.WithAttributeSet(poolAttrs))
+			o.ObserveInt64(usage, int64(s.Idle), metric.WithAttributeSet(idleAttrs))
+			o.ObserveInt64(usage, int64(s.InUse), metric.WithAttributeSet(usedAttrs))
+			o.ObserveInt64(waits, s.WaitCount, metric.WithAttributeSet(poolAttrs))
+			o.ObserveInt64(waitsDuration, s.WaitDuration.Nanoseconds(), metric.WithAttributeSet(poolAttrs))
+			o.ObserveInt64(closed, s.MaxIdleClosed, metric.WithAttributeSet(closedMaxIdle))
+			o.ObserveInt64(closed, s.MaxIdleTimeClosed, metric.WithAttributeSet(closedMaxIdleTime))
+			o.ObserveInt64(closed, s.MaxLifetimeClosed, metric.WithAttributeSet(closedMaxLifetime))
+
+			return nil
+		},
+		maxConnections, usage, waits, waitsDuration, closed,
+	)
+
+	return err
+}
@@ -0,0 +1,241 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var (
+	// DurationBuckets are the default boundaries of *_seconds histograms (5ms to 10s),
+	// the SDK defaults are meant for milliseconds
+	DurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+	// SizeBuckets are the default boundaries of *_bytes histograms (100B to 10MB)
+	SizeBuckets = []float64{100, 1_000, 10_000, 100_000, 1_000_000, 10_000_000}
+)
+
+// instruments is a concurrency-safe cache of instruments by name.
+// Metrics are recorded from many request goroutines, the first one to use a name creates it.
+type instruments[T any] struct {
+	mu     sync.RWMutex
+	byName map[string]T
+}
+
+func newInstruments[T any]() *instruments[T] {
+	return &instruments[T]{byName: make(map[string]T)}
+}
+
+// getOrCreate returns the instrument registered under name, creating it if needed
+func (r *instruments[T]) getOrCreate(name string, create func() (T, error)) (T, error) {
+	r.mu.RLock()
+	instrument, exists := r.byName[name]
+	r.mu.RUnlock()
+	if exists {
+		return instrument, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// another goroutine may have created it while we were waiting for the lock
+	if instrument, exists := r.byName[name]; exists {
+		return instrument, nil
+	}
+
+	instrument, err := create()
+	if err != nil {
+		return instrument, err
+	}
+
+	r.byName[name] = instrument
+	return instrument, nil
+}
+
+// define creates the instrument registered under name, it fails if the name is already in use
+// since the options of the new definition would be silently ignored.
+func (r *instruments[T]) define(name string, create func() (T, error)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.byName[name]; exists {
+		return fmt.Errorf("metric %s is already registered", name)
+	}
+
+	instrument, err := create()
+	if err != nil {
+		return err
+	}
+
+	r.byName[name] = instrument
+	return nil
+}
+
+// defaultUnit derives the unit of an ad-hoc metric from its name suffix
+func defaultUnit(name string) string {
+	switch {
+	case strings.HasSuffix(name, "_seconds"):
+		return "s"
+	case strings.HasSuffix(name, "_bytes"):
+		return "By"
+	default:
+		return ""
+	}
+}
+
+// defaultBuckets derives the bucket boundaries of an ad-hoc histogram from its name suffix
+func defaultBuckets(name string) []float64 {
+	switch {
+	case strings.HasSuffix(name, "_seconds"):
+		return DurationBuckets
+	case strings.HasSuffix(name, "_bytes"):
+		return SizeBuckets
+	default:
+		return nil
+	}
+}
+
+func (t *Telemetry) metricsReady() bool {
+	return t.config.Enabled && t.config.MetricsEnabled && t.meter != nil
+}
+
+// DefineCounter registers a counter with its description and unit before it is first used.
+// Metrics used without a definition are created on the fly with a unit derived from the name.
+//
+//	tel.DefineCounter("task_create_total", metric.WithDescription("Number of tasks created"))
+func (t *Telemetry) DefineCounter(name string, opts ...metric.Int64CounterOption) error {
+	if !t.metricsReady() {
+		return nil
+	}
+
+	return t.counters.define(name, func() (metric.Int64Counter, error) {
+		return t.meter.Int64Counter(name, opts...)
+	})
+}
+
+// DefineUpDownCounter registers an up-down counter (e.g. in-flight jobs) with its description and unit
+func (t *Telemetry) DefineUpDownCounter(name string, opts ...metric.Int64UpDownCounterOption) error {
+	if !t.metricsReady() {
+		return nil
+	}
+
+	return t.upDownCounters.define(name, func() (metric.Int64UpDownCounter, error) {
+		return t.meter.Int64UpDownCounter(name, opts...)
+	})
+}
+
+// DefineHistogram registers a histogram with its description, unit and bucket boundaries
+//
+//	tel.DefineHistogram("payment_amount", metric.WithUnit("{IDR}"),
+//		metric.WithExplicitBucketBoundaries(10_000, 100_000, 1_000_000))
+func (t *Telemetry) DefineHistogram(name string, opts ...metric.Float64HistogramOption) error {
+	if !t.metricsReady() {
+		return nil
+	}
+
+	return t.histograms.define(name, func() (metric.Float64Histogram, error) {
+		return t.meter.Float64Histogram(name, opts...)
+	})
+}
+
+// DefineGauge registers a gauge with its description and unit
+func (t *Telemetry) DefineGauge(name string, opts ...metric.Float64GaugeOption) error {
+	if !t.metricsReady() {
+		return nil
+	}
+
+	return t.gauges.define(name, func() (metric.Float64Gauge, error) {
+		return t.meter.Float64Gauge(name, opts...)
+	})
+}
+
+// ObserveGauge registers a gauge whose value is read by callback on every collection
+//
+//	tel.ObserveGauge("queue_depth", func(ctx context.Context, o metric.Float64Observer) error {
+//		o.Observe(float64(queue.Len()))
+//		return nil
+//	}, metric.WithDescription("Number of jobs waiting in the queue"))
+func (t *Telemetry) ObserveGauge(name string, callback metric.Float64Callback, opts ...metric.Float64ObservableGaugeOption) error {
+	if !t.metricsReady() {
+		return nil
+	}
+
+	_, err := t.meter.Float64ObservableGauge(name, append(opts, metric.WithFloat64Callback(callback))...)
+	return err
+}
+
+// ObserveCounter registers a monotonic counter whose total is read by callback on every collection
+func (t *Telemetry) ObserveCounter(name string, callback metric.Int64Callback, opts ...metric.Int64ObservableCounterOption) error {
+	if !t.metricsReady() {
+		return nil
+	}
+
+	_, err := t.meter.Int64ObservableCounter(name, append(opts, metric.WithInt64Callback(callback))...)
+	return err
+}
+
+// getOrCreateCounter gets or creates a counter metric
+func (t *Telemetry) getOrCreateCounter(name string) (metric.Int64Counter, error) {
+	return t.counters.getOrCreate(name, func() (metric.Int64Counter, error) {
+		return t.meter.Int64Counter(name, metric.WithUnit(defaultUnit(name)))
+	})
+}
+
+// getOrCreateUpDownCounter gets or creates an up-down counter metric
+func (t *Telemetry) getOrCreateUpDownCounter(name string) (metric.Int64UpDownCounter, error) {
+	return t.upDownCounters.getOrCreate(name, func() (metric.Int64UpDownCounter, error) {
+		return t.meter.Int64UpDownCounter(name, metric.WithUnit(defaultUnit(name)))
+	})
+}
+
+// getOrCreateHistogram gets or creates a histogram metric
+func (t *Telemetry) getOrCreateHistogram(name string) (metric.Float64Histogram, error) {
+	return t.histograms.getOrCreate(name, func() (metric.Float64Histogram, error) {
+		opts := []metric.Float64HistogramOption{metric.WithUnit(defaultUnit(name))}
+		if buckets := defaultBuckets(name); buckets != nil {
+			opts = append(opts, metric.WithExplicitBucketBoundaries(buckets...))
+		}
+		return t.meter.Float64Histogram(name, opts...)
+	})
+}
+
+// getOrCreateGauge gets or creates a gauge metric
+func (t *Telemetry) getOrCreateGauge(name string) (metric.Float64Gauge, error) {
+	return t.gauges.getOrCreate(name, func() (metric.Float64Gauge, error) {
+		return t.meter.Float64Gauge(name, metric.WithUnit(defaultUnit(name)))
+	})
+}
+
+// Add adds delta (which may be negative) to an up-down counter metric
+func (t *Telemetry) Add(ctx context.Context, metricName string, delta int64, attrs ...attribute.KeyValue) {
+	if !t.metricsReady() {
+		return
+	}
+
+	counter, err := t.getOrCreateUpDownCounter(metricName)
+	if err != nil {
+		t.logger.Error().Err(err).Str("metric", metricName).Msg("Failed to get up-down counter")
+		return
+	}
+
+	counter.Add(ctx, delta, metric.WithAttributes(attrs...))
+}
+
+// SetGauge records the current value of a gauge metric
+func (t *Telemetry) SetGauge(ctx context.Context, metricName string, value float64, attrs ...attribute.KeyValue) {
+	if !t.metricsReady() {
+		return
+	}
+
+	gauge, err := t.getOrCreateGauge(metricName)
+	if err != nil {
+		t.logger.Error().Err(err).Str("metric", metricName).Msg("Failed to get gauge")
+		return
+	}
+
+	gauge.Record(ctx, value, metric.WithAttributes(attrs...))
+}
@@ -0,0 +1,285 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang-service-template/internal/common"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// these tests are meant to run with the race detector: go test -race ./internal/telemetry/
+
+const goroutines = 16
+
+// newTestTelemetry is a Telemetry with metrics enabled, read by the returned reader instead of an exporter
+func newTestTelemetry(t *testing.T) (*Telemetry, *sdkmetric.ManualReader) {
+	t.Helper()
+
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	return &Telemetry{
+		config:         common.TelemetryConfig{Enabled: true, MetricsEnabled: true},
+		logger:         zerolog.Nop(),
+		meter:          provider.Meter("test"),
+		counters:       newInstruments[metric.Int64Counter](),
+		upDownCounters: newInstruments[metric.Int64UpDownCounter](),
+		histograms:     newInstruments[metric.Float64Histogram](),
+		gauges:         newInstruments[metric.Float64Gauge](),
+	}, reader
+}
+
+// collect reads the metrics of reader by name
+func collect(t *testing.T, reader *sdkmetric.ManualReader) map[string]metricdata.Aggregation {
+	t.Helper()
+
+	var data metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &data); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+
+	metrics := map[string]metricdata.Aggregation{}
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+	return metrics
+}
+
+func sumOf(t *testing.T, metrics map[string]metricdata.Aggregation, name string) int64 {
+	t.Helper()
+
+	sum, ok := metrics[name].(metricdata.Sum[int64])
+	if !ok {
+		t.Fatalf("%s is not an int64 sum: %T", name, metrics[name])
+	}
+
+	var total int64
+	for _, point := range sum.DataPoints {
+		total += point.Value
+	}
+	return total
+}
+
+// countOf is the number of values recorded in the histogram name
+func countOf(t *testing.T, metrics map[string]metricdata.Aggregation, name string) uint64 {
+	t.Helper()
+
+	histogram, ok := metrics[name].(metricdata.Histogram[float64])
+	if !ok {
+		t.Fatalf("%s is not a float64 histogram: %T", name, metrics[name])
+	}
+
+	var total uint64
+	for _, point := range histogram.DataPoints {
+		total += point.Count
+	}
+	return total
+}
+
+// the instruments are created on first use by Increment, RecordDuration, Add and SetGauge, from any goroutine
+func TestRecordConcurrently(t *testing.T) {
+	tel, reader := newTestTelemetry(t)
+	ctx := context.Background()
+
+	const iterations = 100
+
+	var wg sync.WaitGroup
+	for g := range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range iterations {
+				start := time.Now()
+
+				// the same names from every goroutine
+				tel.Increment(ctx, "jobs_total")
+				tel.RecordDuration(ctx, "job_duration_seconds", start)
+				tel.Add(ctx, "jobs_in_flight", 1)
+				tel.SetGauge(ctx, "queue_depth", float64(i))
+				// and a name per goroutine
+				tel.Increment(ctx, fmt.Sprintf("jobs_total_%d", g))
+				tel.RecordDuration(ctx, fmt.Sprintf("job_duration_seconds_%d", g), start)
+				tel.Add(ctx, fmt.Sprintf("jobs_in_flight_%d", g), 1)
+				tel.SetGauge(ctx, fmt.Sprintf("queue_depth_%d", g), float64(i), attribute.Int("goroutine", g))
+			}
+		}()
+	}
+	wg.Wait()
+
+	metrics := collect(t, reader)
+
+	for _, name := range []string{"jobs_total", "jobs_in_flight"} {
+		if got := sumOf(t, metrics, name); got != goroutines*iterations {
+			t.Errorf("%s = %d, want %d", name, got, goroutines*iterations)
+		}
+	}
+	if got := countOf(t, metrics, "job_duration_seconds"); got != goroutines*iterations {
+		t.Errorf("job_duration_seconds count = %d, want %d", got, goroutines*iterations)
+	}
+	for g := range goroutines {
+		for _, name := range []string{fmt.Sprintf("jobs_total_%d", g), fmt.Sprintf("jobs_in_flight_%d", g)} {
+			if got := sumOf(t, metrics, name); got != iterations {
+				t.Errorf("%s = %d, want %d", name, got, iterations)
+			}
+		}
+		name := fmt.Sprintf("job_duration_seconds_%d", g)
+		if got := countOf(t, metrics, name); got != iterations {
+			t.Errorf("%s count = %d, want %d", name, got, iterations)
+		}
+		if _, ok := metrics[fmt.Sprintf("queue_depth_%d", g)].(metricdata.Gauge[float64]); !ok {
+			t.Errorf("queue_depth_%d is missing", g)
+		}
+	}
+	if _, ok := metrics["queue_depth"].(metricdata.Gauge[float64]); !ok {
+		t.Error("queue_depth is missing")
+	}
+
+	// one instrument per name, whichever goroutine created it
+	if got := len(tel.counters.byName); got != goroutines+1 {
+		t.Errorf("%d counters, want %d", got, goroutines+1)
+	}
+	if got := len(tel.histograms.byName); got != goroutines+1 {
+		t.Errorf("%d histograms, want %d", got, goroutines+1)
+	}
+	if got := len(tel.upDownCounters.byName); got != goroutines+1 {
+		t.Errorf("%d up-down counters, want %d", got, goroutines+1)
+	}
+	if got := len(tel.gauges.byName); got != goroutines+1 {
+		t.Errorf("%d gauges, want %d", got, goroutines+1)
+	}
+}
+
+func TestDefineTwice(t *testing.T) {
+	tel, _ := newTestTelemetry(t)
+
+	tests := []struct {
+		name   string
+		define func(name string) error
+	}{
+		{"counter", func(name string) error { return tel.DefineCounter(name) }},
+		{"up_down_counter", func(name string) error { return tel.DefineUpDownCounter(name) }},
+		{"histogram", func(name string) error { return tel.DefineHistogram(name) }},
+		{"gauge", func(name string) error { return tel.DefineGauge(name) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := "defined_" + tt.name
+
+			if err := tt.define(name); err != nil {
+				t.Fatalf("first definition: %v", err)
+			}
+			if err := tt.define(name); err == nil {
+				t.Error("second definition: no error")
+			}
+		})
+	}
+}
+
+func TestDefineConcurrently(t *testing.T) {
+	tel, _ := newTestTelemetry(t)
+
+	var defined atomic.Int32
+	var wg sync.WaitGroup
+	for range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if err := tel.DefineCounter("task_create_total"); err == nil {
+				defined.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := defined.Load(); got != 1 {
+		t.Errorf("defined %d times, want once", got)
+	}
+}
+
+func TestDefineAfterUse(t *testing.T) {
+	tel, _ := newTestTelemetry(t)
+
+	tel.Increment(context.Background(), "used_total")
+	if err := tel.DefineCounter("used_total"); err == nil {
+		t.Error("definition of a metric in use: no error")
+	}
+}
+
+func TestObserveWhileWriting(t *testing.T) {
+	tel, reader := newTestTelemetry(t)
+
+	// written by the goroutines below, read by the callbacks on every collection
+	var depth atomic.Int64
+	var processed atomic.Int64
+
+	err := tel.ObserveGauge("queue_depth", func(_ context.Context, o metric.Float64Observer) error {
+		o.Observe(float64(depth.Load()))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ObserveGauge: %v", err)
+	}
+	err = tel.ObserveCounter("jobs_processed_total", func(_ context.Context, o metric.Int64Observer) error {
+		o.Observe(processed.Load())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ObserveCounter: %v", err)
+	}
+
+	const iterations = 1000
+
+	var wg sync.WaitGroup
+	for range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range iterations {
+				depth.Store(int64(i))
+				processed.Add(1)
+			}
+		}()
+	}
+
+	// collections run the callbacks concurrently with the writes
+	collected := make(chan struct{})
+	go func() {
+		defer close(collected)
+		for range 50 {
+			var data metricdata.ResourceMetrics
+			if err := reader.Collect(context.Background(), &data); err != nil {
+				t.Errorf("collect: %v", err)
+				return
+			}
+		}
+	}()
+
+	wg.Wait()
+	<-collected
+
+	metrics := collect(t, reader)
+	if got := sumOf(t, metrics, "jobs_processed_total"); got != goroutines*iterations {
+		t.Errorf("jobs_processed_total = %d, want %d", got, goroutines*iterations)
+	}
+	gauge, ok := metrics["queue_depth"].(metricdata.Gauge[float64])
+	if !ok || len(gauge.DataPoints) != 1 || gauge.DataPoints[0].Value != iterations-1 {
+		t.Errorf("queue_depth = %+v, want %d", metrics["queue_depth"], iterations-1)
+	}
+}
@@ -10,12 +10,13 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/contrib/instrumentation/runtime"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
 	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/propagation"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
@@ -23,6 +24,11 @@ import (
 	"go.opentelemetry.io/otel/trace"
 )
 
+const (
+	MetricsExporterPrometheus = "prometheus"
+	MetricsExporterOTLP       = "otlp"
+)
+
 type Telemetry struct {
 	config common.TelemetryConfig
 	logger zerolog.Logger
@@ -30,6 +36,7 @@ type Telemetry struct {
 	// OpenTelemetry components
 	tracerProvider *sdktrace.TracerProvider
 	meterProvider  *sdkmetric.MeterProvider
+	loggerProvider *sdklog.LoggerProvider
 	tracer         trace.Tracer
 	meter          metric.Meter
 
@@ -38,8 +45,10 @@ type Telemetry struct {
 	promHandler  http.Handler
 
 	// Metrics - using generic approach
-	counters   map[string]metric.Int64Counter
-	histograms map[string]metric.Float64Histogram
+	counters       *instruments[metric.Int64Counter]
+	upDownCounters *instruments[metric.Int64UpDownCounter]
+	histograms     *instruments[metric.Float64Histogram]
+	gauges         *instruments[metric.Float64Gauge]
 
 	// Shutdown function
 	shutdownFuncs []func(context.Context) error
@@ -52,13 +61,20 @@ func NewTelemetry(config common.TelemetryConfig, logger zerolog.Logger) (*Teleme
 		Str("endpoint", config.OtelEndpoint).
 		Bool("metrics", config.MetricsEnabled).
 		Bool("tracing", config.TracingEnabled).
+		Bool("logs", config.LogsEnabled).
+		Str("protocol", config.OtelProtocol).
+		Strs("metrics_exporters", config.MetricsExporters).
+		Str("sampler", config.TracesSampler).
+		Float64("sampler_ratio", config.TracesSamplerRatio).
 		Msg("Initializing OpenTelemetry")
 
 	t := &Telemetry{
-		config:     config,
-		logger:     logger,
-		counters:   make(map[string]metric.Int64Counter),
-		histograms: make(map[string]metric.Float64Histogram),
+		config:         config,
+		logger:         logger,
+		counters:       newInstruments[metric.Int64Counter](),
+		upDownCounters: newInstruments[metric.Int64UpDownCounter](),
+		histograms:     newInstruments[metric.Float64Histogram](),
+		gauges:         newInstruments[metric.Float64Gauge](),
 	}
 
 	if !config.Enabled {
@@ -86,6 +102,13 @@ func NewTelemetry(config common.TelemetryConfig, logger zerolog.Logger) (*Teleme
 		}
 	}
 
+	// Setup logs
+	if config.LogsEnabled {
+		if err := t.setupLogs(res); err != nil {
+			return nil, fmt.Errorf("failed to setup logs: %w", err)
+		}
+	}
+
 	// Initialize metrics
 	if err := t.initMetrics(); err != nil {
 		return nil, fmt.Errorf("failed to initialize metrics: %w", err)
@@ -121,14 +144,14 @@ func (t *Telemetry) setupTracing(res *resource.Resource) error {
 
 	if t.config.OtelEndpoint != "" {
 		// Use OTLP exporter for Jaeger
-		exporter, err = otlptracehttp.New(context.Background(),
-			otlptracehttp.WithEndpoint(t.config.OtelEndpoint),
-			otlptracehttp.WithInsecure(), // Use HTTPS in production
-		)
+		exporter, err = t.newTraceExporter(context.Background())
 		if err != nil {
 			return err
 		}
-		t.logger.Info().Str("endpoint", t.config.OtelEndpoint).Msg("Using OTLP trace exporter")
+		t.logger.Info().
+			Str("endpoint", t.config.OtelEndpoint).
+			Str("protocol", t.config.OtelProtocol).
+			Msg("Using OTLP trace exporter")
 	} else {
 		// Use console exporter for development
 		t.logger.Info().Msg("Using console trace exporter (no endpoint configured)")
@@ -139,7 +162,7 @@ func (t *Telemetry) setupTracing(res *resource.Resource) error {
 		t.tracerProvider = sdktrace.NewTracerProvider(
 			sdktrace.WithBatcher(exporter),
 			sdktrace.WithResource(res),
-			sdktrace.WithSampler(sdktrace.AlwaysSample()),
+			sdktrace.WithSampler(t.sampler()),
 		)
 
 		otel.SetTracerProvider(t.tracerProvider)
@@ -157,27 +180,56 @@ func (t *Telemetry) setupTracing(res *resource.Resource) error {
 }
 
 func (t *Telemetry) setupMetrics(res *resource.Resource) error {
-	// Create Prometheus registry
-	t.promRegistry = prometheus.NewRegistry()
+	options := []sdkmetric.Option{
+		sdkmetric.WithResource(res),
+	}
 
-	// Create Prometheus exporter
-	promExporter, err := otelprometheus.New(
-		otelprometheus.WithRegisterer(t.promRegistry),
-		otelprometheus.WithoutUnits(),
-	)
-	if err != nil {
-		return err
+	exporters := t.config.MetricsExporters
+	if len(exporters) == 0 {
+		exporters = []string{MetricsExporterPrometheus}
+	}
+
+	for _, name := range exporters {
+		switch name {
+		case MetricsExporterPrometheus:
+			reader, err := t.newPrometheusReader()
+			if err != nil {
+				return err
+			}
+			options = append(options, sdkmetric.WithReader(reader))
+		case MetricsExporterOTLP:
+			if t.config.OtelEndpoint == "" {
+				t.logger.Warn().Msg("OTLP metrics exporter requested but no endpoint configured, skipping")
+				continue
+			}
+			exporter, err := t.newMetricExporter(context.Background())
+			if err != nil {
+				return err
+			}
+			options = append(options, sdkmetric.WithReader(
+				sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithInterval(t.metricExportInterval())),
+			))
+			t.logger.Info().
+				Str("endpoint", t.config.OtelEndpoint).
+				Str("protocol", t.config.OtelProtocol).
+				Dur("interval", t.metricExportInterval()).
+				Msg("OTLP metrics exporter configured")
+		default:
+			return fmt.Errorf("unknown metrics exporter: %s", name)
+		}
 	}
 
 	// Create meter provider
-	t.meterProvider = sdkmetric.NewMeterProvider(
-		sdkmetric.WithResource(res),
-		sdkmetric.WithReader(promExporter),
-	)
+	t.meterProvider = sdkmetric.NewMeterProvider(options...)
 
 	otel.SetMeterProvider(t.meterProvider)
 	t.shutdownFuncs = append(t.shutdownFuncs, t.meterProvider.Shutdown)
 
+	// Go runtime metrics: goroutines, GC, memory, scheduler
+	if err := runtime.Start(runtime.WithMeterProvider(t.meterProvider)); err != nil {
+		return fmt.Errorf("failed to start runtime metrics: %w", err)
+	}
+
 	t.meter = otel.Meter("golang-service-template",
 		metric.WithInstrumentationVersion("1.0.0"),
 		metric.WithInstrumentationAttributes(
@@ -185,11 +237,28 @@ func (t *Telemetry) setupMetrics(res *resource.Resource) error {
 		),
 	)
 
+	return nil
+}
+
+// newPrometheusReader creates the pull exporter served on /metrics
+func (t *Telemetry) newPrometheusReader() (sdkmetric.Reader, error) {
+	// Create Prometheus registry
+	t.promRegistry = prometheus.NewRegistry()
+
+	// Create Prometheus exporter
+	promExporter, err := otelprometheus.New(
+		otelprometheus.WithRegisterer(t.promRegistry),
+		otelprometheus.WithoutUnits(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create Prometheus handler
 	t.promHandler = promhttp.HandlerFor(t.promRegistry, promhttp.HandlerOpts{})
 
 	t.logger.Info().Msg("Prometheus metrics exporter configured")
-	return nil
+	return promExporter, nil
 }
 
 func (t *Telemetry) initMetrics() error {
@@ -198,59 +267,65 @@ func (t *Telemetry) initMetrics() error {
 	}
 
 	// Pre-initialize common HTTP metrics only
-	commonCounters := []string{
-		"http_requests_total",
+	if err := t.DefineCounter("http_requests_total",
+		metric.WithDescription("Number of HTTP requests handled, by method, route and status"),
+	); err != nil {
+		return err
 	}
 
-	commonHistograms := []string{
-		"http_request_duration_seconds",
+	if err := t.DefineHistogram("http_request_duration_seconds",
+		metric.WithDescription("Duration of HTTP requests, by method and route"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(DurationBuckets...),
+	); err != nil {
+		return err
 	}
 
-	// Initialize common counters
-	for _, name := range commonCounters {
-		if _, err := t.getOrCreateCounter(name); err != nil {
-			return err
-		}
+	if err := t.DefineHistogram("http_request_size_bytes",
+		metric.WithDescription("Size of HTTP request bodies, by method and route"),
+		metric.WithUnit("By"),
+		metric.WithExplicitBucketBoundaries(SizeBuckets...),
+	); err != nil {
+		return err
 	}
 
-	// Initialize common histograms
-	for _, name := range commonHistograms {
-		if _, err := t.getOrCreateHistogram(name); err != nil {
-			return err
-		}
+	if err := t.DefineHistogram("http_response_size_bytes",
+		metric.WithDescription("Size of HTTP response bodies, by method and route"),
+		metric.WithUnit("By"),
+		metric.WithExplicitBucketBoundaries(SizeBuckets...),
+	); err != nil {
+		return err
 	}
 
-	return nil
-}
-
-// getOrCreateCounter gets or creates a counter metric
-func (t *Telemetry) getOrCreateCounter(name string) (metric.Int64Counter, error) {
-	if counter, exists := t.counters[name]; exists {
-		return counter, nil
+	if err := t.DefineCounter("rpc_requests_total",
+		metric.WithDescription("Number of RPCs handled, by service, method and code"),
+	); err != nil {
+		return err
 	}
 
-	counter, err := t.meter.Int64Counter(name, metric.WithDescription("Counter metric: "+name))
-	if err != nil {
-		return nil, err
+	if err := t.DefineHistogram("rpc_request_duration_seconds",
+		metric.WithDescription("Duration of RPCs, by service and method"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(DurationBuckets...),
+	); err != nil {
+		return err
 	}
 
-	t.counters[name] = counter
-	return counter, nil
-}
-
-// getOrCreateHistogram gets or creates a histogram metric
-func (t *Telemetry) getOrCreateHistogram(name string) (metric.Float64Histogram, error) {
-	if histogram, exists := t.histograms[name]; exists {
-		return histogram, nil
+	if err := t.DefineCounter("graphql_operations_total",
+		metric.WithDescription("Number of GraphQL operations executed, by type and outcome"),
+	); err != nil {
+		return err
 	}
 
-	histogram, err := t.meter.Float64Histogram(name, metric.WithDescription("Histogram metric: "+name))
-	if err != nil {
-		return nil, err
+	if err := t.DefineHistogram("graphql_operation_duration_seconds",
+		metric.WithDescription("Duration of GraphQL operations, by type"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(DurationBuckets...),
+	); err != nil {
+		return err
 	}
 
-	t.histograms[name] = histogram
-	return histogram, nil
+	return nil
 }
 
 // GetMetricsHandler returns the Prometheus metrics handler
@@ -263,7 +338,7 @@ func (t *Telemetry) GetMetricsHandler() http.Handler {
 
 // Increment increments a counter metric
 func (t *Telemetry) Increment(ctx context.Context, metricName string, attrs ...attribute.KeyValue) {
-	if !t.config.Enabled || !t.config.MetricsEnabled {
+	if !t.metricsReady() {
 		return
 	}
 
@@ -278,7 +353,7 @@ func (t *Telemetry) Increment(ctx context.Context, metricName string, attrs ...a
 
 // RecordDuration records a duration in a histogram metric
 func (t *Telemetry) RecordDuration(ctx context.Context, metricName string, startTime time.Time, attrs ...attribute.KeyValue) {
-	if !t.config.Enabled || !t.config.MetricsEnabled {
+	if !t.metricsReady() {
 		return
 	}
 
@@ -292,9 +367,25 @@ func (t *Telemetry) RecordDuration(ctx context.Context, metricName string, start
 	histogram.Record(ctx, duration, metric.WithAttributes(attrs...))
 }
 
+// Record records a value in a histogram metric
+func (t *Telemetry) Record(ctx context.Context, metricName string, value float64, attrs ...attribute.KeyValue) {
+	if !t.metricsReady() {
+		return
+	}
+
+	histogram, err := t.getOrCreateHistogram(metricName)
+	if err != nil {
+		t.logger.Error().Err(err).Str("metric", metricName).Msg("Failed to get histogram")
+		return
+	}
+
+	histogram.Record(ctx, value, metric.WithAttributes(attrs...))
+}
+
 // RecordHTTPRequest records HTTP request metrics using generic methods
-func (t *Telemetry) RecordHTTPRequest(ctx context.Context, method, path, status string, startTime time.Time) {
-	if !t.config.Enabled || !t.config.MetricsEnabled {
+// path should be the route template (e.g. /tasks/:id) to keep the cardinality low
+func (t *Telemetry) RecordHTTPRequest(ctx context.Context, method, path, status string, startTime time.Time, requestSize, responseSize int64) {
+	if !t.metricsReady() {
 		return
 	}
 
@@ -310,14 +401,60 @@ func (t *Telemetry) RecordHTTPRequest(ctx context.Context, method, path, status
 		attribute.String("method", method),
 		attribute.String("path", path),
 	)
+	t.Record(ctx, "http_request_size_bytes", float64(requestSize),
+		attribute.String("method", method),
+		attribute.String("path", path),
+	)
+	t.Record(ctx, "http_response_size_bytes", float64(responseSize),
+		attribute.String("method", method),
+		attribute.String("path", path),
+	)
+}
+
+// RecordRPCRequest records the metrics of an RPC, code is the Connect code (e.g. not_found), ok when it succeeded
+func (t *Telemetry) RecordRPCRequest(ctx context.Context, service, method, code string, startTime time.Time) {
+	if !t.metricsReady() {
+		return
+	}
+
+	t.Increment(ctx, "rpc_requests_total",
+		attribute.String("service", service),
+		attribute.String("method", method),
+		attribute.String("code", code),
+	)
+	t.RecordDuration(ctx, "rpc_request_duration_seconds", startTime,
+		attribute.String("service", service),
+		attribute.String("method", method),
+	)
+}
+
+// RecordGraphQLOperation records the metrics of a GraphQL operation, operationType is query, mutation or subscription,
+// outcome is error when the response has errors, ok otherwise. The operation names are left out, the clients choose them.
+func (t *Telemetry) RecordGraphQLOperation(ctx context.Context, operationType, outcome string, startTime time.Time) {
+	if !t.metricsReady() {
+		return
+	}
+
+	t.Increment(ctx, "graphql_operations_total",
+		attribute.String("type", operationType),
+		attribute.String("outcome", outcome),
+	)
+	t.RecordDuration(ctx, "graphql_operation_duration_seconds", startTime,
+		attribute.String("type", operationType),
+	)
 }
 
 // CreateSpan creates a new trace span with automatic checks
 func (t *Telemetry) CreateSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return t.StartSpan(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartSpan is CreateSpan with full control over the span options (kind, links, ...)
+func (t *Telemetry) StartSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
 	if !t.config.Enabled || !t.config.TracingEnabled || t.tracer == nil {
 		return ctx, trace.SpanFromContext(ctx)
 	}
-	return t.tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+	return t.tracer.Start(ctx, name, opts...)
 }
 
 // RecordError records an error in the current span
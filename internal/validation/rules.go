@@ -0,0 +1,18 @@
+package validation
+
+import (
+	"github.com/go-playground/validator/v10/non-standard/validators"
+)
+
+// the rules of the service, available in every validate tag
+func init() {
+	// required lets "   " through
+	RegisterRule(Rule{
+		Tag: "notblank",
+		Fn:  validators.NotBlank,
+		Messages: map[string]string{
+			"en": "{0} must not be blank",
+			"id": "{0} tidak boleh kosong",
+		},
+	})
+}
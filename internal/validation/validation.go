@@ -0,0 +1,211 @@
+// Package validation validates the requests, with the messages translated in every language of the errors.
+//
+// The validator and its translators are built once, with the custom rules registered in the package:
+//
+//	func init() {
+//		validation.RegisterRule(validation.Rule{
+//			Tag: "sku",
+//			Fn:  func(fl validator.FieldLevel) bool { return skuPattern.MatchString(fl.Field().String()) },
+//			Messages: map[string]string{"en": "{0} must be a SKU", "id": "{0} harus berupa SKU"},
+//		})
+//	}
+//
+// Rules are registered in init functions, the ones registered after New are not applied.
+package validation
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/cockroachdb/errors"
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	id_translations "github.com/go-playground/validator/v10/translations/id"
+)
+
+// DefaultLanguage is used for the languages without translations, and the rules without a message in the language
+const DefaultLanguage = "en"
+
+// Language is a language of the validation messages
+type Language struct {
+	Locale locales.Translator
+	// RegisterDefaultTranslations of the validator/v10/translations package of the language
+	RegisterDefaultTranslations func(v *validator.Validate, trans ut.Translator) error
+}
+
+// Languages of the messages, add a language here (and its error messages in errz/locales) to support it
+var Languages = map[string]Language{
+	"en": {Locale: en.New(), RegisterDefaultTranslations: en_translations.RegisterDefaultTranslations},
+	"id": {Locale: id.New(), RegisterDefaultTranslations: id_translations.RegisterDefaultTranslations},
+}
+
+// Rule is a custom validation tag
+type Rule struct {
+	Tag string
+	Fn  validator.Func
+	// Validate nil pointers and zero values too, not only the set fields
+	CallEvenIfNull bool
+	// Message by language, {0} is the field and {1} the parameter of the tag
+	Messages map[string]string
+}
+
+// StructRule validates a struct as a whole, e.g. fields that depend on each other.
+// Fn reports the errors with StructLevel.ReportError, the messages of their tags are in Messages.
+type StructRule struct {
+	Type any
+	Fn   validator.StructLevelFunc
+	// Message by tag, then by language
+	Messages map[string]map[string]string
+}
+
+var (
+	registryMu  sync.Mutex
+	rules       []Rule
+	structRules []StructRule
+)
+
+// RegisterRule adds a custom tag to the validators built after it, call it from an init function
+func RegisterRule(rule Rule) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	rules = append(rules, rule)
+}
+
+// RegisterStructRule adds a struct level validation to the validators built after it, call it from an init function
+func RegisterStructRule(rule StructRule) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	structRules = append(structRules, rule)
+}
+
+// Validator validates the structs and translates their errors, it is safe for concurrent use
+type Validator struct {
+	validate    *validator.Validate
+	translators map[string]ut.Translator
+}
+
+// New builds the validator with the rules registered so far and a translator per language
+func New() (*Validator, error) {
+	validate := validator.New()
+	// Name the fields as the clients send them, in the messages and the invalid-params
+	validate.RegisterTagNameFunc(FieldName)
+
+	v := &Validator{
+		validate:    validate,
+		translators: map[string]ut.Translator{},
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for _, rule := range rules {
+		if err := validate.RegisterValidation(rule.Tag, rule.Fn, rule.CallEvenIfNull); err != nil {
+			return nil, errors.Wrapf(err, "invalid validation rule %s", rule.Tag)
+		}
+	}
+	for _, rule := range structRules {
+		validate.RegisterStructValidation(rule.Fn, rule.Type)
+	}
+
+	// message by tag, then by language
+	messages := map[string]map[string]string{}
+	for _, rule := range rules {
+		messages[rule.Tag] = rule.Messages
+	}
+	for _, rule := range structRules {
+		for tag, message := range rule.Messages {
+			messages[tag] = message
+		}
+	}
+
+	fallback := Languages[DefaultLanguage].Locale
+	for name, language := range Languages {
+		trans, _ := ut.New(fallback, language.Locale).GetTranslator(name)
+
+		if err := language.RegisterDefaultTranslations(validate, trans); err != nil {
+			return nil, errors.Wrapf(err, "failed to register the %s validation messages", name)
+		}
+
+		for tag, message := range messages {
+			if err := registerMessage(validate, trans, tag, localized(message, name)); err != nil {
+				return nil, errors.Wrapf(err, "failed to register the %s message of %s", name, tag)
+			}
+		}
+
+		v.translators[name] = trans
+	}
+
+	return v, nil
+}
+
+// localized is the message in language, english when it has none
+func localized(messages map[string]string, language string) string {
+	if message, ok := messages[language]; ok {
+		return message
+	}
+	return messages[DefaultLanguage]
+}
+
+func registerMessage(validate *validator.Validate, trans ut.Translator, tag, message string) error {
+	if message == "" {
+		return nil
+	}
+
+	return validate.RegisterTranslation(tag, trans,
+		func(trans ut.Translator) error {
+			return trans.Add(tag, message, true)
+		},
+		func(trans ut.Translator, fieldError validator.FieldError) string {
+			translated, err := trans.T(tag, fieldError.Field(), fieldError.Param())
+			if err != nil {
+				return fieldError.Error()
+			}
+			return translated
+		},
+	)
+}
+
+// Validate returns the underlying validator, e.g. to validate a single value with Var
+func (v *Validator) Validate() *validator.Validate {
+	return v.validate
+}
+
+// Struct validates s, the errors are validator.ValidationErrors
+func (v *Validator) Struct(s any) error {
+	return v.validate.Struct(s)
+}
+
+// Translator returns the translator of a language, english when the language has none
+func (v *Validator) Translator(language string) ut.Translator {
+	if trans, ok := v.translators[language]; ok {
+		return trans
+	}
+	return v.translators[DefaultLanguage]
+}
+
+// FieldName is the name of the field in the request: its json name, else its path or query parameter, else its Go name
+func FieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "param", "query"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// ParamName is the path of the field from the request root, e.g. rules[0].variant
+func ParamName(fieldError validator.FieldError) string {
+	_, name, found := strings.Cut(fieldError.Namespace(), ".")
+	if !found {
+		return fieldError.Field()
+	}
+	return name
+}
@@ -0,0 +1,235 @@
+/*
+package migration embeds the sql migrations and applies them.
+
+It keeps track of the applied version in the same `schema_migrations` table as
+golang-migrate (https://github.com/golang-migrate/migrate), so both can be used on the same database.
+*/
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"gorm.io/gorm"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+const schemaMigrationsTable = "schema_migrations"
+
+// Migration is a pair of up and down sql scripts, named `<version>_<name>.(up|down).sql`
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	migrations, err := load(migrationsFS)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+	}, nil
+}
+
+// Version returns the currently applied version, 0 if none.
+// A dirty version means a migration failed halfway and must be fixed by hand.
+func (m *Migrator) Version(ctx context.Context) (version int64, dirty bool, err error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return 0, false, err
+	}
+
+	row := m.db.WithContext(ctx).Raw("SELECT version, dirty FROM " + schemaMigrationsTable + " LIMIT 1").Row()
+	if err := row.Scan(&version, &dirty); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, errors.Wrap(err, "failed to read migration version")
+	}
+
+	return version, dirty, nil
+}
+
+// Up applies all the pending migrations, and returns the applied ones
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	version, err := m.cleanVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := []Migration{}
+	for _, migration := range m.migrations {
+		if migration.Version <= version {
+			continue
+		}
+
+		if err := m.apply(ctx, migration.Version, migration.Up, migration.Version); err != nil {
+			return applied, errors.Wrapf(err, "failed to apply %s", migration)
+		}
+
+		applied = append(applied, migration)
+	}
+
+	return applied, nil
+}
+
+// Down rolls back the last `steps` applied migrations, and returns the rolled back ones
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	version, err := m.cleanVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rolledBack := []Migration{}
+	for index := len(m.migrations) - 1; index >= 0 && len(rolledBack) < steps; index-- {
+		migration := m.migrations[index]
+		if migration.Version > version {
+			continue
+		}
+
+		previous := int64(0)
+		if index > 0 {
+			previous = m.migrations[index-1].Version
+		}
+
+		if err := m.apply(ctx, migration.Version, migration.Down, previous); err != nil {
+			return rolledBack, errors.Wrapf(err, "failed to roll back %s", migration)
+		}
+
+		rolledBack = append(rolledBack, migration)
+	}
+
+	return rolledBack, nil
+}
+
+func (m *Migrator) cleanVersion(ctx context.Context) (int64, error) {
+	version, dirty, err := m.Version(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if dirty {
+		return 0, errors.Newf("database is dirty at version %d, fix it by hand and set dirty=false in %s", version, schemaMigrationsTable)
+	}
+
+	return version, nil
+}
+
+// apply runs the script the same way golang-migrate does:
+// mark `version` as dirty, run the script, then store `target` as the clean version
+func (m *Migrator) apply(ctx context.Context, version int64, script string, target int64) error {
+	db := m.db.WithContext(ctx)
+
+	if err := m.setVersion(db, version, true); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(script) != "" {
+		if err := db.Exec(script).Error; err != nil {
+			return err
+		}
+	}
+
+	if target == 0 {
+		return db.Exec("DELETE FROM " + schemaMigrationsTable).Error
+	}
+
+	return m.setVersion(db, target, false)
+}
+
+func (m *Migrator) setVersion(db *gorm.DB, version int64, dirty bool) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM " + schemaMigrationsTable).Error; err != nil {
+			return err
+		}
+		return tx.Exec("INSERT INTO "+schemaMigrationsTable+" (version, dirty) VALUES (?, ?)", version, dirty).Error
+	})
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	err := m.db.WithContext(ctx).
+		Exec("CREATE TABLE IF NOT EXISTS " + schemaMigrationsTable + " (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)").
+		Error
+
+	return errors.Wrap(err, "failed to create migration table")
+}
+
+// load reads the migrations from the embedded files, sorted by version
+func load(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, file := range files {
+		base := strings.TrimPrefix(file, "migrations/")
+
+		var direction string
+		switch {
+		case strings.HasSuffix(base, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(base, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, errors.Newf("migration %s must end with .up.sql or .down.sql", base)
+		}
+
+		name := strings.TrimSuffix(base, "."+direction+".sql")
+		versionStr, name, _ := strings.Cut(name, "_")
+		version, err := strconv.ParseInt(versionStr, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "migration %s must start with a numeric version", base)
+		}
+
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		}
+
+		if direction == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(a, b int) bool {
+		return migrations[a].Version < migrations[b].Version
+	})
+
+	return migrations, nil
+}
+
+func (migration Migration) String() string {
+	return fmt.Sprintf("%d_%s", migration.Version, migration.Name)
+}